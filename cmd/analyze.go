@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"time"
+	"path/filepath"
 
 	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
 	"github.com/fishnix/ghpr-analyzer/internal/cache"
@@ -16,17 +16,40 @@ import (
 )
 
 var (
-	orgFlag              string
-	sinceFlag            string
-	untilFlag            string
-	excludeAuthorFlags   []string
-	excludeTitlePrefixes []string
-	outputFormatFlag     string
-	outputDirFlag        string
-	skipAPICallsFlag     bool
-	invalidateCacheFlag  bool
-	ignoreTTLFlag        bool
-	dryRunFlag           bool
+	orgFlag               string
+	repoFlag              string
+	sinceFlag             string
+	untilFlag             string
+	excludeAuthorFlags    []string
+	excludeTitlePrefixes  []string
+	excludePRFlags        []string
+	includeRepoFlags      []string
+	excludeRepoFlags      []string
+	outputFormatFlag      string
+	outputDirFlag         string
+	skipAPICallsFlag      bool
+	invalidateCacheFlag   bool
+	ignoreTTLFlag         bool
+	dryRunFlag            bool
+	fetchPRDetailsFlag    bool
+	fetchReviewsFlag      bool
+	fetchUserDetailsFlag  bool
+	fetchCommitsFlag      bool
+	fetchReviewEventsFlag bool
+	fetchReleasesFlag     bool
+	limitReposFlag        int
+	crossTabFlag          bool
+	detailedFlag          bool
+	htmlReportFlag        bool
+	allowEmptyFlag        bool
+	minCoverageFlag       float64
+	failOnErrorFlag       bool
+	dumpRepoResultsFlag   string
+	loadRepoResultsFlag   string
+	outputCombinedFlag    bool
+	printConfigFlag       bool
+	codeownersFileFlag    string
+	noCodeownersFlag      bool
 )
 
 // analyzeCmd starts analysis
@@ -47,32 +70,68 @@ func init() {
 
 	// Bind flags to viper
 	analyzeCmd.Flags().StringVar(&orgFlag, "org", "", "GitHub organization name")
-	analyzeCmd.Flags().StringVar(&sinceFlag, "since", "", "Start time for analysis (RFC3339 format)")
-	analyzeCmd.Flags().StringVar(&untilFlag, "until", "", "End time for analysis (RFC3339 format)")
+	analyzeCmd.Flags().StringVar(&repoFlag, "repo", "", "Analyze only this repository (owner/name) instead of the organization's; --org is then optional")
+	analyzeCmd.Flags().StringVar(&sinceFlag, "since", "", "Start time for analysis (RFC3339, or relative like -30d or now-4w)")
+	analyzeCmd.Flags().StringVar(&untilFlag, "until", "", "End time for analysis (RFC3339, or relative like now or now-1w)")
 	analyzeCmd.Flags().StringArrayVar(&excludeAuthorFlags, "exclude-author", []string{}, "Exclude PRs by author (can be specified multiple times)")
 	analyzeCmd.Flags().StringArrayVar(&excludeTitlePrefixes, "exclude-title-prefix", []string{}, "Exclude PRs by title prefix (can be specified multiple times)")
-	analyzeCmd.Flags().StringVar(&outputFormatFlag, "output-format", "", "Output format (json, csv)")
+	analyzeCmd.Flags().StringArrayVar(&excludePRFlags, "exclude-pr", []string{}, "Exclude a single PR given as owner/repo#number (can be specified multiple times)")
+	analyzeCmd.Flags().StringArrayVar(&includeRepoFlags, "include-repo", []string{}, "Analyze only repositories whose name matches this glob, e.g. 'service-*' (can be specified multiple times)")
+	analyzeCmd.Flags().StringArrayVar(&excludeRepoFlags, "exclude-repo", []string{}, "Skip repositories whose name matches this glob, even if included (can be specified multiple times)")
+	analyzeCmd.Flags().StringVar(&outputFormatFlag, "output-format", "", "Output format (json, csv, sqlite)")
 	analyzeCmd.Flags().StringVar(&outputDirFlag, "output-dir", "", "Output directory")
 	analyzeCmd.Flags().BoolVar(&skipAPICallsFlag, "skip-api-calls", false, "Skip API calls and use cache only")
 	analyzeCmd.Flags().BoolVar(&invalidateCacheFlag, "invalidate-cache", false, "Invalidate cache before analysis")
 	analyzeCmd.Flags().BoolVar(&ignoreTTLFlag, "ignore-ttl", false, "Ignore TTL and use cache data regardless of age")
 	analyzeCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Dry run mode (don't make API calls)")
+	analyzeCmd.Flags().BoolVar(&fetchPRDetailsFlag, "fetch-pr-details", false, "Fetch each PR individually for detail fields (commits, additions, deletions)")
+	analyzeCmd.Flags().BoolVar(&fetchReviewsFlag, "fetch-reviews", false, "Fetch the reviews of each PR")
+	analyzeCmd.Flags().BoolVar(&fetchUserDetailsFlag, "fetch-user-details", false, "Fetch each author's profile to count PRs by company")
+	analyzeCmd.Flags().BoolVar(&fetchCommitsFlag, "fetch-commits", false, "Fetch the commits of each PR to credit co-authors")
+	analyzeCmd.Flags().BoolVar(&fetchReviewEventsFlag, "fetch-review-events", false, "Fetch the review events of each PR's timeline to measure review churn")
+	analyzeCmd.Flags().IntVar(&limitReposFlag, "limit-repos", 0, "Process only the first N repositories by name (smoke test; results are truncated)")
+	analyzeCmd.Flags().BoolVar(&fetchReleasesFlag, "fetch-releases", false, "Fetch the releases of each repository to measure merge-to-release lead time")
+	analyzeCmd.Flags().BoolVar(&crossTabFlag, "cross-tab", false, "Export a PRs by user and team matrix")
+	analyzeCmd.Flags().BoolVar(&detailedFlag, "detailed", false, "Export teams_detail.json with each team's repos and users nested")
+	analyzeCmd.Flags().BoolVar(&htmlReportFlag, "html-report", false, "Export all breakdowns as a single HTML report")
+	analyzeCmd.Flags().Float64Var(&minCoverageFlag, "min-coverage", 0, "Warn about repositories where CODEOWNERS owns less than this share (0-1) of the changed files")
+	analyzeCmd.Flags().BoolVar(&failOnErrorFlag, "fail-on-error", false, "Fail the run, after exporting, when a repository is below --min-coverage")
+	analyzeCmd.Flags().BoolVar(&allowEmptyFlag, "allow-empty", false, "Export an empty result instead of failing when no repositories are found")
+	analyzeCmd.Flags().StringVar(&dumpRepoResultsFlag, "dump-repo-results", "", "Write the processed repositories to this file, to re-run aggregation later with --load-repo-results")
+	analyzeCmd.Flags().StringVar(&loadRepoResultsFlag, "load-repo-results", "", "Aggregate the processed repositories written by --dump-repo-results instead of fetching")
+	analyzeCmd.Flags().BoolVar(&outputCombinedFlag, "output-combined", false, "Also export all results as a single report.json")
+	analyzeCmd.Flags().BoolVar(&printConfigFlag, "print-config", false, "Print the effective configuration (after defaults and flags) and exit")
+	analyzeCmd.Flags().StringVar(&codeownersFileFlag, "codeowners-file", "", "Use a local CODEOWNERS file for every repository instead of fetching it")
+	analyzeCmd.Flags().BoolVar(&noCodeownersFlag, "no-codeowners", false, "Skip CODEOWNERS and PR file fetching; counts PRs by repository and user only")
 
 	// Bind flags to viper
 	viper.BindPFlag("github.org", analyzeCmd.Flags().Lookup("org"))
+	viper.BindPFlag("github.repo", analyzeCmd.Flags().Lookup("repo"))
 	viper.BindPFlag("time_window.since", analyzeCmd.Flags().Lookup("since"))
 	viper.BindPFlag("time_window.until", analyzeCmd.Flags().Lookup("until"))
 	viper.BindPFlag("filters.exclude_authors", analyzeCmd.Flags().Lookup("exclude-author"))
 	viper.BindPFlag("filters.exclude_title_prefixes", analyzeCmd.Flags().Lookup("exclude-title-prefix"))
 	viper.BindPFlag("output.format", analyzeCmd.Flags().Lookup("output-format"))
 	viper.BindPFlag("output.output_dir", analyzeCmd.Flags().Lookup("output-dir"))
+	viper.BindPFlag("fetch.pr_details", analyzeCmd.Flags().Lookup("fetch-pr-details"))
+	viper.BindPFlag("fetch.reviews", analyzeCmd.Flags().Lookup("fetch-reviews"))
+	viper.BindPFlag("fetch.user_details", analyzeCmd.Flags().Lookup("fetch-user-details"))
+	viper.BindPFlag("output.cross_tab", analyzeCmd.Flags().Lookup("cross-tab"))
+	viper.BindPFlag("output.detailed", analyzeCmd.Flags().Lookup("detailed"))
+	viper.BindPFlag("output.html_report", analyzeCmd.Flags().Lookup("html-report"))
+	viper.BindPFlag("codeowners.min_coverage", analyzeCmd.Flags().Lookup("min-coverage"))
+	viper.BindPFlag("codeowners.fail_on_low_coverage", analyzeCmd.Flags().Lookup("fail-on-error"))
+	viper.BindPFlag("output.allow_empty", analyzeCmd.Flags().Lookup("allow-empty"))
+	viper.BindPFlag("output.dump_repo_results", analyzeCmd.Flags().Lookup("dump-repo-results"))
+	viper.BindPFlag("output.load_repo_results", analyzeCmd.Flags().Lookup("load-repo-results"))
+	viper.BindPFlag("codeowners.local_file", analyzeCmd.Flags().Lookup("codeowners-file"))
 }
 
 func analyze(cmdCtx context.Context) error {
 	logger.Info("Starting PR analysis")
 
 	// Load configuration
-	cfg, err := config.LoadConfig(cfgFile, logger)
+	cfg, err := config.LoadConfig(cfgFiles, logger)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -81,6 +140,9 @@ func analyze(cmdCtx context.Context) error {
 	if orgFlag != "" {
 		cfg.GitHub.Org = orgFlag
 	}
+	if repoFlag != "" {
+		cfg.GitHub.Repo = repoFlag
+	}
 	if sinceFlag != "" {
 		cfg.TimeWindow.Since = sinceFlag
 	}
@@ -93,32 +155,107 @@ func analyze(cmdCtx context.Context) error {
 	if len(excludeTitlePrefixes) > 0 {
 		cfg.Filters.ExcludeTitlePrefixes = excludeTitlePrefixes
 	}
+	if len(excludePRFlags) > 0 {
+		for _, ref := range excludePRFlags {
+			if !config.PRRefPattern.MatchString(ref) {
+				return fmt.Errorf("invalid --exclude-pr %q (expected owner/repo#number)", ref)
+			}
+		}
+		cfg.Filters.ExcludePRNumbers = excludePRFlags
+	}
+	if len(includeRepoFlags) > 0 {
+		for _, pattern := range includeRepoFlags {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --include-repo %q: %w", pattern, err)
+			}
+		}
+		cfg.Filters.IncludeRepos = includeRepoFlags
+	}
+	if len(excludeRepoFlags) > 0 {
+		for _, pattern := range excludeRepoFlags {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --exclude-repo %q: %w", pattern, err)
+			}
+		}
+		cfg.Filters.ExcludeRepos = excludeRepoFlags
+	}
 	if outputFormatFlag != "" {
 		cfg.Output.Format = outputFormatFlag
 	}
 	if outputDirFlag != "" {
 		cfg.Output.OutputDir = outputDirFlag
 	}
+	if fetchPRDetailsFlag {
+		cfg.Fetch.PRDetails = true
+	}
+	if fetchReviewsFlag {
+		cfg.Fetch.Reviews = true
+	}
+	if fetchUserDetailsFlag {
+		cfg.Fetch.UserDetails = true
+	}
+	if fetchCommitsFlag {
+		cfg.Fetch.Commits = true
+	}
+	if fetchReviewEventsFlag {
+		cfg.Fetch.ReviewEvents = true
+	}
+	if fetchReleasesFlag {
+		cfg.Fetch.Releases = true
+	}
+	if limitReposFlag > 0 {
+		cfg.Concurrency.LimitRepos = limitReposFlag
+	}
+	if crossTabFlag {
+		cfg.Output.CrossTab = true
+	}
+	if detailedFlag {
+		cfg.Output.Detailed = true
+	}
+	if htmlReportFlag {
+		cfg.Output.HTMLReport = true
+	}
+	if allowEmptyFlag {
+		cfg.Output.AllowEmpty = true
+	}
+	if minCoverageFlag != 0 {
+		cfg.CODEOWNERS.MinCoverage = minCoverageFlag
+	}
+	if failOnErrorFlag {
+		cfg.CODEOWNERS.FailOnLowCoverage = true
+	}
+	if dumpRepoResultsFlag != "" {
+		cfg.Output.DumpRepoResults = dumpRepoResultsFlag
+	}
+	if loadRepoResultsFlag != "" {
+		cfg.Output.LoadRepoResults = loadRepoResultsFlag
+	}
+	if outputCombinedFlag {
+		cfg.Output.Combined = true
+	}
+	if codeownersFileFlag != "" {
+		cfg.CODEOWNERS.LocalFile = codeownersFileFlag
+	}
+	if noCodeownersFlag {
+		disableCodeowners(cfg)
+	}
 
-	// Get GitHub token
-	token, err := cfg.GetToken()
-	if err != nil {
-		return fmt.Errorf("failed to get GitHub token: %w", err)
+	// Print the effective configuration instead of running
+	if printConfigFlag {
+		return config.WriteEffective(os.Stdout, cfg)
+	}
+
+	// Record the effective configuration alongside the reports
+	if cfg.Output.EffectiveConfig {
+		if err := writeEffectiveConfig(cfg); err != nil {
+			return err
+		}
 	}
 
 	// Create GitHub client
-	ghClient, err := ghclient.NewClient(
-		token,
-		cfg.RateLimiter.QPS,
-		cfg.RateLimiter.Burst,
-		cfg.RateLimiter.Retry.MaxAttempts,
-		cfg.RateLimiter.Retry.BaseDelayMs,
-		cfg.RateLimiter.Threshold,
-		cfg.RateLimiter.SleepMinutes,
-		logger,
-	)
+	ghClient, err := newGitHubClient(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
 
 	// Handle cache invalidation
@@ -126,20 +263,7 @@ func analyze(cmdCtx context.Context) error {
 		if cfg.Cache.Backend == "" {
 			return fmt.Errorf("cache backend not configured, cannot invalidate")
 		}
-		// Convert TTL from minutes to duration
-		ttl := time.Duration(cfg.Cache.TTLMinutes) * time.Minute
-		if ttl == 0 {
-			// Default to 24 hours if not set
-			ttl = 24 * time.Hour
-		}
-		cacheInstance, err := cache.NewCache(
-			cfg.Cache.Backend,
-			cfg.Cache.SQLitePath,
-			cfg.Cache.JSONDir,
-			ttl,
-			false, // ignoreTTL not needed for invalidation
-			logger,
-		)
+		cacheInstance, err := cache.NewCacheFromConfig(cfg.Cache, false, logger) // ignoreTTL not needed for invalidation
 		if err != nil {
 			return fmt.Errorf("failed to initialize cache: %w", err)
 		}
@@ -177,3 +301,63 @@ func analyze(cmdCtx context.Context) error {
 	logger.Info("Analysis complete")
 	return nil
 }
+
+// writeEffectiveConfig writes the effective configuration to effective_config.yaml in the output directory
+func writeEffectiveConfig(cfg *config.Config) error {
+	if err := os.MkdirAll(cfg.Output.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(cfg.Output.OutputDir, "effective_config.yaml")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create effective config file: %w", err)
+	}
+	defer file.Close()
+
+	if err := config.WriteEffective(file, cfg); err != nil {
+		return fmt.Errorf("failed to write effective config: %w", err)
+	}
+
+	logger.Debug("Wrote effective config", zap.String("path", outputPath))
+	return nil
+}
+
+// newGitHubClient creates a rate-limited GitHub client from the config
+func newGitHubClient(cfg *config.Config) (*ghclient.Client, error) {
+	// Get GitHub token
+	token, err := cfg.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	ghClient, err := ghclient.NewClient(
+		token,
+		cfg.RateLimiter.QPS,
+		cfg.RateLimiter.Burst,
+		cfg.RateLimiter.SearchQPS,
+		cfg.RateLimiter.SearchBurst,
+		cfg.RateLimiter.Retry.MaxAttempts,
+		cfg.RateLimiter.Retry.BaseDelayMs,
+		cfg.RateLimiter.Threshold,
+		cfg.RateLimiter.SleepMinutes,
+		logger,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	return ghClient, nil
+}
+
+// disableCodeowners turns attribution off for --no-codeowners, and with it the
+// CODEOWNERS coverage gate: flags are applied after the config is validated,
+// and without CODEOWNERS every repository would fall below min_coverage
+func disableCodeowners(cfg *config.Config) {
+	if cfg.CODEOWNERS.MinCoverage > 0 {
+		logger.Warn("Ignoring codeowners.min_coverage with --no-codeowners", zap.Float64("min_coverage", cfg.CODEOWNERS.MinCoverage))
+	}
+	cfg.Attribution.Enabled = false
+	cfg.CODEOWNERS.MinCoverage = 0
+	cfg.CODEOWNERS.FailOnLowCoverage = false
+}
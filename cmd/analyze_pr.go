@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// analyzePRCmd traces the attribution of a single PR
+var analyzePRCmd = &cobra.Command{
+	Use:   "analyze-pr owner/repo#number",
+	Short: "trace the CODEOWNERS attribution of a single PR step by step",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		defer mustSync()
+		if err := analyzePR(c.Context(), args[0]); err != nil {
+			logger.Error("PR analysis failed", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(analyzePRCmd)
+
+	analyzePRCmd.Flags().StringVar(&codeownersFileFlag, "codeowners-file", "", "Trace against a local CODEOWNERS file instead of the repository's")
+}
+
+func analyzePR(cmdCtx context.Context, ref string) error {
+	match := config.PRRefPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return fmt.Errorf("invalid PR reference %q (expected owner/repo#number)", ref)
+	}
+	owner, repo := match[1], match[2]
+	number, err := strconv.Atoi(match[3])
+	if err != nil {
+		return fmt.Errorf("invalid PR number %q: %w", match[3], err)
+	}
+
+	// Load configuration (attribution mode, team rollups, rate limits)
+	cfg, err := config.LoadConfig(cfgFiles, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if codeownersFileFlag != "" {
+		cfg.CODEOWNERS.LocalFile = codeownersFileFlag
+	}
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	a, err := analyzer.NewAnalyzer(cfg, ghClient, false, false, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create analyzer: %w", err)
+	}
+
+	trace, err := a.TracePR(cmdCtx, owner, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to trace PR: %w", err)
+	}
+
+	printPRTrace(ref, cfg.Attribution.Mode, trace)
+	return nil
+}
+
+// printPRTrace prints each attribution step of a PR
+func printPRTrace(ref, mode string, trace *analyzer.PRTrace) {
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Printf("PR %s: %s\n", ref, trace.PR.GetTitle())
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Author: %s\n", trace.PR.GetUser().GetLogin())
+	if trace.PR.ClosedAt != nil {
+		fmt.Printf("Closed At: %s\n", trace.PR.GetClosedAt().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Println()
+
+	fmt.Println("1. CODEOWNERS file:")
+	if trace.CODEOWNERSPath == "" {
+		fmt.Println("  (none found)")
+	} else {
+		fmt.Printf("  %s\n", trace.CODEOWNERSPath)
+	}
+	fmt.Println()
+
+	fmt.Println("2. Changed files and matched rules:")
+	if len(trace.Files) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, file := range trace.Files {
+		if file.Rule == nil {
+			fmt.Printf("  %s\n      no matching rule\n", file.Filename)
+			continue
+		}
+		fmt.Printf("  %s\n      line %d: %s %s\n", file.Filename, file.Rule.LineNum, file.Rule.Pattern, strings.Join(file.Rule.Owners, " "))
+	}
+	fmt.Println()
+
+	fmt.Println("3. Owners of changed files:")
+	printTraceList(trace.Owners)
+
+	fmt.Printf("4. Owners after attribution mode %q:\n", mode)
+	printTraceList(trace.AttributedOwners)
+
+	fmt.Println("5. Teams the PR is counted under:")
+	printTraceList(trace.Teams)
+}
+
+// printTraceList prints one trace step's values, one per line
+func printTraceList(values []string) {
+	if len(values) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, value := range values {
+		fmt.Printf("  %s\n", value)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestNoCodeownersDisablesCoverageGate(t *testing.T) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	cfg := &config.Config{
+		Attribution: config.AttributionConfig{Enabled: true},
+		CODEOWNERS:  config.CODEOWNERSConfig{MinCoverage: 0.8, FailOnLowCoverage: true},
+	}
+
+	disableCodeowners(cfg)
+
+	if cfg.Attribution.Enabled {
+		t.Error("expected attribution to be disabled")
+	}
+	if cfg.CODEOWNERS.MinCoverage != 0 || cfg.CODEOWNERS.FailOnLowCoverage {
+		t.Errorf("coverage gate = min %v, fail %v; want it off without CODEOWNERS", cfg.CODEOWNERS.MinCoverage, cfg.CODEOWNERS.FailOnLowCoverage)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	benchmarkReposFlag   int
+	benchmarkBackendFlag string
+)
+
+// benchmarkCmd measures how much the cache speeds up an analysis
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "run a small analysis cold (API) and then warm (cache) and compare time, API calls and cache hit rate",
+	Run: func(c *cobra.Command, _ []string) {
+		defer mustSync()
+		if err := benchmark(c.Context()); err != nil {
+			logger.Error("Benchmark failed", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchmarkCmd)
+
+	benchmarkCmd.Flags().IntVar(&benchmarkReposFlag, "repos", 5, "Number of repositories (first by name) to analyze")
+	benchmarkCmd.Flags().StringVar(&benchmarkBackendFlag, "backend", "", "Cache backend to benchmark (sqlite, json, memory, tiered); defaults to cache.backend")
+}
+
+// benchmarkRun is the measurement of one analysis
+type benchmarkRun struct {
+	name     string
+	elapsed  time.Duration
+	apiCalls int64
+	hits     int64
+	misses   int64
+}
+
+func benchmark(cmdCtx context.Context) error {
+	// Load configuration
+	cfg, err := config.LoadConfig(cfgFiles, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if benchmarkReposFlag <= 0 {
+		return fmt.Errorf("--repos must be positive")
+	}
+	if benchmarkBackendFlag != "" {
+		cfg.Cache.Backend = benchmarkBackendFlag
+	}
+	if cfg.Cache.Backend == "" {
+		return fmt.Errorf("cache backend not configured, nothing to benchmark")
+	}
+
+	// Run against a throwaway cache and output directory so the cold run is
+	// really cold and the user's cache and reports are left alone
+	dir, err := os.MkdirTemp("", "ghpr-analyzer-benchmark-")
+	if err != nil {
+		return fmt.Errorf("failed to create benchmark directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg.Cache.SQLitePath = filepath.Join(dir, "cache.db")
+	cfg.Cache.JSONDir = filepath.Join(dir, "cache")
+	cfg.Cache.Near.SQLitePath = filepath.Join(dir, "near.db")
+	cfg.Cache.Near.JSONDir = filepath.Join(dir, "near")
+	cfg.Cache.Far.SQLitePath = filepath.Join(dir, "far.db")
+	cfg.Cache.Far.JSONDir = filepath.Join(dir, "far")
+	cfg.Cache.SnapshotAt = ""
+	cfg.Output.OutputDir = filepath.Join(dir, "out")
+	cfg.Notify.GitHubIssue = ""
+
+	// A fixed, small and reproducible set of repositories
+	cfg.Concurrency.LimitRepos = benchmarkReposFlag
+	cfg.Concurrency.SortRepos = true
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	var runs []benchmarkRun
+	for _, name := range []string{"cold", "warm"} {
+		a, err := analyzer.NewAnalyzer(cfg, ghClient, false, false, logger)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+
+		logger.Info("Running benchmark analysis", zap.String("run", name))
+		calls := ghClient.APICalls()
+		start := time.Now()
+		if err := a.Analyze(cmdCtx); err != nil {
+			return fmt.Errorf("%s run failed: %w", name, err)
+		}
+		run := benchmarkRun{
+			name:     name,
+			elapsed:  time.Since(start),
+			apiCalls: ghClient.APICalls() - calls,
+		}
+		run.hits, run.misses = a.CacheStats()
+		runs = append(runs, run)
+	}
+
+	fmt.Printf("\nCache benchmark: %d repositories, %s cache\n\n", benchmarkReposFlag, cfg.Cache.Backend)
+	fmt.Printf("%-6s %14s %10s %11s %13s %9s\n", "Run", "Wall Clock", "API Calls", "Cache Hits", "Cache Misses", "Hit Rate")
+	for _, run := range runs {
+		hitRate := 0.0
+		if reads := run.hits + run.misses; reads > 0 {
+			hitRate = float64(run.hits) / float64(reads) * 100
+		}
+		fmt.Printf("%-6s %14s %10d %11d %13d %8.1f%%\n",
+			run.name, run.elapsed.Round(time.Millisecond), run.apiCalls, run.hits, run.misses, hitRate)
+	}
+	if warm := runs[1].elapsed; warm > 0 {
+		fmt.Printf("\nSpeedup: %.1fx\n", float64(runs[0].elapsed)/float64(warm))
+	}
+	return nil
+}
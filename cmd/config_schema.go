@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// configSchemaCmd prints every config option with its default
+var configSchemaCmd = &cobra.Command{
+	Use:   "config-schema",
+	Short: "print all config options with their defaults as commented YAML",
+	Run: func(_ *cobra.Command, _ []string) {
+		defer mustSync()
+		if err := config.WriteSchema(os.Stdout); err != nil {
+			logger.Error("Failed to write config schema", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configSchemaCmd)
+}
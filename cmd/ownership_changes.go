@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	fromRefFlag string
+	toRefFlag   string
+)
+
+// ownershipChangesCmd reports paths whose CODEOWNERS owners changed between two refs
+var ownershipChangesCmd = &cobra.Command{
+	Use:   "ownership-changes",
+	Short: "report PR-touched paths whose CODEOWNERS owners differ between two git refs",
+	Run: func(c *cobra.Command, _ []string) {
+		defer mustSync()
+		if err := ownershipChanges(c.Context()); err != nil {
+			logger.Error("Ownership change detection failed", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ownershipChangesCmd)
+
+	ownershipChangesCmd.Flags().StringVar(&fromRefFlag, "from-ref", "", "Git ref (branch, tag or SHA) of the CODEOWNERS to compare from")
+	ownershipChangesCmd.Flags().StringVar(&toRefFlag, "to-ref", "", "Git ref of the CODEOWNERS to compare to (default branch if empty)")
+	_ = ownershipChangesCmd.MarkFlagRequired("from-ref")
+}
+
+func ownershipChanges(cmdCtx context.Context) error {
+	// Load configuration
+	cfg, err := config.LoadConfig(cfgFiles, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	a, err := analyzer.NewAnalyzer(cfg, ghClient, false, false, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create analyzer: %w", err)
+	}
+
+	return a.DetectOwnershipChanges(cmdCtx, fromRefFlag, toRefFlag)
+}
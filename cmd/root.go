@@ -16,7 +16,7 @@ const (
 
 var (
 	logger   *zap.Logger
-	cfgFile  string
+	cfgFiles []string
 	logLevel string
 )
 
@@ -46,16 +46,14 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfgFiles, "config", nil, "config file, repeatable; later files override earlier ones (default is ./config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 }
 
 // initConfig reads in config file and initializes the logger
 func initConfig() {
 	// Set up viper first to read config file
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
+	if len(cfgFiles) == 0 {
 		viper.SetConfigName("config")
 		viper.SetConfigType("yaml")
 		viper.AddConfigPath(".")
@@ -64,8 +62,14 @@ func initConfig() {
 
 	viper.AutomaticEnv()
 
-	// Read config file if it exists (before initializing logger)
-	_ = viper.ReadInConfig() // Ignore error, will use defaults if file doesn't exist
+	// Read config files if they exist (before initializing logger)
+	if len(cfgFiles) == 0 {
+		_ = viper.ReadInConfig() // Ignore error, will use defaults if file doesn't exist
+	}
+	for _, file := range cfgFiles {
+		viper.SetConfigFile(file)
+		_ = viper.MergeInConfig() // Ignore error, LoadConfig reports unreadable files
+	}
 
 	// Initialize logger with level from config file, flag, or environment
 	logger = configureLogger()
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/fishnix/ghpr-analyzer/internal/ghclient"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// serveShutdownTimeout is how long in-flight requests get to finish on shutdown
+const serveShutdownTimeout = 30 * time.Second
+
+var listenFlag string
+
+// serveCmd runs analyses on request over HTTP
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve analyses over HTTP: GET /analyze returns the aggregated results as JSON",
+	Run: func(c *cobra.Command, _ []string) {
+		defer mustSync()
+		if err := startAPI(c.Context()); err != nil {
+			logger.Error("Server failed", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&listenFlag, "listen", ":8080", "Address to listen on")
+}
+
+// apiServer runs the analyses requested over HTTP, one at a time, sharing a
+// GitHub client so they draw from the same rate limits
+type apiServer struct {
+	cfg      *config.Config
+	ghClient *ghclient.Client
+	running  chan struct{} // Holds a token while an analysis runs
+}
+
+// startAPI serves the API until SIGINT or SIGTERM, which cancel the running
+// analyses, and returns once their requests are answered
+func startAPI(cmdCtx context.Context) error {
+	if cmdCtx == nil {
+		cmdCtx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(cmdCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Load configuration
+	cfg, err := config.LoadConfig(cfgFiles, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	s := &apiServer{cfg: cfg, ghClient: ghClient, running: make(chan struct{}, 1)}
+	server := &http.Server{
+		Addr:              listenFlag,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Serving API", zap.String("listen", listenFlag))
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down API server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// handler routes the API's endpoints
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /analyze", s.analyze)
+	return mux
+}
+
+// healthz reports that the server is up
+func (s *apiServer) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// analyze runs an analysis of the org query parameter (default github.org)
+// over [since, until) and responds with the aggregated results. since
+// defaults to time_window.since and until to the time of the request; both
+// accept what time_window does, resolved against the time of the request.
+func (s *apiServer) analyze(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	// Each analysis gets its own copy of the configuration
+	cfg := *s.cfg
+	if org := query.Get("org"); org != "" {
+		cfg.GitHub.Org = org
+		cfg.GitHub.Repo = ""
+	}
+	if cfg.GitHub.Org == "" && cfg.GitHub.Repo == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("org is required"))
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	since, until := query.Get("since"), query.Get("until")
+	if since == "" {
+		since = cfg.TimeWindow.Since
+	}
+	if until == "" {
+		until = "now"
+	}
+	sinceTime, err := config.ParseTimeBound(since, now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since: %w", err))
+		return
+	}
+	untilTime, err := config.ParseTimeBound(until, now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid until: %w", err))
+		return
+	}
+	if !sinceTime.Before(untilTime) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("since must be before until"))
+		return
+	}
+	cfg.TimeWindow.Since = sinceTime.Format(time.RFC3339)
+	cfg.TimeWindow.Until = untilTime.Format(time.RFC3339)
+
+	// Analyses run one at a time; later requests wait their turn. Each
+	// reports its own API usage, not the server's since it started.
+	select {
+	case s.running <- struct{}{}:
+		defer func() { <-s.running }()
+	case <-ctx.Done():
+		return
+	}
+	s.ghClient.ResetStats()
+
+	// Reports are only written to a throwaway directory, the response is the output
+	dir, err := os.MkdirTemp("", "ghpr-analyzer-serve-")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to create output directory: %w", err))
+		return
+	}
+	defer os.RemoveAll(dir)
+	cfg.Output.OutputDir = filepath.Join(dir, "out")
+	cfg.Output.ResultsDB = ""
+	cfg.Output.DumpRepoResults = ""
+	cfg.Output.LoadRepoResults = ""
+	cfg.Notify.GitHubIssue = ""
+
+	// Progress is logged as the analysis runs, tagged with the request
+	requestLogger := logger.With(
+		zap.String("request", r.URL.RawQuery),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+	requestLogger.Info("Starting requested analysis",
+		zap.String("org", cfg.GitHub.Org),
+		zap.String("since", cfg.TimeWindow.Since),
+		zap.String("until", cfg.TimeWindow.Until),
+	)
+
+	a, err := analyzer.NewAnalyzer(&cfg, s.ghClient, false, false, requestLogger)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to create analyzer: %w", err))
+		return
+	}
+	if err := a.Analyze(ctx); err != nil {
+		requestLogger.Error("Requested analysis failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("analysis failed: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a.Result())
+}
+
+// writeJSON responds with value encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Warn("Failed to write response", zap.Error(err))
+	}
+}
+
+// writeError responds with an error as JSON
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"go.uber.org/zap"
+)
+
+func newTestAPIServer(t *testing.T) http.Handler {
+	t.Helper()
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &apiServer{
+		cfg: &config.Config{
+			GitHub:     config.GitHubConfig{Org: "myorg"},
+			TimeWindow: config.TimeWindowConfig{Since: "now-30d"},
+		},
+		running: make(chan struct{}, 1),
+	}
+	return s.handler()
+}
+
+func TestServeHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestAPIServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /healthz status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["status"] != "ok" {
+		t.Errorf("GET /healthz body = %s, want status ok", rec.Body)
+	}
+}
+
+func TestServeAnalyzeRejectsBadWindows(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid since", query: "since=yesterday"},
+		{name: "invalid until", query: "until=30"},
+		{name: "since equals until", query: "since=2025-10-01T00:00:00Z&until=2025-10-01T00:00:00Z"},
+		{name: "since after until", query: "since=now&until=now-1d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestAPIServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analyze?"+tt.query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("GET /analyze?%s status = %d, want %d", tt.query, rec.Code, http.StatusBadRequest)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] == "" {
+				t.Errorf("GET /analyze?%s body = %s, want an error", tt.query, rec.Body)
+			}
+		})
+	}
+}
+
+func TestServeRejectsOtherMethods(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestAPIServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/analyze", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /analyze status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
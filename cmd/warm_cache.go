@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fishnix/ghpr-analyzer/internal/analyzer"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// warmCacheCmd populates the cache without producing reports
+var warmCacheCmd = &cobra.Command{
+	Use:   "warm-cache",
+	Short: "fetch repos, CODEOWNERS, PRs and PR files into the cache without aggregating or exporting",
+	Run: func(c *cobra.Command, _ []string) {
+		defer mustSync()
+		if err := warmCache(c.Context()); err != nil {
+			logger.Error("Cache warming failed", zap.Error(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(warmCacheCmd)
+}
+
+func warmCache(cmdCtx context.Context) error {
+	// Load configuration
+	cfg, err := config.LoadConfig(cfgFiles, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	a, err := analyzer.NewAnalyzer(cfg, ghClient, false, false, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create analyzer: %w", err)
+	}
+
+	return a.WarmCache(cmdCtx)
+}
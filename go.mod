@@ -8,6 +8,7 @@ require (
 	github.com/spf13/viper v1.21.0
 	go.uber.org/zap v1.27.0
 	golang.org/x/oauth2 v0.33.0
+	golang.org/x/sync v0.17.0
 	golang.org/x/time v0.14.0
 	modernc.org/sqlite v1.40.0
 )
@@ -0,0 +1,524 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"go.uber.org/zap"
+)
+
+// aggregator folds processed repositories into an AnalysisResult one at a
+// time, so aggregation can run while other repositories are still being
+// fetched. It is not safe for concurrent use: a single goroutine adds results
+// and then finishes.
+type aggregator struct {
+	a          *Analyzer
+	aggregated *exporter.AnalysisResult
+	prRows     *exporter.PRCSVWriter // Detailed CSV the PRs are streamed to; nil when not exported
+	total      int                   // Repositories expected, for progress logging
+	processed  int
+
+	// Commit totals for commits-per-PR averages
+	totalCommits, commitPRs        int
+	commitsByTeam, commitPRsByTeam map[string]int
+
+	// Reviewer → author edges of merged PRs (only populated when reviews were fetched)
+	collaboration map[collaborationKey]int
+
+	// Repos and users feeding each team bucket (only tracked with output.detailed)
+	reposByTeam, usersByTeam map[string]map[string]int
+
+	// Paths owned by each team among the changed files (only tracked with output.files_touched)
+	filesByTeam map[string]map[string]int
+
+	// Cycle times in hours, for their distributions
+	cycleHours                         []float64
+	cycleHoursByTeam, cycleHoursByRepo map[string][]float64
+
+	// Lead time totals for merge-to-release averages
+	totalLeadHours                       float64
+	releasedPRs                          int
+	leadHoursByRepo, leadHoursByTeam     map[string]float64
+	releasedPRsByRepo, releasedPRsByTeam map[string]int
+}
+
+// newAggregator creates an aggregator for total repositories processed over
+// [since, until). With a non-nil prRows, each PR is also streamed to the
+// detailed CSV as soon as its teams are known.
+func (a *Analyzer) newAggregator(since, until time.Time, total int, prRows *exporter.PRCSVWriter) *aggregator {
+	g := &aggregator{
+		a:      a,
+		prRows: prRows,
+		total:  total,
+
+		commitsByTeam:   make(map[string]int),
+		commitPRsByTeam: make(map[string]int),
+		collaboration:   make(map[collaborationKey]int),
+
+		cycleHoursByTeam: make(map[string][]float64),
+		cycleHoursByRepo: make(map[string][]float64),
+
+		leadHoursByRepo:   make(map[string]float64),
+		leadHoursByTeam:   make(map[string]float64),
+		releasedPRsByRepo: make(map[string]int),
+		releasedPRsByTeam: make(map[string]int),
+	}
+
+	if a.cfg.Output.Detailed {
+		g.reposByTeam = make(map[string]map[string]int)
+		g.usersByTeam = make(map[string]map[string]int)
+	}
+	if a.cfg.Output.FilesTouched {
+		g.filesByTeam = make(map[string]map[string]int)
+	}
+
+	aggregated := &exporter.AnalysisResult{
+		PRsByRepo:        make(map[string]int),
+		PRsByTeam:        make(map[string]int),
+		PRsByUser:        make(map[string]int),
+		AvgCommitsByTeam: make(map[string]float64),
+
+		PRsByAuthorAssociation: make(map[string]int),
+
+		PRsClosingIssuesByTeam: make(map[string]int),
+		OwningTeams:            make(map[string]map[int][]string),
+		TimeWindow: exporter.TimeWindow{
+			Since: since,
+			Until: until,
+		},
+		GeneratedAt: time.Now(),
+	}
+
+	// The user × team matrix can be large, so it is only built on request
+	if a.cfg.Output.CrossTab {
+		aggregated.PRsByUserTeam = make(map[string]map[string]int)
+	}
+
+	// PR sizes are only known when details were fetched; start every bucket at
+	// zero so the histogram has no gaps
+	if a.cfg.Fetch.PRDetails {
+		aggregated.PRSizeHistogram = make(map[string]int)
+		for _, label := range prSizeBucketLabels(a.cfg.Output.PRSizeBuckets) {
+			aggregated.PRSizeHistogram[label] = 0
+		}
+	}
+
+	// Business units are an optional, config-driven rollup of repositories
+	if len(a.cfg.BusinessUnits) > 0 {
+		aggregated.PRsByBusinessUnit = make(map[string]int)
+	}
+
+	// Categories are an optional, config-driven classification of PR labels
+	if len(a.cfg.Categories) > 0 {
+		aggregated.PRsByCategory = make(map[string]int)
+		aggregated.PRsByTeamCategory = make(map[string]map[string]int)
+	}
+
+	// CODEOWNERS coverage is only measured for the codeowners.min_coverage gate
+	if a.cfg.CODEOWNERS.MinCoverage > 0 {
+		aggregated.CODEOWNERSCoverageByRepo = make(map[string]float64)
+	}
+
+	// Review churn is only known when review events were fetched
+	if a.cfg.Fetch.ReviewEvents {
+		aggregated.ReviewChurnByRepo = make(map[string]int)
+		aggregated.ReviewChurnByTeam = make(map[string]int)
+	}
+
+	// Co-authors are only known when commits were fetched
+	if a.cfg.Fetch.Commits {
+		aggregated.PRsByContributor = make(map[string]int)
+	}
+
+	// Scores are only computed when scoring rules are configured
+	if len(a.cfg.Scoring.Rules) > 0 {
+		aggregated.ScoreByTeam = make(map[string]float64)
+		aggregated.ScoreByUser = make(map[string]float64)
+	}
+
+	// The custom metric is only computed with metrics.external_command
+	if a.cfg.Metrics.ExternalCommand != "" {
+		aggregated.PRsByCustomMetric = make(map[string]int)
+		aggregated.CustomMetricByTeam = make(map[string]float64)
+		aggregated.CustomMetricByUser = make(map[string]float64)
+	}
+
+	// Release lead times are only known when releases were fetched
+	if a.cfg.Fetch.Releases {
+		aggregated.ReleaseLeadTimeHoursByRepo = make(map[string]float64)
+		aggregated.ReleaseLeadTimeHoursByTeam = make(map[string]float64)
+	}
+
+	// The merger of a PR is only known when details were fetched
+	if a.cfg.Fetch.PRDetails {
+		aggregated.SelfMergedPRsByTeam = make(map[string]int)
+	}
+
+	// Reviews are only known when fetched; an empty (non-nil) list tells the
+	// exporters the audit ran and found nothing
+	if a.cfg.Fetch.Reviews {
+		aggregated.MergedWithoutApproval = []exporter.UnapprovedMerge{}
+		aggregated.ReviewsByUser = make(map[string]int)
+	}
+
+	g.aggregated = aggregated
+	return g
+}
+
+// addInOrder folds in repositories as they complete, holding back the ones
+// that finish early so they are added in enumeration order: the outputs
+// (detailed CSV rows, floating-point sums) then do not depend on which
+// repositories happened to finish first. It returns once completed is closed.
+func (g *aggregator) addInOrder(ctx context.Context, completed <-chan completedRepo) {
+	pending := make(map[int]RepoResult)
+	next := 0
+	for c := range completed {
+		pending[c.idx] = c.result
+		for result, ok := pending[next]; ok; result, ok = pending[next] {
+			delete(pending, next)
+			g.add(ctx, result)
+			next++
+		}
+	}
+}
+
+// add folds a processed repository into the aggregate. Repositories that
+// failed are recorded in the run report and otherwise skipped.
+func (g *aggregator) add(ctx context.Context, result RepoResult) {
+	aggregated := g.aggregated
+	if result.Err != nil {
+		g.a.logger.Warn("Repository processing error",
+			zap.String("repo", fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())),
+			zap.Error(result.Err),
+		)
+		g.a.events.record(severityError, fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName()), 0, fmt.Sprintf("repository skipped: %v", result.Err))
+		return
+	}
+
+	repoName := fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())
+	prCount := len(result.PRs)
+	aggregated.PRsByRepo[repoName] = prCount
+	// Open PRs (fetch.pr_state all) are counted apart from the closed ones
+	for _, pr := range result.PRs {
+		if pr.ClosedAt == nil {
+			aggregated.OpenPRs++
+		} else {
+			aggregated.TotalPRsClosed++
+		}
+	}
+	if aggregated.PRsByBusinessUnit != nil {
+		aggregated.PRsByBusinessUnit[businessUnit(repoName, g.a.cfg.BusinessUnits)] += prCount
+	}
+	aggregated.PRsExcludedAsTrivial += result.TrivialPRs
+	aggregated.PRsExcludedAsMassChange += result.MassChangePRs
+
+	// Measure how much of the changed code CODEOWNERS covers; the files of
+	// repositories without CODEOWNERS are not fetched, but none are owned
+	if aggregated.CODEOWNERSCoverageByRepo != nil {
+		if result.CODEOWNERS == nil && prCount > 0 {
+			aggregated.CODEOWNERSCoverageByRepo[repoName] = 0
+		} else if coverage, ok := codeownersCoverage(result.CODEOWNERS, result.Files); ok {
+			aggregated.CODEOWNERSCoverageByRepo[repoName] = coverage
+		}
+	}
+
+	// Count by user (author); PRs of deleted accounts go to the ghost bucket
+	// so the by-user counts add up to the total
+	for _, pr := range result.PRs {
+		aggregated.PRsByUser[g.a.prAuthor(pr)]++
+	}
+	countAuthorAssociations(aggregated.PRsByAuthorAssociation, result.PRs)
+
+	// Credit the author and every co-author once per PR
+	if aggregated.PRsByContributor != nil {
+		for _, pr := range result.PRs {
+			contributors := map[string]bool{strings.ToLower(g.a.prAuthor(pr)): true}
+			for _, coAuthor := range coAuthors(result.Commits[pr.GetNumber()]) {
+				contributors[strings.ToLower(g.a.canonicalLogin(coAuthor))] = true
+			}
+			for contributor := range contributors {
+				aggregated.PRsByContributor[contributor]++
+			}
+		}
+	}
+
+	// Count each reviewer once per PR and flag merged PRs without an approving review
+	if result.Reviews != nil {
+		for _, pr := range result.PRs {
+			prReviews, ok := result.Reviews[pr.GetNumber()]
+			if !ok {
+				continue
+			}
+			for _, reviewer := range g.a.prReviewers(pr, prReviews) {
+				aggregated.ReviewsByUser[reviewer]++
+			}
+			if pr.MergedAt == nil {
+				continue
+			}
+			for _, reviewer := range g.a.prReviewers(pr, prReviews) {
+				g.collaboration[collaborationKey{reviewer: reviewer, author: g.a.prAuthor(pr)}]++
+			}
+			if isApproved(prReviews) {
+				continue
+			}
+			aggregated.MergedWithoutApproval = append(aggregated.MergedWithoutApproval, exporter.UnapprovedMerge{
+				Repo:     repoName,
+				PRNumber: pr.GetNumber(),
+				Author:   g.a.prAuthor(pr),
+				MergedAt: pr.GetMergedAt().Time,
+			})
+		}
+	}
+
+	// Count by team (CODEOWNERS)
+	owner := result.Repo.GetOwner().GetLogin()
+	name := result.Repo.GetName()
+	hasCodeowners := result.CODEOWNERS != nil
+
+	// Releases of repos whose releases could not be loaded are unknown (nil)
+	var releaseTimes []time.Time
+	if result.Releases != nil {
+		releaseTimes = publishedReleaseTimes(result.Releases)
+	}
+
+	if hasCodeowners && len(result.PRs) > 0 {
+		g.a.logger.Debug("Mapping PRs to CODEOWNERS owners",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			zap.Int("pr_count", len(result.PRs)),
+		)
+	}
+
+	// Owning teams computed below are cached for the next run
+	cachedTeams := len(result.PRTeams)
+
+	// Count self-merged PRs by team, including those excluded by
+	// filters.exclude_self_merged
+	aggregated.SelfMergedPRs += len(result.SelfMergedPRs)
+	if aggregated.SelfMergedPRsByTeam != nil {
+		for _, pr := range result.SelfMergedPRs {
+			for _, team := range g.a.prTeams(ctx, pr, result) {
+				aggregated.SelfMergedPRsByTeam[team]++
+			}
+		}
+	}
+
+	owningTeams := make(map[int][]string, len(result.PRs))
+	aggregated.OwningTeams[repoName] = owningTeams
+	for _, pr := range result.PRs {
+		teams := g.a.prTeams(ctx, pr, result)
+		owningTeams[pr.GetNumber()] = teams
+		for _, team := range teams {
+			aggregated.PRsByTeam[team]++
+		}
+		if g.reposByTeam != nil {
+			for _, team := range teams {
+				countNested(g.reposByTeam, team, repoName)
+				countNested(g.usersByTeam, team, g.a.prAuthor(pr))
+			}
+		}
+
+		// Credit each changed file to the teams owning that file; unlike the PR's
+		// teams, this ignores the attribution mode
+		if g.filesByTeam != nil && result.CODEOWNERS != nil {
+			for _, file := range result.Files[pr.GetNumber()] {
+				path := repoName + "/" + file.GetFilename()
+				for _, team := range g.a.teamsForOwners(result.CODEOWNERS.FindOwners(file.GetFilename()), true) {
+					countNested(g.filesByTeam, team, path)
+				}
+			}
+		}
+
+		g.prRows.Write(g.a.prRow(repoName, pr, teams))
+
+		// Weight the PR by the scoring rules
+		if aggregated.ScoreByTeam != nil {
+			score := prScore(pr, g.a.cfg.Scoring)
+			for _, team := range teams {
+				aggregated.ScoreByTeam[team] += score
+			}
+			aggregated.ScoreByUser[g.a.prAuthor(pr)] += score
+		}
+
+		// Sum numeric custom metric results and count categorical ones
+		if aggregated.PRsByCustomMetric != nil {
+			value, ok := result.CustomMetrics[pr.GetNumber()]
+			if number, numeric, valid := externalMetricValue(value); !ok || !valid {
+				aggregated.CustomMetricFailedPRs++
+			} else if numeric {
+				for _, team := range teams {
+					aggregated.CustomMetricByTeam[team] += number
+				}
+				aggregated.CustomMetricByUser[g.a.prAuthor(pr)] += number
+			} else if value != "" {
+				aggregated.PRsByCustomMetric[value]++
+			}
+		}
+
+		// Collect the time from open to merge or close
+		if cycle, ok := prCycleTime(pr, g.a.calendar); ok {
+			hours := cycle.Hours()
+			g.cycleHours = append(g.cycleHours, hours)
+			g.cycleHoursByRepo[repoName] = append(g.cycleHoursByRepo[repoName], hours)
+			for _, team := range teams {
+				g.cycleHoursByTeam[team] = append(g.cycleHoursByTeam[team], hours)
+			}
+		}
+
+		// Count review dismissals and re-requests
+		if aggregated.ReviewChurnByTeam != nil {
+			if events, ok := result.ReviewEvents[pr.GetNumber()]; ok {
+				churn := reviewChurn(events)
+				aggregated.ReviewChurn += churn
+				aggregated.ReviewChurnByRepo[repoName] += churn
+				for _, team := range teams {
+					aggregated.ReviewChurnByTeam[team] += churn
+				}
+			}
+		}
+
+		// Classify the PR by its labels
+		if aggregated.PRsByCategory != nil {
+			category := prCategory(pr, g.a.cfg.Categories)
+			aggregated.PRsByCategory[category]++
+			for _, team := range teams {
+				countNested(aggregated.PRsByTeamCategory, team, category)
+			}
+		}
+
+		// Count issue-linked (planned) work
+		if len(closingIssueRefs(pr.GetBody(), repoName)) > 0 {
+			aggregated.PRsClosingIssues++
+			for _, team := range teams {
+				aggregated.PRsClosingIssuesByTeam[team]++
+			}
+		}
+
+		// Cross-tabulate the author against the owning teams
+		if aggregated.PRsByUserTeam != nil {
+			user := g.a.prAuthor(pr)
+			if aggregated.PRsByUserTeam[user] == nil {
+				aggregated.PRsByUserTeam[user] = make(map[string]int)
+			}
+			for _, team := range teams {
+				aggregated.PRsByUserTeam[user][team]++
+			}
+		}
+
+		// Bucket PR sizes by total lines changed
+		if aggregated.PRSizeHistogram != nil && hasPRDetails(pr) {
+			lines := pr.GetAdditions() + pr.GetDeletions()
+			aggregated.PRSizeHistogram[prSizeBucket(lines, g.a.cfg.Output.PRSizeBuckets)]++
+		}
+
+		// Measure the time from merge to the first release after it
+		if releaseTimes != nil && pr.MergedAt != nil {
+			if lead, ok := releaseLeadTime(pr.GetMergedAt().Time, releaseTimes, g.a.calendar); ok {
+				hours := lead.Hours()
+				g.totalLeadHours += hours
+				g.releasedPRs++
+				g.leadHoursByRepo[repoName] += hours
+				g.releasedPRsByRepo[repoName]++
+				for _, team := range teams {
+					g.leadHoursByTeam[team] += hours
+					g.releasedPRsByTeam[team]++
+				}
+			} else {
+				aggregated.UnreleasedPRs++
+			}
+		}
+
+		// Track commit counts of merged PRs (only populated when PR details were fetched)
+		if pr.MergedAt != nil && pr.Commits != nil {
+			g.totalCommits += pr.GetCommits()
+			g.commitPRs++
+			for _, team := range teams {
+				g.commitsByTeam[team] += pr.GetCommits()
+				g.commitPRsByTeam[team]++
+			}
+		}
+	}
+
+	if len(result.PRTeams) > cachedTeams {
+		if err := g.a.cache.SetPRTeams(ctx, owner, name, result.PRTeamsKey, result.PRTeams); err != nil {
+			g.a.logger.Warn("Failed to cache PR owning teams", zap.Error(err))
+		}
+	}
+
+	g.processed++
+	if g.processed%10 == 0 || g.processed == g.total {
+		g.a.logger.Debug("Aggregation progress",
+			zap.Int("processed", g.processed),
+			zap.Int("total", g.total),
+			zap.Int("prs_processed_so_far", aggregated.TotalPRsClosed),
+		)
+	}
+}
+
+// finish computes the breakdowns that need every repository (averages,
+// distributions, sorted lists) and returns the aggregate
+func (g *aggregator) finish(ctx context.Context) *exporter.AnalysisResult {
+	aggregated := g.aggregated
+
+	// Repositories complete in any order; sort what was collected in that
+	// order so the output is deterministic
+	sort.Slice(aggregated.MergedWithoutApproval, func(i, j int) bool {
+		x, y := aggregated.MergedWithoutApproval[i], aggregated.MergedWithoutApproval[j]
+		if x.Repo != y.Repo {
+			return x.Repo < y.Repo
+		}
+		return x.PRNumber < y.PRNumber
+	})
+
+	aggregated.PRsMergedWithoutApproval = len(aggregated.MergedWithoutApproval)
+	if g.a.cfg.Fetch.Reviews {
+		aggregated.CollaborationEdges = collaborationEdges(g.collaboration)
+	}
+
+	if g.reposByTeam != nil {
+		aggregated.TeamsDetail = teamsDetail(aggregated.PRsByTeam, g.reposByTeam, g.usersByTeam)
+	}
+	if g.filesByTeam != nil {
+		aggregated.DistinctFilesByTeam = make(map[string]int, len(g.filesByTeam))
+		for team, files := range g.filesByTeam {
+			aggregated.DistinctFilesByTeam[team] = len(files)
+		}
+	}
+
+	// Attribute authors' PRs to the company on their profile
+	if g.a.cfg.Fetch.UserDetails {
+		aggregated.PRsByCompany = g.a.countPRsByCompany(ctx, aggregated.PRsByUser)
+	}
+
+	// Summarize the PRs per contributor distribution
+	aggregated.PRsPerContributor = contributorStats(aggregated.PRsByUser, g.a.cfg.Metrics.MinContributorPRs, g.a.cfg.Attribution.GhostAuthorBucket)
+
+	// Compute commits-per-PR averages
+	if g.commitPRs > 0 {
+		aggregated.AvgCommitsPerPR = float64(g.totalCommits) / float64(g.commitPRs)
+	}
+	for team, prCount := range g.commitPRsByTeam {
+		aggregated.AvgCommitsByTeam[team] = float64(g.commitsByTeam[team]) / float64(prCount)
+	}
+
+	// Summarize the cycle time distributions
+	aggregated.CycleTime = cycleTimeStats(g.cycleHours)
+	aggregated.CycleTimeByTeam = cycleTimeStatsBy(g.cycleHoursByTeam)
+	aggregated.CycleTimeByRepo = cycleTimeStatsBy(g.cycleHoursByRepo)
+
+	// Compute merge-to-release lead time averages
+	if g.releasedPRs > 0 {
+		aggregated.AvgReleaseLeadTimeHours = g.totalLeadHours / float64(g.releasedPRs)
+	}
+	for repo, prCount := range g.releasedPRsByRepo {
+		aggregated.ReleaseLeadTimeHoursByRepo[repo] = g.leadHoursByRepo[repo] / float64(prCount)
+	}
+	for team, prCount := range g.releasedPRsByTeam {
+		aggregated.ReleaseLeadTimeHoursByTeam[team] = g.leadHoursByTeam[team] / float64(prCount)
+	}
+
+	return aggregated
+}
@@ -2,18 +2,39 @@ package analyzer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fishnix/ghpr-analyzer/internal/cache"
+	"github.com/fishnix/ghpr-analyzer/internal/calendar"
 	"github.com/fishnix/ghpr-analyzer/internal/config"
 	"github.com/fishnix/ghpr-analyzer/internal/exporter"
 	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
 	"github.com/fishnix/ghpr-analyzer/internal/ghclient"
+	"github.com/fishnix/ghpr-analyzer/internal/tracing"
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// repoRetryDelay is the delay before the first retry of a failed repository;
+	// later retries wait proportionally longer
+	repoRetryDelay = 5 * time.Second
+
+	// independentCompany is the company bucket for authors without a company on their profile
+	independentCompany = "independent"
 )
 
 // Analyzer performs the PR analysis
@@ -23,9 +44,24 @@ type Analyzer struct {
 	repoEnum          *fetcher.RepoEnumerator
 	prFetcher         *fetcher.PRFetcher
 	codeownersFetcher *fetcher.CODEOWNERSFetcher
+	userFetcher       *fetcher.UserFetcher
+	releaseFetcher    *fetcher.ReleaseFetcher
 	jsonExporter      *exporter.JSONExporter
+	locale            *exporter.Locale // Formats dates and numbers of the human-readable output
 	cache             cache.Cache
+	cacheCounter      *cache.CountingCache // The same cache, counting hits and misses; nil without a cache
+	inflight          singleflight.Group   // Collapses concurrent cache-miss fetches of the same data
+	events            runEvents            // Errors and warnings for run_report.json
+	sharedOnce        sync.Once
+	shared            *fetcher.CODEOWNERSFile // CODEOWNERS of codeowners.source_repo, see sharedCODEOWNERS
+	local             *localCODEOWNERS        // CODEOWNERS files read from disk instead of the API
+	versions          sync.Map                // Parsed CODEOWNERS at merge commits by content hash, see codeownersVersion
+	baseBranches      []*regexp.Regexp        // Compiled filters.base_branch_regexes
+	tracer            *tracing.Tracer         // nil unless tracing.otlp_endpoint is set
+	calendar          *calendar.Calendar      // Measures durations; nil (wall clock) unless metrics.business_days_only is set
 	skipAPICalls      bool
+	reuseCachedTeams  bool                     // Skip fetching the files of PRs whose owning teams are cached, unless needsPRFiles
+	result            *exporter.AnalysisResult // Aggregate of the last Analyze, see Result
 	logger            *zap.Logger
 }
 
@@ -35,32 +71,47 @@ func NewAnalyzer(cfg *config.Config, ghClient *ghclient.Client, skipAPICalls boo
 
 	repoEnum := fetcher.NewRepoEnumerator(client, ghClient, cfg.GitHub.Org, logger)
 	prFetcher := fetcher.NewPRFetcher(client, ghClient, logger)
+	prFetcher.SetPRState(cfg.Fetch.PRState)
 	codeownersFetcher := fetcher.NewCODEOWNERSFetcher(client, ghClient, logger)
+	codeownersFetcher.SetEnforceGitHubLimits(cfg.CODEOWNERS.EnforceGitHubLimits)
+	codeownersFetcher.SetMaxBytes(cfg.CODEOWNERS.MaxBytes)
+	local, err := loadLocalCODEOWNERS(cfg.CODEOWNERS, codeownersFetcher)
+	if err != nil {
+		return nil, err
+	}
+	baseBranches, err := compileBaseBranchRegexes(cfg.Filters.BaseBranchRegexes)
+	if err != nil {
+		return nil, err
+	}
+	userFetcher := fetcher.NewUserFetcher(client, ghClient, logger)
+	releaseFetcher := fetcher.NewReleaseFetcher(client, ghClient, logger)
+
+	cal, err := calendar.NewCalendar(cfg.Metrics.BusinessDaysOnly, cfg.Metrics.Holidays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics config: %w", err)
+	}
 
-	jsonExporter := exporter.NewJSONExporter(cfg.Output.OutputDir, logger)
+	jsonExporter := exporter.NewJSONExporter(cfg.Output.OutputDir, cfg.Output.JSONCompact, logger)
+	if cfg.Output.IncludePRBody {
+		jsonExporter.IncludePRBody(cfg.Output.PRBodyMaxLength)
+	}
+	jsonExporter.SetCalendar(cal)
+	jsonExporter.SetIdentityMap(cfg.IdentityMap)
+	locale, err := exporter.NewLocale(cfg.Output.Locale, cfg.Output.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output config: %w", err)
+	}
 
 	// Initialize cache
 	var cacheInstance cache.Cache
-	var err error
+	var cacheCounter *cache.CountingCache
 	if cfg.Cache.Backend != "" {
-		// Convert TTL from minutes to duration
-		ttl := time.Duration(cfg.Cache.TTLMinutes) * time.Minute
-		if ttl == 0 {
-			// Default to 24 hours if not set
-			ttl = 24 * time.Hour
-		}
-
-		cacheInstance, err = cache.NewCache(
-			cfg.Cache.Backend,
-			cfg.Cache.SQLitePath,
-			cfg.Cache.JSONDir,
-			ttl,
-			ignoreTTL,
-			logger,
-		)
+		backend, err := cache.NewCacheFromConfig(cfg.Cache, ignoreTTL, logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize cache: %w", err)
 		}
+		cacheCounter = cache.NewCountingCache(backend)
+		cacheInstance = cacheCounter
 	}
 
 	return &Analyzer{
@@ -69,19 +120,56 @@ func NewAnalyzer(cfg *config.Config, ghClient *ghclient.Client, skipAPICalls boo
 		repoEnum:          repoEnum,
 		prFetcher:         prFetcher,
 		codeownersFetcher: codeownersFetcher,
+		local:             local,
+		baseBranches:      baseBranches,
+		userFetcher:       userFetcher,
+		releaseFetcher:    releaseFetcher,
 		jsonExporter:      jsonExporter,
+		locale:            locale,
 		cache:             cacheInstance,
+		cacheCounter:      cacheCounter,
+		tracer:            tracing.NewTracer(cfg.Tracing.OTLPEndpoint, logger),
+		calendar:          cal,
 		skipAPICalls:      skipAPICalls,
 		logger:            logger,
 	}, nil
 }
 
 // Analyze performs the complete analysis
-func (a *Analyzer) Analyze(ctx context.Context) error {
+func (a *Analyzer) Analyze(ctx context.Context) (err error) {
 	a.logger.Info("Starting PR analysis",
 		zap.String("org", a.cfg.GitHub.Org),
 	)
 
+	// Unlike warming the cache or detecting ownership changes, the analysis
+	// can attribute PRs with cached owning teams without their files
+	a.reuseCachedTeams = a.cache != nil
+
+	// Close cache, however the run ends
+	if a.cache != nil {
+		defer func() {
+			if err := a.cache.Close(); err != nil {
+				a.logger.Warn("Failed to close cache", zap.Error(err))
+			}
+		}()
+	}
+
+	// Report the outcome for orchestration, however the run ends
+	start := time.Now()
+	status := &exporter.RunStatus{}
+	defer func() {
+		a.exportStatus(status, start, err)
+	}()
+
+	// Trace the run; spans are exported once it is over, however it ends
+	ctx, span := a.tracer.Start(ctx, "analyze", "org", a.cfg.GitHub.Org)
+	defer func() {
+		span.End()
+		if err := a.tracer.Shutdown(context.Background()); err != nil {
+			a.logger.Warn("Failed to export trace spans", zap.Error(err))
+		}
+	}()
+
 	// Get time window
 	since, until, err := a.cfg.GetTimeWindow()
 	if err != nil {
@@ -93,51 +181,83 @@ func (a *Analyzer) Analyze(ctx context.Context) error {
 		zap.String("until", until.Format(time.RFC3339)),
 	)
 
-	// Enumerate repositories (check cache first)
-	var repos []*github.Repository
-	if a.cache != nil {
-		a.logger.Debug("Cache is configured, checking for cached repositories")
-
-		cachedRepos, err := a.cache.GetRepos(ctx, a.cfg.GitHub.Org)
-		if err == nil && len(cachedRepos) > 0 {
-			a.logger.Info("Using cached repositories", zap.Int("count", len(cachedRepos)))
-			repos = cachedRepos
+	// Stream each PR to the detailed CSV as soon as its teams are known
+	var prRows *exporter.PRCSVWriter
+	if a.cfg.Output.DetailedCSV {
+		prRows, err = exporter.NewPRCSVWriter(a.cfg.Output.OutputDir, a.logger)
+		if err != nil {
+			return fmt.Errorf("failed to export detailed PRs: %w", err)
 		}
 	}
 
-	a.logger.Info("Repositories", zap.Int("count", len(repos)))
-	if len(repos) == 0 {
-		return fmt.Errorf("no repositories found")
-	}
-
-	// Fetch from API if not cached or cache-only mode
-	if len(repos) == 0 {
-		if a.skipAPICalls {
-			return fmt.Errorf("no cached repositories found and --skip-api-calls is enabled")
+	// Aggregate frozen results of an earlier run, or fetch and process the
+	// repositories now, aggregating each one as soon as it completes
+	var results []RepoResult
+	var aggregated *exporter.AnalysisResult
+	var aggSpan *tracing.Span
+	if path := a.cfg.Output.LoadRepoResults; path != "" {
+		results, since, until, err = loadRepoResults(path)
+		if err != nil {
+			return err
 		}
+		a.logger.Info("Loaded repository results, skipping fetching",
+			zap.String("path", path),
+			zap.Int("repos", len(results)),
+			zap.String("since", since.Format(time.RFC3339)),
+			zap.String("until", until.Format(time.RFC3339)),
+		)
 
-		var err error
-		repos, err = a.repoEnum.EnumerateRepos(ctx)
+		a.logger.Info("Aggregating results from processed repositories")
+		var aggCtx context.Context
+		aggCtx, aggSpan = a.tracer.Start(ctx, "aggregate", "repos", len(results))
+		aggregated = a.aggregateResults(aggCtx, results, since, until, prRows)
+	} else {
+		repos, err := a.enumerateRepos(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to enumerate repositories: %w", err)
+			return err
 		}
 
-		// Cache repositories
-		if a.cache != nil {
-			if err := a.cache.SetRepos(ctx, a.cfg.GitHub.Org, repos); err != nil {
-				a.logger.Warn("Failed to cache repositories", zap.Error(err))
+		a.logger.Info("Found repositories", zap.Int("count", len(repos)))
+
+		// A single goroutine owns the aggregate and folds in repositories as
+		// they complete; the buffer lets workers move on without waiting for it
+		var aggCtx context.Context
+		aggCtx, aggSpan = a.tracer.Start(ctx, "aggregate", "repos", len(repos))
+		agg := a.newAggregator(since, until, len(repos), prRows)
+		completed := make(chan completedRepo, len(repos))
+		aggDone := make(chan struct{})
+		go func() {
+			defer close(aggDone)
+			agg.addInOrder(aggCtx, completed)
+		}()
+
+		// Process repositories concurrently
+		results = a.processRepos(ctx, repos, since, until, completed)
+		<-aggDone
+		a.logger.Info("Finalizing aggregation of processed repositories")
+		aggregated = agg.finish(aggCtx)
+
+		// Dumped once aggregation is done, as it updates the results' owning teams
+		if path := a.cfg.Output.DumpRepoResults; path != "" {
+			if err := dumpRepoResults(path, results, since, until); err != nil {
+				return err
 			}
+			a.logger.Info("Dumped repository results", zap.String("path", path))
 		}
 	}
-
-	a.logger.Info("Found repositories", zap.Int("count", len(repos)))
-
-	// Process repositories concurrently
-	results := a.processRepos(ctx, repos, since, until)
-
-	// Aggregate results
-	a.logger.Info("Aggregating results from processed repositories")
-	aggregated := a.aggregateResults(ctx, results, since, until)
+	for _, result := range results {
+		if result.Err != nil {
+			status.ReposErrored++
+		} else {
+			status.ReposAnalyzed++
+		}
+	}
+	if err := prRows.Close(); err != nil {
+		return fmt.Errorf("failed to export detailed PRs: %w", err)
+	}
+	aggSpan.End()
+	a.result = aggregated
+	status.TotalPRs = aggregated.TotalPRsClosed
 	a.logger.Info("Aggregation complete",
 		zap.Int("total_prs", aggregated.TotalPRsClosed),
 		zap.Int("repos_count", len(aggregated.PRsByRepo)),
@@ -145,33 +265,56 @@ func (a *Analyzer) Analyze(ctx context.Context) error {
 		zap.Int("users_count", len(aggregated.PRsByUser)),
 	)
 
+	// Flag repositories below the coverage gate; the run fails only once
+	// everything is exported
+	var lowCoverage []string
+	if minCoverage := a.cfg.CODEOWNERS.MinCoverage; minCoverage > 0 {
+		lowCoverage = lowCoverageRepos(aggregated.CODEOWNERSCoverageByRepo, minCoverage)
+		for _, repo := range lowCoverage {
+			coverage := aggregated.CODEOWNERSCoverageByRepo[repo]
+			a.logger.Warn("CODEOWNERS coverage below the minimum",
+				zap.String("repo", repo),
+				zap.Float64("coverage", coverage),
+				zap.Float64("min_coverage", minCoverage),
+			)
+			a.events.record(severityWarning, repo, 0, fmt.Sprintf("CODEOWNERS covers %.1f%% of changed files, below the minimum of %.1f%%", coverage*100, minCoverage*100))
+		}
+	}
+
 	// Export results based on format
 	a.logger.Info("Starting export", zap.String("format", a.cfg.Output.Format))
 	switch a.cfg.Output.Format {
 	case "csv":
-		csvExporter := exporter.NewCSVExporter(a.cfg.Output.OutputDir, a.logger)
+		csvExporter := exporter.NewCSVExporter(a.cfg.Output.OutputDir, a.cfg.Output.Breakdowns, a.logger)
+		csvExporter.SetLocale(a.locale)
 		if err := csvExporter.Export(aggregated); err != nil {
 			return fmt.Errorf("failed to export CSV results: %w", err)
 		}
-		// Also export JSON for compatibility
-		if err := a.jsonExporter.Export(aggregated); err != nil {
-			return fmt.Errorf("failed to export JSON results: %w", err)
-		}
-		// Also export human summary
-		summaryExporter := exporter.NewSummaryExporter(a.logger)
-		if err := summaryExporter.Export(aggregated); err != nil {
-			return fmt.Errorf("failed to export summary: %w", err)
+	case "sqlite":
+		path := a.cfg.Output.ResultsDB
+		if path == "" {
+			path = filepath.Join(a.cfg.Output.OutputDir, "results.db")
 		}
-	case "json":
-		fallthrough
-	default:
-		if err := a.jsonExporter.Export(aggregated); err != nil {
-			return fmt.Errorf("failed to export results: %w", err)
+		sqliteExporter := exporter.NewSQLiteExporter(path, a.logger)
+		if err := sqliteExporter.Export(ctx, aggregated, a.prRowsOf(results, aggregated.OwningTeams)); err != nil {
+			return fmt.Errorf("failed to export SQLite results: %w", err)
 		}
-		// Also export human summary
-		summaryExporter := exporter.NewSummaryExporter(a.logger)
-		if err := summaryExporter.Export(aggregated); err != nil {
-			return fmt.Errorf("failed to export summary: %w", err)
+	}
+	// Every format also exports JSON, for compatibility, and the human summary
+	if err := a.jsonExporter.Export(aggregated); err != nil {
+		return fmt.Errorf("failed to export JSON results: %w", err)
+	}
+	summaryExporter := exporter.NewSummaryExporter(a.cfg.Output.Breakdowns, a.logger)
+	summaryExporter.SetLocale(a.locale)
+	if err := summaryExporter.Export(aggregated); err != nil {
+		return fmt.Errorf("failed to export summary: %w", err)
+	}
+
+	// Export combined HTML report
+	if a.cfg.Output.HTMLReport {
+		htmlExporter := exporter.NewHTMLExporter(a.cfg.Output.OutputDir, a.cfg.Output.ReportSections, a.logger)
+		if err := htmlExporter.Export(aggregated); err != nil {
+			return fmt.Errorf("failed to export HTML report: %w", err)
 		}
 	}
 
@@ -185,31 +328,447 @@ func (a *Analyzer) Analyze(ctx context.Context) error {
 		}
 	}
 	a.logger.Info("Exporting per-repo PRs to JSON", zap.Int("repo_count", len(repoPRs)))
-	if err := a.jsonExporter.ExportPerRepo(repoPRs); err != nil {
+	if err := a.jsonExporter.ExportPerRepo(repoPRs, aggregated.OwningTeams); err != nil {
 		return fmt.Errorf("failed to export per-repo results: %w", err)
 	}
 
+	// Export the nested per-team breakdown
+	if a.cfg.Output.Detailed {
+		if err := a.jsonExporter.ExportTeamsDetail(aggregated.TeamsDetail); err != nil {
+			return fmt.Errorf("failed to export team details: %w", err)
+		}
+	}
+
+	// Report time lost to rate limiting
+	sleepEvents, sleepTotal := a.ghClient.SleepStats()
+	a.logger.Info("Rate limit sleeps",
+		zap.Int("sleep_events", sleepEvents),
+		zap.Duration("sleep_total", sleepTotal),
+	)
+	usage := &exporter.APIUsage{
+		RateLimitSleeps:       sleepEvents,
+		RateLimitSleepSeconds: sleepTotal.Seconds(),
+	}
+	if err := a.jsonExporter.ExportAPIUsage(usage); err != nil {
+		return fmt.Errorf("failed to export API usage: %w", err)
+	}
+	if sleepEvents > 0 {
+		a.events.record(severityWarning, "", 0, fmt.Sprintf("slept %d times for %s waiting on GitHub rate limits", sleepEvents, sleepTotal.Round(time.Second)))
+	}
+
+	// Export the rate limit of every response, showing when the budget went
+	timeline := a.ghClient.RateTimeline()
+	samples := make([]exporter.RateLimitSample, len(timeline))
+	for i, s := range timeline {
+		samples[i] = exporter.RateLimitSample{Time: s.Time, Resource: s.Resource, Remaining: s.Remaining, Limit: s.Limit}
+	}
+	if err := exporter.NewCSVExporter(a.cfg.Output.OutputDir, nil, a.logger).ExportRateLimitTimeline(samples); err != nil {
+		return fmt.Errorf("failed to export rate limit timeline: %w", err)
+	}
+
+	// Publish the summary where the team discusses it
+	a.notifyGitHubIssue(ctx, aggregated)
+
+	// Export the errors and warnings of the run
+	runReport := a.events.report()
+	a.logger.Info("Run report",
+		zap.Int("errors", runReport.Errors),
+		zap.Int("warnings", runReport.Warnings),
+	)
+	if err := a.jsonExporter.ExportRunReport(runReport); err != nil {
+		return fmt.Errorf("failed to export run report: %w", err)
+	}
+
+	// Export everything as one report.json
+	if a.cfg.Output.Combined {
+		if err := a.jsonExporter.ExportCombined(aggregated, repoPRs, usage); err != nil {
+			return fmt.Errorf("failed to export combined report: %w", err)
+		}
+	}
+
 	a.logger.Info("Analysis complete",
 		zap.Int("total_prs", aggregated.TotalPRsClosed),
-		zap.Int("repos_analyzed", len(repos)),
+		zap.Int("repos_analyzed", len(results)),
+	)
+
+	// Report how much the cache spared
+	a.logCacheStats()
+
+	if len(lowCoverage) > 0 && a.cfg.CODEOWNERS.FailOnLowCoverage {
+		return fmt.Errorf("CODEOWNERS coverage below %.2f in %d repositories: %s", a.cfg.CODEOWNERS.MinCoverage, len(lowCoverage), strings.Join(lowCoverage, ", "))
+	}
+
+	return nil
+}
+
+// exportStatus completes the run status with what is known at exit and
+// exports it to status.json; failing to do so is only a warning
+func (a *Analyzer) exportStatus(status *exporter.RunStatus, start time.Time, err error) {
+	status.Success = err == nil
+	if err != nil {
+		status.Error = err.Error()
+	}
+	sleepEvents, _ := a.ghClient.SleepStats()
+	status.RateLimited = sleepEvents > 0
+	status.APICalls = a.ghClient.APICalls()
+	status.DurationSeconds = time.Since(start).Seconds()
+
+	if err := a.jsonExporter.ExportStatus(status); err != nil {
+		a.logger.Warn("Failed to export run status", zap.Error(err))
+	}
+}
+
+// Result returns the aggregate of the last Analyze, or nil when none got as
+// far as aggregating
+func (a *Analyzer) Result() *exporter.AnalysisResult {
+	return a.result
+}
+
+// CacheStats returns the number of cache reads that hit and missed so far
+func (a *Analyzer) CacheStats() (hits, misses int64) {
+	if a.cacheCounter == nil {
+		return 0, 0
+	}
+	return a.cacheCounter.Stats()
+}
+
+// logCacheStats logs the hit rate of the cache reads of the run, overall and
+// by entity type, showing at a glance whether the cache was warm
+func (a *Analyzer) logCacheStats() {
+	if a.cacheCounter == nil {
+		return
+	}
+
+	hits, misses := a.cacheCounter.Stats()
+	total := cache.EntityStats{Hits: hits, Misses: misses}
+	a.logger.Info("Cache hit rate",
+		zap.String("hit_rate", fmt.Sprintf("%.1f%%", total.HitRate()*100)),
+		zap.Int64("hits", hits),
+		zap.Int64("misses", misses),
 	)
 
-	// Close cache
+	stats := a.cacheCounter.EntityStats()
+	entities := make([]string, 0, len(stats))
+	for entity := range stats {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	for _, entity := range entities {
+		s := stats[entity]
+		a.logger.Info("Cache hit rate by entity",
+			zap.String("entity", entity),
+			zap.String("hit_rate", fmt.Sprintf("%.1f%%", s.HitRate()*100)),
+			zap.Int64("hits", s.Hits),
+			zap.Int64("misses", s.Misses),
+		)
+	}
+}
+
+// enumerateRepos returns the organization's repositories, or only github.repo
+// when set, from the cache when possible. Enumerated repositories are narrowed
+// to those filters.include_repos and filters.exclude_repos select, skipping
+// archived (unless filters.include_archived) and disabled ones; an explicitly
+// named repository is analyzed regardless.
+func (a *Analyzer) enumerateRepos(ctx context.Context) ([]*github.Repository, error) {
+	ctx, span := a.tracer.Start(ctx, "enumerate_repos", "org", a.cfg.GitHub.Org)
+	defer span.End()
+
+	// A single repository is cached like a one-repository listing of its owner
+	org, filter := a.cfg.GitHub.Org, a.repoEnum.FilterSignature()
+	var repoName string
+	if a.cfg.GitHub.Repo != "" {
+		org, repoName, _ = strings.Cut(a.cfg.GitHub.Repo, "/")
+		filter = "repo-" + repoName
+	}
+
+	// Enumerate repositories (check cache first)
+	var repos []*github.Repository
 	if a.cache != nil {
-		if err := a.cache.Close(); err != nil {
-			a.logger.Warn("Failed to close cache", zap.Error(err))
+		a.logger.Debug("Cache is configured, checking for cached repositories")
+
+		cachedRepos, err := a.cache.GetRepos(ctx, org, filter)
+		if err == nil && len(cachedRepos) > 0 {
+			a.logger.Info("Using cached repositories", zap.Int("count", len(cachedRepos)))
+			span.SetAttributes("cached", true)
+			repos = cachedRepos
 		}
 	}
 
-	return nil
+	// Fetch from API if not cached or cache-only mode
+	if len(repos) == 0 {
+		if a.skipAPICalls {
+			return nil, fmt.Errorf("no cached repositories found and --skip-api-calls is enabled")
+		}
+
+		if repoName != "" {
+			repo, err := a.repoEnum.FetchRepo(ctx, org, repoName)
+			if err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("failed to fetch repository %s: %w", a.cfg.GitHub.Repo, err)
+			}
+			repos = []*github.Repository{repo}
+		} else {
+			var err error
+			repos, err = a.repoEnum.EnumerateRepos(ctx)
+			if err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("failed to enumerate repositories: %w", err)
+			}
+		}
+
+		// Cache repositories
+		if a.cache != nil {
+			if err := a.cache.SetRepos(ctx, org, filter, repos); err != nil {
+				a.logger.Warn("Failed to cache repositories", zap.Error(err))
+			}
+		}
+	}
+
+	// Filters select among enumerated repositories; a repository named with
+	// github.repo (or --repo) is analyzed as asked
+	if repoName == "" {
+		// Filter by name after loading, so the cached listing serves any filters
+		if len(a.cfg.Filters.IncludeRepos) > 0 || len(a.cfg.Filters.ExcludeRepos) > 0 {
+			enumerated := len(repos)
+			repos = filterRepos(repos, a.cfg.Filters.IncludeRepos, a.cfg.Filters.ExcludeRepos)
+			a.logger.Info("Filtered repositories by name",
+				zap.Int("enumerated", enumerated),
+				zap.Int("kept", len(repos)),
+			)
+		}
+
+		// Archived repositories get no new PRs and disabled ones cannot be read, so
+		// they only cost API calls
+		var archived, disabled int
+		repos, archived, disabled = activeRepos(repos, a.cfg.Filters.IncludeArchived)
+		if archived > 0 || disabled > 0 {
+			a.logger.Info("Skipped archived and disabled repositories",
+				zap.Int("archived", archived),
+				zap.Int("disabled", disabled),
+				zap.Int("kept", len(repos)),
+			)
+		}
+	}
+
+	// An org can legitimately have no repositories left after filtering; with
+	// output.allow_empty that yields an empty result instead of a failed run
+	a.logger.Info("Repositories", zap.Int("count", len(repos)))
+	if len(repos) == 0 {
+		if !a.cfg.Output.AllowEmpty {
+			return nil, fmt.Errorf("no repositories found")
+		}
+		a.logger.Warn("No repositories found, continuing with an empty result (allow_empty)")
+		a.events.record(severityWarning, "", 0, "no repositories found")
+	}
+
+	// Spend the rate budget on busy repositories first, so a partial or
+	// limited run covers the ones that matter most; ties go by name. Otherwise
+	// start repositories in a stable order so runs can be compared log line by
+	// line; a limited run is always sorted so it picks the same repositories
+	limit := a.cfg.Concurrency.LimitRepos
+	order := "name"
+	if a.cfg.Concurrency.Priority == "activity" {
+		order = "activity"
+		sort.Slice(repos, func(i, j int) bool {
+			pushedI, pushedJ := repos[i].GetPushedAt().Time, repos[j].GetPushedAt().Time
+			if !pushedI.Equal(pushedJ) {
+				return pushedI.After(pushedJ)
+			}
+			return repos[i].GetFullName() < repos[j].GetFullName()
+		})
+	} else if a.cfg.Concurrency.SortRepos || (limit > 0 && len(repos) > limit) {
+		sort.Slice(repos, func(i, j int) bool {
+			return repos[i].GetFullName() < repos[j].GetFullName()
+		})
+	}
+
+	// Truncate for smoke tests, loudly, so the output is not mistaken for a full report
+	if limit > 0 && len(repos) > limit {
+		a.logger.Warn("Limiting analysis to the first repositories; results are TRUNCATED and not a full report",
+			zap.Int("limit_repos", limit),
+			zap.Int("repos_enumerated", len(repos)),
+			zap.String("order", order),
+		)
+		a.events.record(severityWarning, "", 0, fmt.Sprintf("results truncated: only %d of %d repositories were processed (limit_repos)", limit, len(repos)))
+		repos = repos[:limit]
+	}
+
+	span.SetAttributes("repos", len(repos))
+	return repos, nil
+}
+
+// loadCODEOWNERS returns a repository's parsed CODEOWNERS from the cache or the
+// API, or nil if it has none or it could not be loaded
+func (a *Analyzer) loadCODEOWNERS(ctx context.Context, owner, name string) *fetcher.CODEOWNERSFile {
+	// A local file overrides the repository's own CODEOWNERS
+	codeowners := a.local.forRepo(owner, name)
+
+	// Check cache next
+	if codeowners == nil && a.cache != nil {
+		cachedContent, err := a.cache.GetCODEOWNERS(ctx, owner, name)
+		if err == nil && cache.IsAbsentCODEOWNERS(cachedContent) {
+			a.logger.Debug("CODEOWNERS cached as absent",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			)
+			return nil
+		}
+		if err == nil && len(cachedContent) > 0 {
+			codeowners, err = a.parseCachedCODEOWNERS(ctx, owner, name, cachedContent)
+			if err != nil {
+				a.logger.Warn("Failed to parse cached CODEOWNERS", zap.Error(err))
+				a.events.record(severityWarning, owner+"/"+name, 0, fmt.Sprintf("failed to parse cached CODEOWNERS: %v", err))
+			}
+		}
+	}
+
+	// Fetch from API if not cached
+	if codeowners == nil {
+		if !a.skipAPICalls {
+			fetched, err := a.fetchOnce("codeowners:"+owner+"/"+name, func() (interface{}, error) {
+				parsed, rawContent, err := a.codeownersFetcher.FetchCODEOWNERS(ctx, owner, name)
+				if errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+					// Treated as absent, but not cached as such, so a raised
+					// codeowners.max_bytes takes effect on the next run
+					return (*fetcher.CODEOWNERSFile)(nil), nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				if parsed != nil && a.cache != nil && len(rawContent) > 0 {
+					// Cache CODEOWNERS raw content, and the parsed file so the
+					// next run need not parse it again
+					if err := a.cache.SetCODEOWNERS(ctx, owner, name, rawContent); err != nil {
+						a.logger.Warn("Failed to cache CODEOWNERS", zap.Error(err))
+					}
+					a.cacheParsedCODEOWNERS(ctx, owner, name, rawContent, parsed)
+				}
+				if parsed == nil && a.cache != nil {
+					// Cache the absence too, sparing the lookups of every path next run
+					if err := a.cache.SetCODEOWNERS(ctx, owner, name, cache.AbsentCODEOWNERS); err != nil {
+						a.logger.Warn("Failed to cache CODEOWNERS absence", zap.Error(err))
+					}
+				}
+				return parsed, nil
+			})
+			if err != nil {
+				a.logger.Warn("Failed to fetch CODEOWNERS",
+					zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+					zap.Error(err),
+				)
+				a.events.record(severityWarning, owner+"/"+name, 0, fmt.Sprintf("failed to fetch CODEOWNERS: %v", err))
+				// Continue without CODEOWNERS
+				codeowners = nil
+			} else {
+				codeowners = fetched.(*fetcher.CODEOWNERSFile)
+			}
+		} else {
+			a.logger.Debug("Skipping CODEOWNERS fetch (cache-only mode)",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			)
+		}
+	}
+
+	// Surface CODEOWNERS lines that were ignored
+	if codeowners != nil {
+		for _, line := range codeowners.InvalidLines {
+			a.events.record(severityWarning, owner+"/"+name, 0, fmt.Sprintf("CODEOWNERS line %d has no owners and was ignored", line))
+		}
+		if codeowners.DroppedRules > 0 {
+			a.events.record(severityWarning, owner+"/"+name, 0, fmt.Sprintf("CODEOWNERS exceeds GitHub's size limit; its %d rules were dropped as GitHub ignores them", codeowners.DroppedRules))
+		}
+	}
+
+	return codeowners
+}
+
+// codeownersParserVersion is part of the key of parsed CODEOWNERS cache
+// entries; bump it whenever parsing changes so stale parses are not reused
+const codeownersParserVersion = 1
+
+// codeownersParseKey identifies the parse of CODEOWNERS content: the content
+// hash plus the parser settings that affect the result
+func (a *Analyzer) codeownersParseKey(content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("v%d:%t:%x", codeownersParserVersion, a.cfg.CODEOWNERS.EnforceGitHubLimits, sum)
+}
+
+// parseCachedCODEOWNERS returns the parse of cached CODEOWNERS content, reusing
+// the cached parse of the same content when there is one
+func (a *Analyzer) parseCachedCODEOWNERS(ctx context.Context, owner, name string, content []byte) (*fetcher.CODEOWNERSFile, error) {
+	key := a.codeownersParseKey(content)
+	if data, err := a.cache.GetParsedCODEOWNERS(ctx, owner, name, key); err == nil {
+		var parsed fetcher.CODEOWNERSFile
+		if err := json.Unmarshal(data, &parsed); err == nil {
+			return &parsed, nil
+		}
+		a.logger.Debug("Ignoring undecodable parsed CODEOWNERS cache entry",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+		)
+	}
+
+	parsed, err := a.codeownersFetcher.ParseCODEOWNERS(content, "")
+	if err != nil {
+		return nil, err
+	}
+	a.cacheParsedCODEOWNERS(ctx, owner, name, content, parsed)
+	return parsed, nil
+}
+
+// cacheParsedCODEOWNERS caches the parse of CODEOWNERS content
+func (a *Analyzer) cacheParsedCODEOWNERS(ctx context.Context, owner, name string, content []byte, parsed *fetcher.CODEOWNERSFile) {
+	data, err := json.Marshal(parsed)
+	if err == nil {
+		err = a.cache.SetParsedCODEOWNERS(ctx, owner, name, a.codeownersParseKey(content), data)
+	}
+	if err != nil {
+		a.logger.Warn("Failed to cache parsed CODEOWNERS", zap.Error(err))
+	}
+}
+
+// sharedCODEOWNERS returns the CODEOWNERS of codeowners.source_repo, loaded once
+// per run, or nil if no source repository is configured or it has none
+func (a *Analyzer) sharedCODEOWNERS(ctx context.Context) *fetcher.CODEOWNERSFile {
+	if a.cfg.CODEOWNERS.SourceRepo == "" {
+		return nil
+	}
+
+	a.sharedOnce.Do(func() {
+		owner, name, _ := strings.Cut(a.cfg.CODEOWNERS.SourceRepo, "/")
+		a.shared = a.loadCODEOWNERS(ctx, owner, name)
+		if a.shared == nil {
+			a.logger.Warn("No CODEOWNERS found in codeowners.source_repo",
+				zap.String("source_repo", a.cfg.CODEOWNERS.SourceRepo),
+			)
+		}
+	})
+	return a.shared
 }
 
 // RepoResult holds the results for a single repository
 type RepoResult struct {
-	Repo       *github.Repository
-	PRs        []*github.PullRequest
-	CODEOWNERS *fetcher.CODEOWNERSFile
-	Err        error
+	Repo          *github.Repository
+	PRs           []*github.PullRequest
+	CODEOWNERS    *fetcher.CODEOWNERSFile
+	Reviews       map[int][]*github.PullRequestReview // Keyed by PR number; nil unless reviews are fetched
+	Commits       map[int][]*github.RepositoryCommit  // Keyed by PR number; nil unless commits are fetched
+	ReviewEvents  map[int][]*github.Timeline          // Review events of the timeline keyed by PR number; nil unless fetched
+	Files         map[int][]*github.CommitFile        // Changed files keyed by PR number; nil without CODEOWNERS
+	Releases      []*github.RepositoryRelease         // nil unless releases are fetched (and the fetch succeeded)
+	TrivialPRs    int                                 // PRs excluded for touching only trivial paths
+	MassChangePRs int                                 // PRs excluded for changing more than filters.max_changed_files files
+	Err           error                               `json:"-"`
+
+	SelfMergedPRs []*github.PullRequest           // PRs merged by their author, kept or not by filters.exclude_self_merged
+	PRCODEOWNERS  map[int]*fetcher.CODEOWNERSFile // CODEOWNERS at the merge commit by PR number (nil: none then); nil unless attribution.point_in_time
+	PRTeams       map[int][]string                // Owning teams by PR number, cached across runs; nil when not cached
+	PRTeamsKey    string                          // Cache key of PRTeams, see prTeamsKey
+	CustomMetrics map[int]string                  // Result of metrics.external_command by PR number; nil without the command
+}
+
+// completedRepo is a processed repository with its position in the enumeration
+type completedRepo struct {
+	idx    int
+	result RepoResult
 }
 
 // PROwners holds the owners for a PR
@@ -218,13 +777,28 @@ type PROwners struct {
 	Owners []string
 }
 
-func (a *Analyzer) processRepos(ctx context.Context, repos []*github.Repository, since, until time.Time) []RepoResult {
+func (a *Analyzer) processRepos(ctx context.Context, repos []*github.Repository, since, until time.Time, completed chan<- completedRepo) []RepoResult {
+	// The window is half-open, [since, until), so back-to-back windows never
+	// count a PR twice; time_window.inclusive_end also admits PRs closed at until
+	if a.cfg.TimeWindow.InclusiveEnd {
+		until = until.Add(time.Nanosecond)
+	}
+
 	// Create worker pool
 	numWorkers := a.cfg.Concurrency.RepoWorkers
 	if numWorkers <= 0 {
 		numWorkers = 8
 	}
 
+	// Bound file fetches per repository so one large repo cannot monopolize the rate budget
+	fileWorkers := a.cfg.Concurrency.FileWorkersPerRepo
+	if fileWorkers <= 0 {
+		fileWorkers = 4
+	}
+
+	// Space out repository starts so workers do not all burst at once
+	interRepoDelay := time.Duration(a.cfg.RateLimiter.InterRepoDelayMs) * time.Millisecond
+
 	results := make([]RepoResult, len(repos))
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, numWorkers)
@@ -233,20 +807,59 @@ func (a *Analyzer) processRepos(ctx context.Context, repos []*github.Repository,
 		wg.Add(1)
 		sem <- struct{}{} // Acquire semaphore
 
+		if interRepoDelay > 0 && i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(interRepoDelay):
+			}
+		}
+
 		go func(idx int, r *github.Repository) {
 			defer wg.Done()
 			defer func() { <-sem }() // Release semaphore
 
-			result := a.processRepo(ctx, r, since, until)
+			result := a.tracedProcessRepo(ctx, r, since, until, fileWorkers)
+			for attempt := 1; attempt <= a.cfg.Concurrency.RepoRetries && isRetryableError(ctx, result.Err); attempt++ {
+				delay := time.Duration(attempt) * repoRetryDelay
+				a.logger.Warn("Retrying repository after transient failure",
+					zap.String("repo", fmt.Sprintf("%s/%s", r.GetOwner().GetLogin(), r.GetName())),
+					zap.Int("attempt", attempt),
+					zap.Int("max_retries", a.cfg.Concurrency.RepoRetries),
+					zap.Duration("delay", delay),
+					zap.Error(result.Err),
+				)
+				select {
+				case <-ctx.Done():
+				case <-time.After(delay):
+				}
+				result = a.tracedProcessRepo(ctx, r, since, until, fileWorkers)
+			}
 			results[idx] = result
+			if completed != nil {
+				completed <- completedRepo{idx: idx, result: result}
+			}
 		}(i, repo)
 	}
 
 	wg.Wait()
+	if completed != nil {
+		close(completed)
+	}
 	return results
 }
 
-func (a *Analyzer) processRepo(ctx context.Context, repo *github.Repository, since, until time.Time) RepoResult {
+// tracedProcessRepo processes a repository in its own span, so slow repos stand out in the trace
+func (a *Analyzer) tracedProcessRepo(ctx context.Context, repo *github.Repository, since, until time.Time, fileWorkers int) RepoResult {
+	ctx, span := a.tracer.Start(ctx, "process_repo", "repo", fmt.Sprintf("%s/%s", repo.GetOwner().GetLogin(), repo.GetName()))
+	defer span.End()
+
+	result := a.processRepo(ctx, repo, since, until, fileWorkers)
+	span.SetAttributes("prs", len(result.PRs))
+	span.RecordError(result.Err)
+	return result
+}
+
+func (a *Analyzer) processRepo(ctx context.Context, repo *github.Repository, since, until time.Time, fileWorkers int) RepoResult {
 	owner := repo.GetOwner().GetLogin()
 	name := repo.GetName()
 
@@ -263,44 +876,46 @@ func (a *Analyzer) processRepo(ctx context.Context, repo *github.Repository, sin
 		}
 	}
 
-	// Fetch CODEOWNERS file (check cache first)
+	// Fetch CODEOWNERS, falling back to the shared file of codeowners.source_repo;
+	// without attribution, neither CODEOWNERS nor the PRs' files are fetched
 	var codeowners *fetcher.CODEOWNERSFile
-	if a.cache != nil {
-		cachedContent, err := a.cache.GetCODEOWNERS(ctx, owner, name)
-		if err == nil && len(cachedContent) > 0 {
-			// Parse cached CODEOWNERS
-			// Create a temporary fetcher for parsing (no client needed for parsing)
-			tempFetcher := fetcher.NewCODEOWNERSFetcher(nil, nil, a.logger)
-			codeowners, err = tempFetcher.ParseCODEOWNERS(cachedContent, "")
-			if err != nil {
-				a.logger.Warn("Failed to parse cached CODEOWNERS", zap.Error(err))
-			}
+	pointInTime := false
+	if a.cfg.Attribution.Enabled {
+		codeowners = a.loadCODEOWNERS(ctx, owner, name)
+		// Only the repository's own file has a history to attribute PRs with
+		pointInTime = a.cfg.Attribution.PointInTime && codeowners != nil && a.local.forRepo(owner, name) == nil
+		if codeowners == nil {
+			codeowners = a.sharedCODEOWNERS(ctx)
 		}
+		codeowners = a.withoutCatchAllRules(codeowners)
 	}
 
-	// Fetch from API if not cached
-	if codeowners == nil {
-		if !a.skipAPICalls {
-			var err error
-			var rawContent []byte
-			codeowners, rawContent, err = a.codeownersFetcher.FetchCODEOWNERS(ctx, owner, name)
+	// With pr_state all, the PRs still open are listed afresh on every run,
+	// as they keep changing, and analyzed along with the closed ones, which
+	// are cached and resumed like with any other state
+	finish := func(prs []*github.PullRequest) RepoResult {
+		if a.cfg.Fetch.PRState == "all" && !a.skipAPICalls {
+			openPRs, err := a.prFetcher.FetchOpenPRs(ctx, owner, name, since, until)
 			if err != nil {
-				a.logger.Warn("Failed to fetch CODEOWNERS",
-					zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
-					zap.Error(err),
-				)
-				// Continue without CODEOWNERS
-				codeowners = nil
-			} else if codeowners != nil && a.cache != nil && len(rawContent) > 0 {
-				// Cache CODEOWNERS raw content
-				if err := a.cache.SetCODEOWNERS(ctx, owner, name, rawContent); err != nil {
-					a.logger.Warn("Failed to cache CODEOWNERS", zap.Error(err))
+				return RepoResult{
+					Repo:       repo,
+					CODEOWNERS: codeowners,
+					Err:        fmt.Errorf("failed to list open PRs: %w", err),
 				}
 			}
-		} else {
-			a.logger.Debug("Skipping CODEOWNERS fetch (cache-only mode)",
-				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
-			)
+			// A PR reopened since it was cached is only counted as open
+			prs = mergePRs(prs, openPRs)
+		}
+		return a.finishRepo(ctx, repo, codeowners, pointInTime, prs, fileWorkers)
+	}
+
+	// An earlier fetch from the same since that failed partway can be resumed.
+	// Its until need not match, as the default until moves with every run:
+	// PRs closed since then are listed on the first pages, fetched on resume.
+	var progress *cache.FetchProgress
+	if a.cache != nil {
+		if p, err := a.cache.GetFetchProgress(ctx, owner, name); err == nil && p.Since.Equal(since) {
+			progress = p
 		}
 	}
 
@@ -317,8 +932,15 @@ func (a *Analyzer) processRepo(ctx context.Context, repo *github.Repository, sin
 		}
 	}
 
-	// Fetch from API if not cached
-	if len(prs) == 0 {
+	if progress != nil && a.skipAPICalls {
+		a.logger.Warn("Using PRs of an incomplete fetch (cache-only mode)",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			zap.Int("last_page", progress.LastPage),
+		)
+	}
+
+	// Fetch from API if not cached, or resume an incomplete fetch
+	if len(prs) == 0 || (progress != nil && !a.skipAPICalls) {
 		if a.skipAPICalls {
 			return RepoResult{
 				Repo:       repo,
@@ -327,56 +949,479 @@ func (a *Analyzer) processRepo(ctx context.Context, repo *github.Repository, sin
 			}
 		}
 
-		var err error
-		prs, err = a.prFetcher.FetchClosedPRs(ctx, owner, name, since, until)
+		// Search filters the time window server-side; an interrupted list fetch
+		// is always resumed by listing
+		if a.cfg.Fetch.Mode == "search" && progress == nil {
+			searchCtx, span := a.tracer.Start(ctx, "fetch_prs", "mode", "search", "window_splits", a.cfg.Fetch.WindowSplits)
+			searchedPRs, err := a.prFetcher.SearchClosedPRsSplit(searchCtx, owner, name, since, until, a.cfg.Fetch.WindowSplits, fileWorkers)
+			span.SetAttributes("prs", len(searchedPRs))
+			span.RecordError(err)
+			span.End()
+			switch {
+			case err == nil:
+				if a.cache != nil {
+					if err := a.cache.SetPRs(ctx, owner, name, searchedPRs); err != nil {
+						a.logger.Warn("Failed to cache PRs", zap.Error(err))
+					}
+				}
+				return finish(searchedPRs)
+			case errors.Is(err, fetcher.ErrSearchCapExceeded):
+				a.logger.Info("Search result cap hit, falling back to listing PRs",
+					zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+				)
+			default:
+				return RepoResult{
+					Repo:       repo,
+					CODEOWNERS: codeowners,
+					Err:        fmt.Errorf("failed to search PRs: %w", err),
+				}
+			}
+		}
+
+		// Cache each page as it arrives so a failure later on loses nothing
+		startedAt := time.Now()
+		var cachePage func(page int, pagePRs []*github.PullRequest)
+		var onPage func(page int, pagePRs []*github.PullRequest)
+		if a.cache != nil {
+			cachePage = func(_ int, pagePRs []*github.PullRequest) {
+				if err := a.cache.SetPRs(ctx, owner, name, pagePRs); err != nil {
+					a.logger.Warn("Failed to cache PRs", zap.Error(err))
+				}
+			}
+			onPage = func(page int, pagePRs []*github.PullRequest) {
+				if err := a.cache.SetPRs(ctx, owner, name, pagePRs); err != nil {
+					a.logger.Warn("Failed to cache PRs", zap.Error(err))
+					return
+				}
+				if err := a.cache.SetFetchProgress(ctx, owner, name, &cache.FetchProgress{Since: since, Until: until, LastPage: page, StartedAt: startedAt}); err != nil {
+					a.logger.Warn("Failed to record PR fetch progress", zap.Error(err))
+				}
+			}
+		}
+
+		startPage := 1
+		seedPRs := prs
+		if progress != nil && progress.StartedAt.IsZero() {
+			// Recorded by a version that did not track when the fetch started,
+			// so the PRs that moved ahead of its pages cannot be told apart
+			a.logger.Info("Restarting incomplete PR fetch without a start time",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			)
+		} else if progress != nil {
+			startPage = progress.LastPage + 1
+			a.logger.Info("Resuming incomplete PR fetch",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+				zap.Int("cached_prs", len(seedPRs)),
+				zap.Int("start_page", startPage),
+			)
+
+			// The list is ordered by update time, so PRs updated since the
+			// interrupted fetch started, and those closed after its until,
+			// moved to pages it had already passed; fetch them before
+			// resuming, so the progress recorded from here on covers them
+			updatedSince := progress.StartedAt
+			if progress.Until.Before(until) && progress.Until.Before(updatedSince) {
+				updatedSince = progress.Until
+			}
+			key := fmt.Sprintf("prs:%s/%s:%d:%d:updated:%d", owner, name, since.Unix(), until.Unix(), updatedSince.Unix())
+			headCtx, span := a.tracer.Start(ctx, "fetch_prs", "mode", "list", "start_page", 1)
+			fetched, err := a.fetchOnce(key, func() (interface{}, error) {
+				return a.prFetcher.FetchClosedPRsUpdatedSince(headCtx, owner, name, since, until, updatedSince, cachePage)
+			})
+			// Copy, since mergePRs appends in place and the result may be shared
+			headPRs := append([]*github.PullRequest(nil), fetched.([]*github.PullRequest)...)
+			span.SetAttributes("prs", len(headPRs))
+			span.RecordError(err)
+			span.End()
+			if err != nil {
+				return RepoResult{
+					Repo:       repo,
+					CODEOWNERS: codeowners,
+					Err:        fmt.Errorf("failed to fetch PRs updated since the incomplete fetch: %w", err),
+				}
+			}
+			seedPRs = mergePRs(seedPRs, headPRs)
+		}
+
+		key := fmt.Sprintf("prs:%s/%s:%d:%d:%d", owner, name, since.Unix(), until.Unix(), startPage)
+		listCtx, span := a.tracer.Start(ctx, "fetch_prs", "mode", "list", "start_page", startPage)
+		fetched, err := a.fetchOnce(key, func() (interface{}, error) {
+			return a.prFetcher.FetchClosedPRsFromPage(listCtx, owner, name, since, until, startPage, onPage)
+		})
+		// Copy, since later steps replace PRs in place and the result may be shared
+		fetchedPRs := append([]*github.PullRequest(nil), fetched.([]*github.PullRequest)...)
+		span.SetAttributes("prs", len(fetchedPRs))
+		span.RecordError(err)
+		span.End()
 		if err != nil {
 			return RepoResult{
 				Repo:       repo,
 				CODEOWNERS: codeowners,
-				Err:        fmt.Errorf("failed to fetch PRs: %w", err),
+				Err:        fmt.Errorf("failed to fetch PRs (%d fetched PRs cached for resume): %w", len(fetchedPRs), err),
 			}
 		}
+		prs = mergePRs(seedPRs, fetchedPRs)
 
-		// Cache PRs
 		if a.cache != nil {
-			if err := a.cache.SetPRs(ctx, owner, name, prs); err != nil {
-				a.logger.Warn("Failed to cache PRs", zap.Error(err))
+			if err := a.cache.ClearFetchProgress(ctx, owner, name); err != nil {
+				a.logger.Warn("Failed to clear PR fetch progress", zap.Error(err))
 			}
 		}
 	}
 
+	return finish(prs)
+}
+
+// needsPRFiles reports whether the run uses the changed files of PRs for more
+// than attributing them, so they are fetched even for PRs whose owning teams
+// are cached. Dumped results must hold every PR's files to be re-attributed
+// later.
+func (a *Analyzer) needsPRFiles() bool {
+	return a.cfg.Filters.MeaningfulOnly ||
+		a.cfg.Output.FilesTouched ||
+		a.cfg.Output.DumpRepoResults != "" ||
+		a.cfg.CODEOWNERS.MinCoverage > 0
+}
+
+// finishRepo filters a repository's PRs and fetches the per-PR data enabled in the config
+func (a *Analyzer) finishRepo(ctx context.Context, repo *github.Repository, codeowners *fetcher.CODEOWNERSFile, pointInTime bool, prs []*github.PullRequest, fileWorkers int) RepoResult {
+	owner := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
 	// Apply filters
-	filteredPRs := a.applyFilters(prs)
+	filteredPRs := a.applyFilters(owner+"/"+name, a.excludeListedPRs(owner+"/"+name, prs))
+
+	// The changed file count of a PR is a detail field, so with
+	// filters.max_changed_files details are fetched first, sparing the file
+	// fetches of mass changes
+	detailsFirst := a.cfg.Filters.MaxChangedFiles > 0
+	if detailsFirst && a.cfg.Fetch.PRDetails && !a.skipAPICalls {
+		filteredPRs = a.fetchPRDetails(ctx, owner, name, filteredPRs)
+	}
+	filteredPRs, massChangePRs := a.excludeMassChanges(filteredPRs)
+
+	// Owning teams computed by previous runs from the same attribution inputs
+	prTeams, prTeamsKey := a.loadPRTeams(ctx, owner, name, codeowners)
+
+	// Fetch changed files for CODEOWNERS mapping and the meaningful-work filter
+	var files map[int][]*github.CommitFile
+	if codeowners != nil || a.cfg.Filters.MeaningfulOnly {
+		filePRs := filteredPRs
+		if a.reuseCachedTeams && !a.needsPRFiles() && len(prTeams) > 0 {
+			filePRs = nil
+			for _, pr := range filteredPRs {
+				if _, ok := prTeams[pr.GetNumber()]; !ok {
+					filePRs = append(filePRs, pr)
+				}
+			}
+		}
+		files = a.fetchPRFiles(ctx, owner, name, filePRs, fileWorkers)
+	}
+
+	// Drop PRs that only touch trivial paths (docs, config, ...)
+	trivialPRs := 0
+	if a.cfg.Filters.MeaningfulOnly {
+		var meaningful []*github.PullRequest
+		for _, pr := range filteredPRs {
+			if isTrivialPR(files[pr.GetNumber()], a.cfg.Filters.TrivialPaths) {
+				a.logger.Debug("Excluding PR touching only trivial paths", zap.Int("pr_number", pr.GetNumber()))
+				trivialPRs++
+				continue
+			}
+			meaningful = append(meaningful, pr)
+		}
+		filteredPRs = meaningful
+	}
+
+	// Fetch PR details for fields the list endpoint omits
+	if !detailsFirst && a.cfg.Fetch.PRDetails && !a.skipAPICalls {
+		filteredPRs = a.fetchPRDetails(ctx, owner, name, filteredPRs)
+	}
+
+	// Self-merges are only known once details are fetched, so they are
+	// filtered here rather than with the other filters
+	filteredPRs, selfMerged := a.excludeSelfMerged(filteredPRs)
+
+	// CODEOWNERS as of each PR's merge commit, once the merge commit is known
+	var prCodeowners map[int]*fetcher.CODEOWNERSFile
+	if pointInTime {
+		prCodeowners = a.codeownersAtMerges(ctx, owner, name, filteredPRs, prTeams)
+	}
+
+	// Fetch PR reviews
+	var reviews map[int][]*github.PullRequestReview
+	if a.cfg.Fetch.Reviews {
+		reviews = a.fetchPRReviews(ctx, owner, name, filteredPRs)
+	}
+
+	// Fetch PR commits for co-author credit
+	var commits map[int][]*github.RepositoryCommit
+	if a.cfg.Fetch.Commits {
+		commits = a.fetchPRCommits(ctx, owner, name, filteredPRs)
+	}
+
+	// Fetch review events for review churn
+	var reviewEvents map[int][]*github.Timeline
+	if a.cfg.Fetch.ReviewEvents {
+		reviewEvents = a.fetchPRReviewEvents(ctx, owner, name, filteredPRs)
+	}
+
+	// Fetch releases for merge-to-release lead time
+	var releases []*github.RepositoryRelease
+	if a.cfg.Fetch.Releases {
+		releases = a.fetchReleases(ctx, owner, name)
+	}
+
+	// Compute the custom metric of every PR that was kept
+	customMetrics := a.externalMetrics(ctx, owner+"/"+name, filteredPRs)
 
 	return RepoResult{
 		Repo:       repo,
 		PRs:        filteredPRs,
 		CODEOWNERS: codeowners,
+		Reviews:    reviews,
+		Commits:    commits,
+		Files:      files,
+
+		ReviewEvents:  reviewEvents,
+		Releases:      releases,
+		TrivialPRs:    trivialPRs,
+		MassChangePRs: massChangePRs,
+
+		SelfMergedPRs: selfMerged,
+		PRCODEOWNERS:  prCodeowners,
+		PRTeams:       prTeams,
+		PRTeamsKey:    prTeamsKey,
+		CustomMetrics: customMetrics,
+	}
+}
+
+// fetchPRFiles fetches the changed files of a repository's PRs, at most workers at a time
+func (a *Analyzer) fetchPRFiles(ctx context.Context, owner, repo string, prs []*github.PullRequest, workers int) map[int][]*github.CommitFile {
+	ctx, span := a.tracer.Start(ctx, "fetch_pr_files", "prs", len(prs))
+	defer span.End()
+
+	files := make(map[int][]*github.CommitFile, len(prs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	for _, pr := range prs {
+		wg.Add(1)
+		sem <- struct{}{} // Acquire semaphore
+
+		go func(prNumber int) {
+			defer wg.Done()
+			defer func() { <-sem }() // Release semaphore
+
+			prFiles := a.getPRFiles(ctx, owner, repo, prNumber)
+			if prFiles == nil {
+				return
+			}
+
+			mu.Lock()
+			files[prNumber] = prFiles
+			mu.Unlock()
+		}(pr.GetNumber())
+	}
+
+	wg.Wait()
+	return files
+}
+
+// isTrivialPR reports whether all of a PR's changed files match the trivial path
+// globs. PRs whose files are unknown are never trivial.
+func isTrivialPR(files []*github.CommitFile, trivialPaths []string) bool {
+	if len(files) == 0 || len(trivialPaths) == 0 {
+		return false
+	}
+
+	for _, file := range files {
+		trivial := false
+		for _, pattern := range trivialPaths {
+			if matchesGlob(pattern, file.GetFilename()) {
+				trivial = true
+				break
+			}
+		}
+		if !trivial {
+			return false
+		}
 	}
+	return true
 }
 
-func (a *Analyzer) applyFilters(prs []*github.PullRequest) []*github.PullRequest {
+// globRegexps caches the compiled regexp of each glob matchesGlob has seen.
+// Globs come from the config, so the cache stays small while saving a
+// compilation per file of every PR.
+var globRegexps sync.Map // pattern -> *regexp.Regexp
+
+// matchesGlob matches a path against a glob where "**" spans directories and
+// "*" does not. Patterns without a slash match the file name in any directory.
+func matchesGlob(pattern, filePath string) bool {
+	if !strings.Contains(pattern, "/") {
+		filePath = path.Base(filePath)
+	}
+
+	re, ok := globRegexps.Load(pattern)
+	if !ok {
+		compiled, err := compileGlob(pattern)
+		if err != nil {
+			return false
+		}
+		re, _ = globRegexps.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(strings.TrimPrefix(filePath, "/"))
+}
+
+// compileGlob translates a glob as matched by matchesGlob into a regexp
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
+
+// revertedPRRef matches the PR reference in a revert PR body, e.g. "Reverts org/repo#123"
+var revertedPRRef = regexp.MustCompile(`(?i)\breverts?\s+([\w.-]+/[\w.-]+)?#(\d+)`)
+
+// isRevertPR reports whether a PR is a revert, as titled by GitHub's revert button
+func isRevertPR(pr *github.PullRequest) bool {
+	return strings.HasPrefix(pr.GetTitle(), `Revert "`)
+}
+
+// revertedPRNumber returns the number of the PR of repo a revert PR body
+// references, or 0; a PR of another repository shares only its number
+func revertedPRNumber(body, repo string) int {
+	match := revertedPRRef.FindStringSubmatch(body)
+	if match == nil || (match[1] != "" && !strings.EqualFold(match[1], repo)) {
+		return 0
+	}
+	number, err := strconv.Atoi(match[2])
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// closingIssueRef matches a GitHub closing keyword followed by an issue reference:
+// #123, owner/repo#123 or an issue URL
+var closingIssueRef = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+(?:([\w.-]+/[\w.-]+)?#(\d+)|https://github\.com/([\w.-]+/[\w.-]+)/issues/(\d+))\b`)
+
+// closingIssueRefs returns the distinct issues a PR body closes via GitHub's
+// closing keywords, as owner/repo#number; bare #number references resolve to repo
+func closingIssueRefs(body, repo string) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	for _, match := range closingIssueRef.FindAllStringSubmatch(body, -1) {
+		issueRepo, number := match[1], match[2]
+		if match[4] != "" {
+			issueRepo, number = match[3], match[4]
+		}
+		if issueRepo == "" {
+			issueRepo = repo
+		}
+		ref := strings.ToLower(issueRepo) + "#" + number
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// isRetryableError reports whether a repository failure looks transient
+// (a 5xx response or a timeout) and the run has not been cancelled
+func isRetryableError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// mergePRs combines PRs seeded from the cache with freshly fetched ones,
+// preferring the fetched copy of a PR present in both
+func mergePRs(seed, fetched []*github.PullRequest) []*github.PullRequest {
+	if len(seed) == 0 {
+		return fetched
+	}
+
+	seen := make(map[int]bool, len(fetched))
+	for _, pr := range fetched {
+		seen[pr.GetNumber()] = true
+	}
+
+	merged := fetched
+	for _, pr := range seed {
+		if !seen[pr.GetNumber()] {
+			merged = append(merged, pr)
+		}
+	}
+	return merged
+}
+
+func (a *Analyzer) applyFilters(repo string, prs []*github.PullRequest) []*github.PullRequest {
 	var filtered []*github.PullRequest
+	filters := a.repoFilters(repo)
 
 	excludeAuthors := make(map[string]bool)
-	for _, author := range a.cfg.Filters.ExcludeAuthors {
+	for _, author := range filters.ExcludeAuthors {
 		excludeAuthors[author] = true
 	}
 
-	excludePrefixes := a.cfg.Filters.ExcludeTitlePrefixes
+	excludePrefixes := filters.ExcludeTitlePrefixes
 
-	for _, pr := range prs {
-		// Check author exclusion
-		if pr.User != nil {
-			author := pr.User.GetLogin()
-			if excludeAuthors[author] {
-				a.logger.Debug("Excluding PR by author",
-					zap.Int("pr_number", pr.GetNumber()),
-					zap.String("author", author),
-				)
-				continue
+	// PRs reverted by a revert PR in the same batch cancel out with it
+	revertedPRs := make(map[int]bool)
+	if filters.ExcludeReverts {
+		for _, pr := range prs {
+			if isRevertPR(pr) {
+				if number := revertedPRNumber(pr.GetBody(), repo); number != 0 {
+					revertedPRs[number] = true
+				}
 			}
 		}
+	}
+
+	for _, pr := range prs {
+		// Check PR state; cached PRs may have been fetched with a broader state
+		if a.cfg.Fetch.PRState == "merged" && pr.MergedAt == nil {
+			a.logger.Debug("Excluding unmerged PR", zap.Int("pr_number", pr.GetNumber()))
+			continue
+		}
+
+		// Check author exclusion, by the login itself or the identity it is aliased to
+		if author := a.prAuthor(pr); excludeAuthors[author] || excludeAuthors[pr.GetUser().GetLogin()] {
+			a.logger.Debug("Excluding PR by author",
+				zap.Int("pr_number", pr.GetNumber()),
+				zap.String("author", author),
+			)
+			continue
+		}
 
 		// Check title prefix exclusion
 		title := pr.GetTitle()
@@ -395,22 +1440,745 @@ func (a *Analyzer) applyFilters(prs []*github.PullRequest) []*github.PullRequest
 			continue
 		}
 
+		// Check base branch
+		if len(a.baseBranches) > 0 && !matchesAnyRegex(a.baseBranches, pr.GetBase().GetRef()) {
+			a.logger.Debug("Excluding PR by base branch",
+				zap.Int("pr_number", pr.GetNumber()),
+				zap.String("base", pr.GetBase().GetRef()),
+			)
+			continue
+		}
+
+		// Check revert exclusion
+		if filters.ExcludeReverts {
+			if isRevertPR(pr) {
+				a.logger.Debug("Excluding revert PR", zap.Int("pr_number", pr.GetNumber()))
+				continue
+			}
+			if revertedPRs[pr.GetNumber()] {
+				a.logger.Debug("Excluding reverted PR", zap.Int("pr_number", pr.GetNumber()))
+				continue
+			}
+		}
+
 		filtered = append(filtered, pr)
 	}
 
 	return filtered
 }
 
+// compileBaseBranchRegexes compiles filters.base_branch_regexes once per run
+func compileBaseBranchRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filters.base_branch_regexes entry %q: %w", pattern, err)
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes, nil
+}
+
+// matchesAnyRegex reports whether s matches at least one of the regexes
+func matchesAnyRegex(regexes []*regexp.Regexp, s string) bool {
+	for _, re := range regexes {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeSelfMerged returns the PRs merged by their own author, dropping them
+// from prs when filters.exclude_self_merged is set
+func (a *Analyzer) excludeSelfMerged(prs []*github.PullRequest) (kept, selfMerged []*github.PullRequest) {
+	for _, pr := range prs {
+		if !isSelfMerged(pr) {
+			kept = append(kept, pr)
+			continue
+		}
+		selfMerged = append(selfMerged, pr)
+		if a.cfg.Filters.ExcludeSelfMerged {
+			a.logger.Debug("Excluding self-merged PR", zap.Int("pr_number", pr.GetNumber()))
+			continue
+		}
+		kept = append(kept, pr)
+	}
+	return kept, selfMerged
+}
+
+// excludeMassChanges drops the PRs changing more files than
+// filters.max_changed_files, returning how many were dropped. PRs whose changed
+// file count is unknown (details not fetched) are kept.
+func (a *Analyzer) excludeMassChanges(prs []*github.PullRequest) ([]*github.PullRequest, int) {
+	limit := a.cfg.Filters.MaxChangedFiles
+	if limit <= 0 {
+		return prs, 0
+	}
+
+	var kept []*github.PullRequest
+	excluded := 0
+	for _, pr := range prs {
+		if pr.ChangedFiles != nil && pr.GetChangedFiles() > limit {
+			a.logger.Debug("Excluding mass change PR",
+				zap.Int("pr_number", pr.GetNumber()),
+				zap.Int("changed_files", pr.GetChangedFiles()),
+			)
+			excluded++
+			continue
+		}
+		kept = append(kept, pr)
+	}
+	return kept, excluded
+}
+
+// excludeListedPRs drops the PRs of a repository listed in filters.exclude_pr_numbers
+func (a *Analyzer) excludeListedPRs(repo string, prs []*github.PullRequest) []*github.PullRequest {
+	if len(a.cfg.Filters.ExcludePRNumbers) == 0 {
+		return prs
+	}
+
+	excluded := make(map[string]bool, len(a.cfg.Filters.ExcludePRNumbers))
+	for _, ref := range a.cfg.Filters.ExcludePRNumbers {
+		excluded[strings.ToLower(ref)] = true
+	}
+
+	var kept []*github.PullRequest
+	for _, pr := range prs {
+		if excluded[strings.ToLower(fmt.Sprintf("%s#%d", repo, pr.GetNumber()))] {
+			a.logger.Debug("Excluding listed PR",
+				zap.String("repo", repo),
+				zap.Int("pr_number", pr.GetNumber()),
+			)
+			continue
+		}
+		kept = append(kept, pr)
+	}
+	return kept
+}
+
+// fetchPRDetails replaces list-endpoint PRs with their full representation so
+// detail-only fields (commits, additions, deletions) are populated. PRs that
+// already carry details (e.g. from cache) are left untouched.
+func (a *Analyzer) fetchPRDetails(ctx context.Context, owner, repo string, prs []*github.PullRequest) []*github.PullRequest {
+	var fetchedPRs []*github.PullRequest
+	for i, pr := range prs {
+		if hasPRDetails(pr) {
+			continue
+		}
+
+		fetched, err := a.fetchOnce(fmt.Sprintf("details:%s/%s#%d", owner, repo, pr.GetNumber()), func() (interface{}, error) {
+			return a.prFetcher.FetchPRDetails(ctx, owner, repo, pr.GetNumber())
+		})
+		if err != nil {
+			a.logger.Debug("Failed to fetch PR details",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+				zap.Int("pr_number", pr.GetNumber()),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, owner+"/"+repo, pr.GetNumber(), fmt.Sprintf("failed to fetch PR details: %v", err))
+			continue
+		}
+
+		detailed := fetched.(*github.PullRequest)
+		prs[i] = detailed
+		fetchedPRs = append(fetchedPRs, detailed)
+	}
+
+	// Cache the detailed PRs so later runs don't fetch them again
+	if len(fetchedPRs) > 0 && a.cache != nil {
+		if err := a.cache.SetPRs(ctx, owner, repo, fetchedPRs); err != nil {
+			a.logger.Warn("Failed to cache PR details", zap.Error(err))
+		}
+	}
+
+	return prs
+}
+
+// fetchPRReviews fetches the reviews of each PR (checking the cache first).
+// PRs whose reviews could not be loaded are absent from the returned map.
+func (a *Analyzer) fetchPRReviews(ctx context.Context, owner, repo string, prs []*github.PullRequest) map[int][]*github.PullRequestReview {
+	reviews := make(map[int][]*github.PullRequestReview)
+	for _, pr := range prs {
+		prNumber := pr.GetNumber()
+
+		if a.cache != nil {
+			cachedReviews, err := a.cache.GetPRReviews(ctx, owner, repo, prNumber)
+			if err == nil {
+				reviews[prNumber] = cachedReviews
+				continue
+			}
+		}
+
+		if a.skipAPICalls {
+			a.logger.Debug("Skipping PR reviews fetch (cache-only mode)",
+				zap.Int("pr_number", prNumber),
+			)
+			continue
+		}
+
+		fetched, err := a.fetchOnce(fmt.Sprintf("reviews:%s/%s#%d", owner, repo, prNumber), func() (interface{}, error) {
+			prReviews, err := a.prFetcher.FetchPRReviews(ctx, owner, repo, prNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			// Cache PR reviews
+			if a.cache != nil {
+				if err := a.cache.SetPRReviews(ctx, owner, repo, prNumber, prReviews); err != nil {
+					a.logger.Warn("Failed to cache PR reviews", zap.Error(err))
+				}
+			}
+			return prReviews, nil
+		})
+		if err != nil {
+			a.logger.Debug("Failed to fetch PR reviews",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+				zap.Int("pr_number", prNumber),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, owner+"/"+repo, prNumber, fmt.Sprintf("failed to fetch PR reviews: %v", err))
+			continue
+		}
+		reviews[prNumber] = fetched.([]*github.PullRequestReview)
+	}
+
+	return reviews
+}
+
+// fetchPRCommits fetches the commits of each PR (checking the cache first).
+// PRs whose commits could not be loaded are absent from the returned map.
+func (a *Analyzer) fetchPRCommits(ctx context.Context, owner, repo string, prs []*github.PullRequest) map[int][]*github.RepositoryCommit {
+	commits := make(map[int][]*github.RepositoryCommit)
+	for _, pr := range prs {
+		prNumber := pr.GetNumber()
+
+		if a.cache != nil {
+			cachedCommits, err := a.cache.GetPRCommits(ctx, owner, repo, prNumber)
+			if err == nil {
+				commits[prNumber] = cachedCommits
+				continue
+			}
+		}
+
+		if a.skipAPICalls {
+			a.logger.Debug("Skipping PR commits fetch (cache-only mode)",
+				zap.Int("pr_number", prNumber),
+			)
+			continue
+		}
+
+		fetched, err := a.fetchOnce(fmt.Sprintf("commits:%s/%s#%d", owner, repo, prNumber), func() (interface{}, error) {
+			prCommits, err := a.prFetcher.FetchPRCommits(ctx, owner, repo, prNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			// Cache PR commits
+			if a.cache != nil {
+				if err := a.cache.SetPRCommits(ctx, owner, repo, prNumber, prCommits); err != nil {
+					a.logger.Warn("Failed to cache PR commits", zap.Error(err))
+				}
+			}
+			return prCommits, nil
+		})
+		if err != nil {
+			a.logger.Debug("Failed to fetch PR commits",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+				zap.Int("pr_number", prNumber),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, owner+"/"+repo, prNumber, fmt.Sprintf("failed to fetch PR commits: %v", err))
+			continue
+		}
+		commits[prNumber] = fetched.([]*github.RepositoryCommit)
+	}
+
+	return commits
+}
+
+// fetchReleases fetches the releases of a repository (checking the cache first).
+// It returns nil when the releases could not be loaded, and a non-nil empty
+// slice for a repository without releases.
+func (a *Analyzer) fetchReleases(ctx context.Context, owner, repo string) []*github.RepositoryRelease {
+	if a.cache != nil {
+		cachedReleases, err := a.cache.GetReleases(ctx, owner, repo)
+		if err == nil {
+			if cachedReleases == nil {
+				cachedReleases = []*github.RepositoryRelease{}
+			}
+			return cachedReleases
+		}
+	}
+
+	if a.skipAPICalls {
+		a.logger.Debug("Skipping releases fetch (cache-only mode)",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		)
+		return nil
+	}
+
+	fetched, err := a.fetchOnce(fmt.Sprintf("releases:%s/%s", owner, repo), func() (interface{}, error) {
+		releases, err := a.releaseFetcher.FetchReleases(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		if releases == nil {
+			releases = []*github.RepositoryRelease{}
+		}
+
+		// Cache releases
+		if a.cache != nil {
+			if err := a.cache.SetReleases(ctx, owner, repo, releases); err != nil {
+				a.logger.Warn("Failed to cache releases", zap.Error(err))
+			}
+		}
+		return releases, nil
+	})
+	if err != nil {
+		a.logger.Debug("Failed to fetch releases",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+			zap.Error(err),
+		)
+		a.events.record(severityWarning, owner+"/"+repo, 0, fmt.Sprintf("failed to fetch releases: %v", err))
+		return nil
+	}
+	return fetched.([]*github.RepositoryRelease)
+}
+
+// coAuthorTrailer matches a Co-authored-by commit trailer, capturing the email
+var coAuthorTrailer = regexp.MustCompile(`(?im)^[ \t]*co-authored-by:[^<\n]*<([^>\n]+)>`)
+
+// noreplyEmail matches GitHub's noreply commit email, capturing the login
+var noreplyEmail = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// coAuthors returns the distinct co-authors credited by Co-authored-by trailers
+// in a PR's commit messages. Co-authors are identified by login when their
+// email is a GitHub noreply address, and by lowercased email otherwise.
+func coAuthors(commits []*github.RepositoryCommit) []string {
+	seen := make(map[string]bool)
+	var authors []string
+	for _, commit := range commits {
+		for _, match := range coAuthorTrailer.FindAllStringSubmatch(commit.GetCommit().GetMessage(), -1) {
+			email := strings.ToLower(strings.TrimSpace(match[1]))
+			author := email
+			if login := noreplyEmail.FindStringSubmatch(email); login != nil {
+				author = login[1]
+			}
+			if !seen[author] {
+				seen[author] = true
+				authors = append(authors, author)
+			}
+		}
+	}
+	return authors
+}
+
+// isApproved reports whether any of the reviews approved the PR
+func isApproved(reviews []*github.PullRequestReview) bool {
+	for _, review := range reviews {
+		if review.GetState() == "APPROVED" {
+			return true
+		}
+	}
+	return false
+}
+
+// prAuthor returns the login of a PR's author, or the ghost bucket for PRs of
+// deleted accounts, which have no author
+func (a *Analyzer) prAuthor(pr *github.PullRequest) string {
+	if pr.User == nil || pr.User.GetLogin() == "" {
+		return a.cfg.Attribution.GhostAuthorBucket
+	}
+	return a.canonicalLogin(pr.User.GetLogin())
+}
+
+// canonicalLogin returns the identity a login is aliased to in identity_map,
+// or the login itself when it is not aliased
+func (a *Analyzer) canonicalLogin(login string) string {
+	if canonical, ok := a.cfg.IdentityMap[strings.ToLower(login)]; ok {
+		return canonical
+	}
+	return login
+}
+
+// filterRepos returns the repositories whose name matches one of the include
+// globs (any name when there are none) and none of the exclude globs
+func filterRepos(repos []*github.Repository, include, exclude []string) []*github.Repository {
+	matchesAny := func(patterns []string, name string) bool {
+		for _, pattern := range patterns {
+			// Patterns are validated with the config
+			if matched, _ := filepath.Match(pattern, name); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	kept := make([]*github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		name := repo.GetName()
+		if matchesAny(exclude, name) || (len(include) > 0 && !matchesAny(include, name)) {
+			continue
+		}
+		kept = append(kept, repo)
+	}
+	return kept
+}
+
+// activeRepos returns the repositories that are not disabled nor, unless
+// includeArchived, archived, with the number of each skipped
+func activeRepos(repos []*github.Repository, includeArchived bool) (active []*github.Repository, archived, disabled int) {
+	active = make([]*github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		switch {
+		case repo.GetDisabled():
+			disabled++
+		case repo.GetArchived() && !includeArchived:
+			archived++
+		default:
+			active = append(active, repo)
+		}
+	}
+	return active, archived, disabled
+}
+
+// collaborationKey identifies a reviewer → author edge
+type collaborationKey struct {
+	reviewer, author string
+}
+
+// prReviewers returns the distinct reviewers of a PR other than its author, by
+// canonical identity
+func (a *Analyzer) prReviewers(pr *github.PullRequest, reviews []*github.PullRequestReview) []string {
+	author := a.canonicalLogin(pr.GetUser().GetLogin())
+	seen := make(map[string]bool)
+	var reviewers []string
+	for _, review := range reviews {
+		reviewer := a.canonicalLogin(review.GetUser().GetLogin())
+		if reviewer == "" || strings.EqualFold(reviewer, author) || seen[reviewer] {
+			continue
+		}
+		seen[reviewer] = true
+		reviewers = append(reviewers, reviewer)
+	}
+	return reviewers
+}
+
+// collaborationEdges converts edge counts to an edge list, heaviest edges first
+func collaborationEdges(counts map[collaborationKey]int) []exporter.CollaborationEdge {
+	edges := make([]exporter.CollaborationEdge, 0, len(counts))
+	for key, count := range counts {
+		edges = append(edges, exporter.CollaborationEdge{Reviewer: key.reviewer, Author: key.author, Count: count})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Count != edges[j].Count {
+			return edges[i].Count > edges[j].Count
+		}
+		if edges[i].Reviewer != edges[j].Reviewer {
+			return edges[i].Reviewer < edges[j].Reviewer
+		}
+		return edges[i].Author < edges[j].Author
+	})
+	return edges
+}
+
+// prTeams returns the teams a PR of a repository result is attributed to,
+// reusing and recording them in result.PRTeams
+func (a *Analyzer) prTeams(ctx context.Context, pr *github.PullRequest, result RepoResult) []string {
+	// Without attribution, PRs count toward no team, not even a bucket
+	if !a.cfg.Attribution.Enabled {
+		return nil
+	}
+
+	if teams, ok := result.PRTeams[pr.GetNumber()]; ok {
+		return teams
+	}
+
+	// Attribute with the CODEOWNERS of the merge commit when it is known
+	codeowners := result.CODEOWNERS
+	if version, ok := result.PRCODEOWNERS[pr.GetNumber()]; ok {
+		codeowners = version
+	}
+	hasCodeowners := codeowners != nil
+
+	var owners []string
+	if hasCodeowners {
+		// Map PR files to owners
+		owner, name := result.Repo.GetOwner().GetLogin(), result.Repo.GetName()
+		prOwners := a.mapPROwners(ctx, pr, codeowners, owner, name, result.Files)
+		// Apply attribution mode
+		owners = a.applyAttributionMode(owner+"/"+name, prOwners)
+	}
+
+	teams := a.teamsForOwners(owners, hasCodeowners)
+
+	// Only teams computed from the PR's files are worth keeping; PRs whose
+	// files could not be loaded are attributed again next run
+	if _, ok := result.Files[pr.GetNumber()]; ok && result.PRTeams != nil {
+		result.PRTeams[pr.GetNumber()] = teams
+	}
+	return teams
+}
+
+// prTeamsVersion is part of the key of cached owning teams; bump it whenever
+// attribution changes so stale teams are not reused
+const prTeamsVersion = 4
+
+// prTeamsKey identifies the inputs of attribution besides a PR's files: the
+// CODEOWNERS rules and the attribution settings. Cached owning teams are only
+// reused under the same key, so editing CODEOWNERS or the config invalidates them.
+func (a *Analyzer) prTeamsKey(repo string, codeowners *fetcher.CODEOWNERSFile) string {
+	inputs, _ := json.Marshal(struct {
+		Version     int
+		Rules       []fetcher.CODEOWNERSRule
+		Attribution config.AttributionConfig
+		TeamRollup  []config.TeamRollupConfig
+		Override    config.RepoOverrideConfig
+	}{prTeamsVersion, codeowners.Rules, a.cfg.Attribution, a.cfg.TeamRollup, a.repoOverride(repo)})
+	return fmt.Sprintf("%x", sha256.Sum256(inputs))
+}
+
+// loadPRTeams returns the cached owning teams of a repository's PRs (empty on
+// a miss) and their cache key, or nil when they are not cached: without a
+// cache, or without CODEOWNERS, whose buckets cost nothing to compute
+func (a *Analyzer) loadPRTeams(ctx context.Context, owner, name string, codeowners *fetcher.CODEOWNERSFile) (map[int][]string, string) {
+	if a.cache == nil || codeowners == nil {
+		return nil, ""
+	}
+
+	key := a.prTeamsKey(owner+"/"+name, codeowners)
+	teams, err := a.cache.GetPRTeams(ctx, owner, name, key)
+	if err != nil || teams == nil {
+		teams = make(map[int][]string)
+	}
+	return teams, key
+}
+
+// isSelfMerged reports whether a PR was merged by its own author. The merger
+// is only known for PRs fetched individually (fetch.pr_details).
+func isSelfMerged(pr *github.PullRequest) bool {
+	if pr.MergedAt == nil || pr.GetUser().GetLogin() == "" {
+		return false
+	}
+	return strings.EqualFold(pr.GetMergedBy().GetLogin(), pr.GetUser().GetLogin())
+}
+
+// unknownAuthorAssociation is the author association bucket of PRs the API
+// returned without one
+const unknownAuthorAssociation = "UNKNOWN"
+
+// countAuthorAssociations counts PRs by the author's association with the
+// repository (MEMBER, COLLABORATOR, CONTRIBUTOR, FIRST_TIME_CONTRIBUTOR, NONE, ...)
+func countAuthorAssociations(counts map[string]int, prs []*github.PullRequest) {
+	for _, pr := range prs {
+		association := strings.ToUpper(pr.GetAuthorAssociation())
+		if association == "" {
+			association = unknownAuthorAssociation
+		}
+		counts[association]++
+	}
+}
+
+// prRow returns the per-PR export row of a PR owned by teams
+func (a *Analyzer) prRow(repoName string, pr *github.PullRequest, teams []string) exporter.PRRow {
+	return exporter.PRRow{
+		Repo:      repoName,
+		Number:    pr.GetNumber(),
+		Title:     pr.GetTitle(),
+		Author:    a.prAuthor(pr),
+		State:     pr.GetState(),
+		CreatedAt: pr.GetCreatedAt().Time,
+		ClosedAt:  pr.GetClosedAt().Time,
+		MergedAt:  pr.GetMergedAt().Time,
+		Teams:     teams,
+		URL:       pr.GetHTMLURL(),
+	}
+}
+
+// prRowsOf returns the per-PR export rows of the analyzed repositories, with
+// the owning teams computed during aggregation
+func (a *Analyzer) prRowsOf(results []RepoResult, owningTeams map[string]map[int][]string) []exporter.PRRow {
+	var rows []exporter.PRRow
+	for _, result := range results {
+		if result.Err != nil || result.Repo == nil {
+			continue
+		}
+		repoName := fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())
+		for _, pr := range result.PRs {
+			rows = append(rows, a.prRow(repoName, pr, owningTeams[repoName][pr.GetNumber()]))
+		}
+	}
+	return rows
+}
+
+// countNested increments counts[outer][inner], creating the inner map as needed
+func countNested(counts map[string]map[string]int, outer, inner string) {
+	if counts[outer] == nil {
+		counts[outer] = make(map[string]int)
+	}
+	counts[outer][inner]++
+}
+
+// teamsDetail nests the repos and users of each team under its PR count,
+// teams with the most PRs first
+func teamsDetail(prsByTeam map[string]int, reposByTeam, usersByTeam map[string]map[string]int) []exporter.TeamDetail {
+	teams := make([]exporter.TeamDetail, 0, len(prsByTeam))
+	for team, prs := range prsByTeam {
+		teams = append(teams, exporter.TeamDetail{
+			Team:  team,
+			PRs:   prs,
+			Repos: namedCounts(reposByTeam[team]),
+			Users: namedCounts(usersByTeam[team]),
+		})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].PRs != teams[j].PRs {
+			return teams[i].PRs > teams[j].PRs
+		}
+		return teams[i].Team < teams[j].Team
+	})
+	return teams
+}
+
+// namedCounts converts counts to a list, highest counts first
+func namedCounts(counts map[string]int) []exporter.NamedCount {
+	list := make([]exporter.NamedCount, 0, len(counts))
+	for name, prs := range counts {
+		list = append(list, exporter.NamedCount{Name: name, PRs: prs})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].PRs != list[j].PRs {
+			return list[i].PRs > list[j].PRs
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// countPRsByCompany attributes each author's PR count to the company on their profile
+func (a *Analyzer) countPRsByCompany(ctx context.Context, prsByUser map[string]int) map[string]int {
+	a.logger.Info("Fetching user profiles for company attribution", zap.Int("users", len(prsByUser)))
+
+	prsByCompany := make(map[string]int)
+	for login, count := range prsByUser {
+		// Deleted accounts have no profile to fetch
+		if login == a.cfg.Attribution.GhostAuthorBucket {
+			prsByCompany[normalizeCompany("")] += count
+			continue
+		}
+		user := a.getUserProfile(ctx, login)
+		prsByCompany[normalizeCompany(user.GetCompany())] += count
+	}
+
+	return prsByCompany
+}
+
+// getUserProfile returns a user's full profile (checking the cache first), or nil if unavailable
+func (a *Analyzer) getUserProfile(ctx context.Context, login string) *github.User {
+	if a.cache != nil {
+		cachedUser, err := a.cache.GetUser(ctx, login)
+		if err == nil {
+			return cachedUser
+		}
+	}
+
+	if a.skipAPICalls {
+		a.logger.Debug("Skipping user profile fetch (cache-only mode)", zap.String("user", login))
+		return nil
+	}
+
+	fetched, err := a.fetchOnce("user:"+login, func() (interface{}, error) {
+		user, err := a.userFetcher.FetchUser(ctx, login)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache user profile
+		if a.cache != nil {
+			if err := a.cache.SetUser(ctx, login, user); err != nil {
+				a.logger.Warn("Failed to cache user profile", zap.Error(err))
+			}
+		}
+		return user, nil
+	})
+	if err != nil {
+		a.logger.Debug("Failed to fetch user profile",
+			zap.String("user", login),
+			zap.Error(err),
+		)
+		a.events.record(severityWarning, "", 0, fmt.Sprintf("failed to fetch profile of user %s: %v", login, err))
+		return nil
+	}
+
+	return fetched.(*github.User)
+}
+
+// normalizeCompany normalizes the free-form company field of a user profile
+// (e.g. "@GitHub " and "github" are the same company)
+func normalizeCompany(company string) string {
+	company = strings.TrimSpace(company)
+	company = strings.TrimPrefix(company, "@")
+	company = strings.ToLower(strings.TrimSpace(company))
+	if company == "" {
+		return independentCompany
+	}
+	return company
+}
+
+// prSizeBucketLabels returns the histogram bucket labels for the given
+// inclusive upper bounds, e.g. [10 100] yields "0-10", "11-100" and "101+"
+func prSizeBucketLabels(bounds []int) []string {
+	labels := make([]string, 0, len(bounds)+1)
+	lower := 0
+	for _, bound := range bounds {
+		labels = append(labels, fmt.Sprintf("%d-%d", lower, bound))
+		lower = bound + 1
+	}
+	return append(labels, fmt.Sprintf("%d+", lower))
+}
+
+// prSizeBucket returns the label of the histogram bucket containing lines
+func prSizeBucket(lines int, bounds []int) string {
+	labels := prSizeBucketLabels(bounds)
+	for i, bound := range bounds {
+		if lines <= bound {
+			return labels[i]
+		}
+	}
+	return labels[len(labels)-1]
+}
+
+// hasPRDetails reports whether a PR was fetched individually rather than listed
+func hasPRDetails(pr *github.PullRequest) bool {
+	return pr.Commits != nil
+}
+
 // mapPROwners maps PR changed files to CODEOWNERS owners
-func (a *Analyzer) mapPROwners(ctx context.Context, pr *github.PullRequest, codeowners *fetcher.CODEOWNERSFile, owner, repo string) []string {
+func (a *Analyzer) mapPROwners(ctx context.Context, pr *github.PullRequest, codeowners *fetcher.CODEOWNERSFile, owner, repo string, files map[int][]*github.CommitFile) []string {
 	if codeowners == nil {
 		return nil
 	}
 
+	// Use the files fetched during repository processing, if any
+	prFiles, ok := files[pr.GetNumber()]
+	if !ok {
+		prFiles = a.getPRFiles(ctx, owner, repo, pr.GetNumber())
+	}
+	if prFiles == nil {
+		return nil
+	}
+
+	return ownersForFiles(codeowners, a.withoutExcludedPaths(owner+"/"+repo, prFiles))
+}
+
+// getPRFiles returns a PR's changed files from the cache or the API, or nil if unavailable
+func (a *Analyzer) getPRFiles(ctx context.Context, owner, repo string, prNumber int) []*github.CommitFile {
 	// Fetch PR changed files (check cache first)
 	var prFiles []*github.CommitFile
 	if a.cache != nil {
-		cachedFiles, err := a.cache.GetPRFiles(ctx, owner, repo, pr.GetNumber())
+		cachedFiles, err := a.cache.GetPRFiles(ctx, owner, repo, prNumber)
 		if err == nil && len(cachedFiles) > 0 {
 			prFiles = cachedFiles
 		}
@@ -419,56 +2187,86 @@ func (a *Analyzer) mapPROwners(ctx context.Context, pr *github.PullRequest, code
 	// Fetch from API if not cached
 	if len(prFiles) == 0 {
 		if !a.skipAPICalls {
-			var err error
-			prFiles, err = a.prFetcher.FetchPRFiles(ctx, owner, repo, pr.GetNumber())
+			if a.ghClient != nil {
+				if err := a.ghClient.WaitForRateLimit(ctx); err != nil {
+					return nil
+				}
+			}
+
+			fetched, err := a.fetchOnce(fmt.Sprintf("files:%s/%s#%d", owner, repo, prNumber), func() (interface{}, error) {
+				fetchCtx, span := a.tracer.Start(ctx, "github.pr_files", "pr_number", prNumber)
+				prFiles, err := a.prFetcher.FetchPRFiles(fetchCtx, owner, repo, prNumber)
+				span.RecordError(err)
+				span.End()
+				if err != nil {
+					return nil, err
+				}
+
+				// Cache PR files
+				if a.cache != nil {
+					if err := a.cache.SetPRFiles(ctx, owner, repo, prNumber, prFiles); err != nil {
+						a.logger.Warn("Failed to cache PR files", zap.Error(err))
+					}
+				}
+				return prFiles, nil
+			})
 			if err != nil {
 				a.logger.Debug("Failed to fetch PR files",
-					zap.Int("pr_number", pr.GetNumber()),
+					zap.Int("pr_number", prNumber),
 					zap.Error(err),
 				)
+				a.events.record(severityWarning, owner+"/"+repo, prNumber, fmt.Sprintf("failed to fetch PR files: %v", err))
 				return nil
 			}
-
-			// Cache PR files
-			if a.cache != nil {
-				if err := a.cache.SetPRFiles(ctx, owner, repo, pr.GetNumber(), prFiles); err != nil {
-					a.logger.Warn("Failed to cache PR files", zap.Error(err))
-				}
-			}
+			prFiles = fetched.([]*github.CommitFile)
 		} else {
 			a.logger.Debug("Skipping PR files fetch (cache-only mode)",
-				zap.Int("pr_number", pr.GetNumber()),
+				zap.Int("pr_number", prNumber),
 			)
 			return nil
 		}
 	}
 
-	// Collect all owners from all changed files
-	allOwners := make(map[string]bool)
-	for _, file := range prFiles {
-		filePath := file.GetFilename()
-		owners := codeowners.FindOwners(filePath)
-		for _, owner := range owners {
-			allOwners[owner] = true
-		}
+	return prFiles
+}
+
+// fetchOnce runs fetch for key, unless a fetch for the same key is already in
+// flight, in which case it waits for and returns that fetch's result instead.
+// Repos run concurrently, so this keeps duplicate work (e.g. a repo listed
+// twice) from issuing duplicate API calls and racing on cache writes.
+func (a *Analyzer) fetchOnce(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	v, err, shared := a.inflight.Do(key, fetch)
+	if shared {
+		a.logger.Debug("Shared in-flight fetch", zap.String("key", key))
 	}
+	return v, err
+}
 
-	// Convert to slice
+// ownersForFiles collects the CODEOWNERS owners of all changed files
+func ownersForFiles(codeowners *fetcher.CODEOWNERSFile, prFiles []*github.CommitFile) []string {
+	// Keep owners in the order they are first seen, so the first owner of
+	// first-owner-only is the first owner of the first owned file
+	seen := make(map[string]bool)
 	var owners []string
-	for owner := range allOwners {
-		owners = append(owners, owner)
+	for _, file := range prFiles {
+		for _, owner := range codeowners.FindOwners(file.GetFilename()) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
 	}
 
 	return owners
 }
 
 // applyAttributionMode applies the attribution mode to owners
-func (a *Analyzer) applyAttributionMode(owners []string) []string {
+func (a *Analyzer) applyAttributionMode(repo string, owners []string) []string {
 	if len(owners) == 0 {
 		return owners
 	}
 
-	mode := a.cfg.Attribution.Mode
+	mode := a.repoAttributionMode(repo)
 	switch mode {
 	case "first-owner-only":
 		// Return only the first owner
@@ -486,20 +2284,14 @@ func (a *Analyzer) applyAttributionMode(owners []string) []string {
 	}
 }
 
-// normalizeOwner normalizes owner name (handles @ prefix, team format)
-func normalizeOwner(owner string) string {
-	// Remove @ prefix if present
-	return strings.TrimPrefix(owner, "@")
-}
-
 // getRollupTeams returns the rollup team names for a given team
 func (a *Analyzer) getRollupTeams(team string) []string {
 	var rollupTeams []string
-	normalizedTeam := normalizeOwner(team)
+	owner := classifyOwner(team)
 
 	for _, rollup := range a.cfg.TeamRollup {
 		for _, rollupTeam := range rollup.Teams {
-			if normalizeOwner(rollupTeam) == normalizedTeam {
+			if classifyOwner(rollupTeam).matches(owner) {
 				rollupTeams = append(rollupTeams, rollup.Name)
 				break // Team can be in multiple rollups, but we only add each rollup name once
 			}
@@ -509,33 +2301,44 @@ func (a *Analyzer) getRollupTeams(team string) []string {
 	return rollupTeams
 }
 
-// isTeamInRollup checks if a team is part of any rollup configuration
-func (a *Analyzer) isTeamInRollup(team string) bool {
-	normalizedTeam := normalizeOwner(team)
-
-	for _, rollup := range a.cfg.TeamRollup {
-		for _, rollupTeam := range rollup.Teams {
-			if normalizeOwner(rollupTeam) == normalizedTeam {
-				return true
-			}
+// teamsForOwners resolves attributed owners to the team buckets a PR is counted under,
+// each bucket once, in name order. Teams in a rollup are counted under each rollup name
+// (and, with attribution.count_rollup_members, their own name too), other teams under
+// their own normalized name. PRs without owners are counted under the bucket of the
+// reason: the repository has no CODEOWNERS file, or none of the PR's paths matched a rule.
+func (a *Analyzer) teamsForOwners(owners []string, hasCodeowners bool) []string {
+	if len(owners) == 0 {
+		if !hasCodeowners {
+			return []string{a.cfg.Attribution.NoCodeownersFileBucket}
 		}
+		return []string{a.cfg.Attribution.UnmatchedPathsBucket}
 	}
 
-	return false
-}
+	// Rollup and individual names share one set, so a PR is counted once even
+	// when a rollup is named after a team outside it (e.g. a parent team)
+	buckets := make(map[string]bool)
+	for _, owner := range owners {
+		rollupTeams := a.getRollupTeams(owner)
+		for _, rollupTeam := range rollupTeams {
+			buckets[rollupTeam] = true
+		}
+		if len(rollupTeams) == 0 || a.cfg.Attribution.CountRollupMembers {
+			buckets[normalizeOwner(owner)] = true
+		}
+	}
 
-func (a *Analyzer) aggregateResults(ctx context.Context, results []RepoResult, since, until time.Time) *exporter.AnalysisResult {
-	aggregated := &exporter.AnalysisResult{
-		PRsByRepo: make(map[string]int),
-		PRsByTeam: make(map[string]int),
-		PRsByUser: make(map[string]int),
-		TimeWindow: exporter.TimeWindow{
-			Since: since,
-			Until: until,
-		},
-		GeneratedAt: time.Now(),
+	teams := make([]string, 0, len(buckets))
+	for team := range buckets {
+		teams = append(teams, team)
 	}
+	sort.Strings(teams)
 
+	return teams
+}
+
+// aggregateResults aggregates the processed repositories. With a non-nil prRows,
+// each PR is also streamed to the detailed CSV as soon as its teams are known.
+func (a *Analyzer) aggregateResults(ctx context.Context, results []RepoResult, since, until time.Time, prRows *exporter.PRCSVWriter) *exporter.AnalysisResult {
 	totalPRs := 0
 	for _, result := range results {
 		if result.PRs != nil {
@@ -548,96 +2351,9 @@ func (a *Analyzer) aggregateResults(ctx context.Context, results []RepoResult, s
 		zap.Int("total_prs_to_process", totalPRs),
 	)
 
-	processedCount := 0
+	g := a.newAggregator(since, until, len(results), prRows)
 	for _, result := range results {
-		if result.Err != nil {
-			a.logger.Warn("Repository processing error",
-				zap.String("repo", fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())),
-				zap.Error(result.Err),
-			)
-			continue
-		}
-
-		repoName := fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())
-		prCount := len(result.PRs)
-		aggregated.PRsByRepo[repoName] = prCount
-		aggregated.TotalPRsClosed += prCount
-
-		// Count by user (author)
-		for _, pr := range result.PRs {
-			if pr.User != nil {
-				user := pr.User.GetLogin()
-				aggregated.PRsByUser[user]++
-			}
-		}
-
-		// Count by team (CODEOWNERS)
-		owner := result.Repo.GetOwner().GetLogin()
-		name := result.Repo.GetName()
-		hasCodeowners := result.CODEOWNERS != nil
-
-		if hasCodeowners && len(result.PRs) > 0 {
-			a.logger.Debug("Mapping PRs to CODEOWNERS owners",
-				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
-				zap.Int("pr_count", len(result.PRs)),
-			)
-		}
-
-		for _, pr := range result.PRs {
-			var owners []string
-			if hasCodeowners {
-				// Map PR files to owners
-				prOwners := a.mapPROwners(ctx, pr, result.CODEOWNERS, owner, name)
-				// Apply attribution mode
-				owners = a.applyAttributionMode(prOwners)
-			}
-
-			if len(owners) == 0 {
-				// No owners found, use "no_codeowners"
-				aggregated.PRsByTeam["no_codeowners"]++
-			} else {
-				// Track which rollup teams this PR should be counted under (to avoid double-counting)
-				rollupTeamsSet := make(map[string]bool)
-				nonRollupTeams := make(map[string]bool)
-
-				// Process each owner
-				for _, owner := range owners {
-					normalized := normalizeOwner(owner)
-
-					// Check if this team is part of a rollup
-					if a.isTeamInRollup(owner) {
-						// Team is in a rollup, add to rollup teams set
-						rollupTeams := a.getRollupTeams(owner)
-						for _, rollupTeam := range rollupTeams {
-							rollupTeamsSet[rollupTeam] = true
-						}
-					} else {
-						// Team is not in a rollup, count under individual team name
-						nonRollupTeams[normalized] = true
-					}
-				}
-
-				// Count each rollup team once per PR
-				for rollupTeam := range rollupTeamsSet {
-					aggregated.PRsByTeam[rollupTeam]++
-				}
-
-				// Count each non-rollup team once per PR
-				for team := range nonRollupTeams {
-					aggregated.PRsByTeam[team]++
-				}
-			}
-		}
-
-		processedCount++
-		if processedCount%10 == 0 || processedCount == len(results) {
-			a.logger.Debug("Aggregation progress",
-				zap.Int("processed", processedCount),
-				zap.Int("total", len(results)),
-				zap.Int("prs_processed_so_far", aggregated.TotalPRsClosed),
-			)
-		}
+		g.add(ctx, result)
 	}
-
-	return aggregated
+	return g.finish(ctx)
 }
@@ -0,0 +1,1310 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/cache"
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+	"github.com/fishnix/ghpr-analyzer/internal/ghclient"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+func TestClosingIssueRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "closes", body: "Closes #123", want: []string{"my-org/repo#123"}},
+		{name: "fixes", body: "This fixes #45.", want: []string{"my-org/repo#45"}},
+		{name: "resolved with colon", body: "Resolved: #7", want: []string{"my-org/repo#7"}},
+		{name: "all keyword forms", body: "close #1 closed #2 fix #3 fixed #4 resolve #5 resolves #6", want: []string{
+			"my-org/repo#1", "my-org/repo#2", "my-org/repo#3", "my-org/repo#4", "my-org/repo#5", "my-org/repo#6",
+		}},
+		{name: "case insensitive", body: "FIXES #9", want: []string{"my-org/repo#9"}},
+		{name: "cross-repo", body: "fixes other-org/other.repo#45", want: []string{"other-org/other.repo#45"}},
+		{name: "issue URL", body: "Closes https://github.com/other-org/api/issues/12", want: []string{"other-org/api#12"}},
+		{name: "duplicates", body: "Fixes #3\n\nAlso fixes my-org/repo#3", want: []string{"my-org/repo#3"}},
+		{name: "mention without keyword", body: "Related to #123, see #124", want: nil},
+		{name: "keyword inside word", body: "prefixes #12", want: nil},
+		{name: "empty body", body: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := closingIssueRefs(tt.body, "my-org/repo")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("closingIssueRefs(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPRFilesCollapsesConcurrentFetches(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprint(w, `[{"filename": "main.go"}]`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	a := &Analyzer{
+		cfg:       &config.Config{},
+		prFetcher: fetcher.NewPRFetcher(client, nil, logger),
+		logger:    logger,
+	}
+
+	const callers = 5
+	var started, done sync.WaitGroup
+	results := make([][]*github.CommitFile, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i] = a.getPRFiles(context.Background(), "my-org", "repo", 1)
+		}(i)
+	}
+
+	// Give every caller time to join the in-flight fetch before it completes
+	started.Wait()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 API request, got %d", got)
+	}
+	for i, files := range results {
+		if len(files) != 1 || files[0].GetFilename() != "main.go" {
+			t.Errorf("caller %d: unexpected files %v", i, files)
+		}
+	}
+}
+
+func TestGetPRFilesIsServedFromCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `[{"filename": "main.go"}]`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	a := &Analyzer{
+		cfg:       &config.Config{},
+		cache:     cache.NewMemoryCache(time.Hour, false, false, logger),
+		prFetcher: fetcher.NewPRFetcher(client, nil, logger),
+		logger:    logger,
+	}
+
+	for i := 0; i < 2; i++ {
+		files := a.getPRFiles(context.Background(), "my-org", "repo", 1)
+		if len(files) != 1 || files[0].GetFilename() != "main.go" {
+			t.Fatalf("call %d: unexpected files %v", i, files)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 API request, got %d", got)
+	}
+
+	// Files of other PRs are not served from the cache
+	a.getPRFiles(context.Background(), "my-org", "repo", 2)
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected 2 API requests, got %d", got)
+	}
+}
+
+func TestLoadCODEOWNERSDoesNotCacheOversizedFileAsAbsent(t *testing.T) {
+	content := "* @myorg/platform\n" + strings.Repeat("# padding\n", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "size": %d, "content": %q}`,
+			len(content), base64.StdEncoding.EncodeToString([]byte(content)))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	codeownersFetcher := fetcher.NewCODEOWNERSFetcher(client, nil, logger)
+	codeownersFetcher.SetMaxBytes(64)
+	a := &Analyzer{
+		cfg:               &config.Config{},
+		cache:             cache.NewMemoryCache(time.Hour, false, false, logger),
+		codeownersFetcher: codeownersFetcher,
+		logger:            logger,
+	}
+
+	if codeowners := a.loadCODEOWNERS(context.Background(), "myorg", "api"); codeowners != nil {
+		t.Fatalf("loadCODEOWNERS() = %+v, want none for an oversized file", codeowners)
+	}
+	if cached, err := a.cache.GetCODEOWNERS(context.Background(), "myorg", "api"); err == nil {
+		t.Fatalf("expected nothing cached for an oversized file, got %q", cached)
+	}
+
+	// A raised limit takes effect without waiting for the cache to expire
+	codeownersFetcher.SetMaxBytes(4096)
+	if codeowners := a.loadCODEOWNERS(context.Background(), "myorg", "api"); codeowners == nil || len(codeowners.Rules) != 1 {
+		t.Errorf("loadCODEOWNERS() under a raised limit = %+v, want 1 rule", codeowners)
+	}
+}
+
+func TestProcessRepoResumesWithLaterUntil(t *testing.T) {
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	interruptedUntil := since.Add(10 * 24 * time.Hour)
+	until := interruptedUntil.Add(24 * time.Hour)
+	closedAt := func(hours int) string {
+		return since.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)
+	}
+
+	// Listed by update, newest first: #3 closed after the interrupted fetch's
+	// until, #2 on the page it had fetched, #1 on the page it had not
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		switch page {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, "http://"+r.Host, r.URL.Path))
+			fmt.Fprintf(w, `[{"number": 3, "closed_at": %[1]q, "updated_at": %[1]q}, {"number": 2, "closed_at": %[2]q, "updated_at": %[2]q}]`, closedAt(250), closedAt(100))
+		case "2":
+			fmt.Fprintf(w, `[{"number": 1, "closed_at": %[1]q, "updated_at": %[1]q}]`, closedAt(10))
+		default:
+			t.Errorf("unexpected page %q", page)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	ghClient, err := ghclient.NewClient("token", 100, 100, 100, 100, 1, 0, 0, 0, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{
+		cfg:       &config.Config{},
+		ghClient:  ghClient,
+		cache:     cache.NewMemoryCache(time.Hour, false, false, logger),
+		prFetcher: fetcher.NewPRFetcher(client, nil, logger),
+		logger:    logger,
+	}
+
+	// A fetch up to the earlier until was interrupted after its first page
+	ctx := context.Background()
+	pr2 := &github.PullRequest{Number: github.Int(2), ClosedAt: &github.Timestamp{Time: since.Add(100 * time.Hour)}}
+	if err := a.cache.SetPRs(ctx, "myorg", "api", []*github.PullRequest{pr2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.cache.SetFetchProgress(ctx, "myorg", "api", &cache.FetchProgress{Since: since, Until: interruptedUntil, LastPage: 1, StartedAt: interruptedUntil}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}}
+	result := a.processRepo(ctx, repo, since, until, 1)
+	if result.Err != nil {
+		t.Fatalf("processRepo() error = %v", result.Err)
+	}
+
+	var numbers []int
+	for _, pr := range result.PRs {
+		numbers = append(numbers, pr.GetNumber())
+	}
+	sort.Ints(numbers)
+	if !reflect.DeepEqual(numbers, []int{1, 2, 3}) {
+		t.Errorf("PRs = %v, want 1, 2 and 3", numbers)
+	}
+	// The new PRs at the head of the list, then the pages not fetched yet
+	if !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Errorf("pages fetched = %v, want [1 2]", pages)
+	}
+	if _, err := a.cache.GetFetchProgress(ctx, "myorg", "api"); err == nil {
+		t.Error("expected the fetch progress to be cleared once complete")
+	}
+}
+
+func TestProcessRepoResumeRefetchesPRsUpdatedSinceInterruption(t *testing.T) {
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(30 * 24 * time.Hour)
+	startedAt := since.Add(20 * 24 * time.Hour)
+	at := func(hours int) string {
+		return since.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)
+	}
+
+	// The interrupted fetch listed #3 and #2 on page 1; #1, on page 2 then,
+	// was updated since and moved ahead of them, pushing #2 to page 2
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		switch page {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, "http://"+r.Host, r.URL.Path))
+			fmt.Fprintf(w, `[{"number": 1, "closed_at": %q, "updated_at": %q}, {"number": 3, "closed_at": %[3]q, "updated_at": %[3]q}]`, at(10), at(20*24+1), at(100))
+		case "2":
+			fmt.Fprintf(w, `[{"number": 2, "closed_at": %[1]q, "updated_at": %[1]q}]`, at(50))
+		default:
+			t.Errorf("unexpected page %q", page)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	ghClient, err := ghclient.NewClient("token", 100, 100, 100, 100, 1, 0, 0, 0, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{
+		cfg:       &config.Config{},
+		ghClient:  ghClient,
+		cache:     cache.NewMemoryCache(time.Hour, false, false, logger),
+		prFetcher: fetcher.NewPRFetcher(client, nil, logger),
+		logger:    logger,
+	}
+
+	ctx := context.Background()
+	cached := []*github.PullRequest{
+		{Number: github.Int(3), ClosedAt: &github.Timestamp{Time: since.Add(100 * time.Hour)}},
+		{Number: github.Int(2), ClosedAt: &github.Timestamp{Time: since.Add(50 * time.Hour)}},
+	}
+	if err := a.cache.SetPRs(ctx, "myorg", "api", cached); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.cache.SetFetchProgress(ctx, "myorg", "api", &cache.FetchProgress{Since: since, Until: until, LastPage: 1, StartedAt: startedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}}
+	result := a.processRepo(ctx, repo, since, until, 1)
+	if result.Err != nil {
+		t.Fatalf("processRepo() error = %v", result.Err)
+	}
+
+	var numbers []int
+	for _, pr := range result.PRs {
+		numbers = append(numbers, pr.GetNumber())
+	}
+	sort.Ints(numbers)
+	if !reflect.DeepEqual(numbers, []int{1, 2, 3}) {
+		t.Errorf("PRs = %v, want 1, 2 and 3", numbers)
+	}
+	// The head of the list down to the PRs updated before the interrupted
+	// fetch started, then the pages not fetched yet
+	if !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Errorf("pages fetched = %v, want [1 2]", pages)
+	}
+}
+
+func TestProcessRepoAllStateUsesCacheForClosedPRs(t *testing.T) {
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(30 * 24 * time.Hour)
+
+	var states []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		states = append(states, r.URL.Query().Get("state"))
+		fmt.Fprintf(w, `[{"number": 2, "state": "open", "user": {"login": "bob"}, "updated_at": %q}]`, since.Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	logger := zap.NewNop()
+	ghClient, err := ghclient.NewClient("token", 100, 100, 100, 100, 1, 0, 0, 0, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{
+		cfg:       &config.Config{Fetch: config.FetchConfig{PRState: "all"}},
+		ghClient:  ghClient,
+		cache:     cache.NewMemoryCache(time.Hour, false, false, logger),
+		prFetcher: fetcher.NewPRFetcher(client, nil, logger),
+		logger:    logger,
+	}
+	a.prFetcher.SetPRState("all")
+
+	ctx := context.Background()
+	closed := &github.PullRequest{Number: github.Int(1), User: &github.User{Login: github.String("alice")}, ClosedAt: &github.Timestamp{Time: since.Add(time.Hour)}}
+	if err := a.cache.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closed}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}}
+	result := a.processRepo(ctx, repo, since, until, 1)
+	if result.Err != nil {
+		t.Fatalf("processRepo() error = %v", result.Err)
+	}
+
+	// Closed PRs come from the cache; only the open ones are listed
+	if !reflect.DeepEqual(states, []string{"open"}) {
+		t.Errorf("list states requested = %v, want only open", states)
+	}
+	got := a.aggregateResults(ctx, []RepoResult{result}, since, until, nil)
+	if got.TotalPRsClosed != 1 || got.OpenPRs != 1 {
+		t.Errorf("TotalPRsClosed = %d, OpenPRs = %d, want 1 and 1", got.TotalPRsClosed, got.OpenPRs)
+	}
+}
+
+func TestCoAuthors(t *testing.T) {
+	commit := func(message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{Message: github.String(message)}}
+	}
+
+	commits := []*github.RepositoryCommit{
+		commit("Add feature\n\nCo-authored-by: Alice <12345+Alice@users.noreply.github.com>\nCo-authored-by: Bob Smith <bob@example.com>"),
+		commit("Fix tests\n\nco-authored-by: alice <alice@users.noreply.github.com>"),
+		commit("Mention Co-authored-by: Carol <carol@example.com> inline only"),
+		commit("No trailers"),
+	}
+
+	want := []string{"alice", "bob@example.com"}
+	if got := coAuthors(commits); !reflect.DeepEqual(got, want) {
+		t.Errorf("coAuthors() = %v, want %v", got, want)
+	}
+}
+
+func TestPRScore(t *testing.T) {
+	scoring := config.ScoringConfig{
+		Default: 1,
+		Rules: []config.ScoringRuleConfig{
+			{Label: "epic", Score: 3},
+			{TitlePrefix: "typo", Score: 0.5},
+			{MaxLines: 10, Score: 0.25},
+			{Label: "security", MinLines: 100, Score: 2},
+		},
+	}
+	details := func(pr *github.PullRequest, lines int) *github.PullRequest {
+		pr.Commits = github.Int(1)
+		pr.Additions = github.Int(lines)
+		pr.Deletions = github.Int(0)
+		return pr
+	}
+	labeled := func(names ...string) []*github.Label {
+		var labels []*github.Label
+		for _, name := range names {
+			labels = append(labels, &github.Label{Name: github.String(name)})
+		}
+		return labels
+	}
+
+	tests := []struct {
+		name string
+		pr   *github.PullRequest
+		want float64
+	}{
+		{name: "no rule matches", pr: &github.PullRequest{Title: github.String("Add feature")}, want: 1},
+		{name: "label", pr: &github.PullRequest{Title: github.String("Add feature"), Labels: labeled("Epic")}, want: 3},
+		{name: "title prefix", pr: &github.PullRequest{Title: github.String("Typo in README")}, want: 0.5},
+		{name: "matching rules sum", pr: details(&github.PullRequest{Title: github.String("typo fix")}, 2), want: 0.75},
+		{name: "size unknown without details", pr: &github.PullRequest{Title: github.String("Small change")}, want: 1},
+		{name: "all conditions must hold", pr: details(&github.PullRequest{Title: github.String("Patch"), Labels: labeled("security")}, 50), want: 1},
+		{name: "label and size", pr: details(&github.PullRequest{Title: github.String("Patch"), Labels: labeled("security")}, 150), want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prScore(tt.pr, scoring); got != tt.want {
+				t.Errorf("prScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyOwner(t *testing.T) {
+	tests := []struct {
+		owner string
+		want  codeOwner
+	}{
+		{owner: "@alice", want: codeOwner{Kind: ownerUser, Name: "alice"}},
+		{owner: "@MyOrg/Payments", want: codeOwner{Kind: ownerTeam, Name: "myorg/payments"}},
+		{owner: "myorg/payments", want: codeOwner{Kind: ownerTeam, Name: "myorg/payments"}},
+		{owner: "@Enterprise/MyOrg/Payments", want: codeOwner{Kind: ownerTeam, Name: "enterprise/myorg/payments"}},
+		{owner: "Dev@Example.com", want: codeOwner{Kind: ownerEmail, Name: "dev@example.com"}},
+		{owner: "payments", want: codeOwner{Kind: ownerUser, Name: "payments"}},
+	}
+	for _, tt := range tests {
+		if got := classifyOwner(tt.owner); got != tt.want {
+			t.Errorf("classifyOwner(%q) = %+v, want %+v", tt.owner, got, tt.want)
+		}
+	}
+}
+
+func TestTeamsForOwnersMatchesRollupEntries(t *testing.T) {
+	a := &Analyzer{cfg: &config.Config{
+		Attribution: config.AttributionConfig{NoCodeownersFileBucket: "no_codeowners_file", UnmatchedPathsBucket: "unmatched_paths"},
+		TeamRollup: []config.TeamRollupConfig{
+			{Name: "money", Teams: []string{"payments", "@myorg/Billing"}},
+		},
+	}}
+
+	tests := []struct {
+		owners []string
+		want   []string
+	}{
+		{owners: []string{"@myorg/payments"}, want: []string{"money"}},
+		{owners: []string{"@otherorg/payments"}, want: []string{"money"}},
+		{owners: []string{"@MyOrg/billing"}, want: []string{"money"}},
+		{owners: []string{"@otherorg/billing"}, want: []string{"otherorg/billing"}},
+		{owners: []string{"@myorg/payments", "@myorg/billing"}, want: []string{"money"}},
+		{owners: []string{"dev@example.com"}, want: []string{"dev@example.com"}},
+	}
+	for _, tt := range tests {
+		if got := a.teamsForOwners(tt.owners, true); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("teamsForOwners(%v) = %v, want %v", tt.owners, got, tt.want)
+		}
+	}
+}
+
+func TestTeamsForOwnersMatchesMultiPartSlugs(t *testing.T) {
+	a := &Analyzer{cfg: &config.Config{
+		TeamRollup: []config.TeamRollupConfig{
+			{Name: "money", Teams: []string{"@myorg/payments", "billing"}},
+			{Name: "edge", Teams: []string{"@enterprise/myorg/cdn"}},
+		},
+	}}
+
+	tests := []struct {
+		owners []string
+		want   []string
+	}{
+		// Two-part references
+		{owners: []string{"@myorg/payments"}, want: []string{"money"}},
+		{owners: []string{"@myorg/billing"}, want: []string{"money"}},
+		// Three-part references match two-part and unqualified rollup entries
+		{owners: []string{"@enterprise/myorg/payments"}, want: []string{"money"}},
+		{owners: []string{"@Enterprise/MyOrg/Billing"}, want: []string{"money"}},
+		{owners: []string{"@enterprise/otherorg/payments"}, want: []string{"enterprise/otherorg/payments"}},
+		// A three-part rollup entry only matches that exact team
+		{owners: []string{"@enterprise/myorg/cdn"}, want: []string{"edge"}},
+		{owners: []string{"@myorg/cdn"}, want: []string{"myorg/cdn"}},
+		// Segments match whole, not as a substring
+		{owners: []string{"@enterprise/notmyorg/payments"}, want: []string{"enterprise/notmyorg/payments"}},
+	}
+	for _, tt := range tests {
+		if got := a.teamsForOwners(tt.owners, true); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("teamsForOwners(%v) = %v, want %v", tt.owners, got, tt.want)
+		}
+	}
+}
+
+func TestTeamsForOwnersCountsEachBucketOnce(t *testing.T) {
+	rollups := []config.TeamRollupConfig{
+		{Name: "myorg/platform", Teams: []string{"@myorg/platform-api", "@myorg/platform-web"}},
+		{Name: "infra", Teams: []string{"@myorg/platform-api"}},
+	}
+
+	tests := []struct {
+		name         string
+		countMembers bool
+		owners       []string
+		want         []string
+	}{
+		{
+			name:   "rollup members are not counted under their own name",
+			owners: []string{"@myorg/platform-api", "@myorg/platform-web"},
+			want:   []string{"infra", "myorg/platform"},
+		},
+		{
+			name:   "rollup named after a team outside it",
+			owners: []string{"@myorg/platform", "@myorg/platform-web"},
+			want:   []string{"myorg/platform"},
+		},
+		{
+			name:         "members counted when configured",
+			countMembers: true,
+			owners:       []string{"@myorg/platform-api", "@myorg/platform"},
+			want:         []string{"infra", "myorg/platform", "myorg/platform-api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analyzer{cfg: &config.Config{
+				Attribution: config.AttributionConfig{CountRollupMembers: tt.countMembers},
+				TeamRollup:  rollups,
+			}}
+			if got := a.teamsForOwners(tt.owners, true); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("teamsForOwners(%v) = %v, want %v", tt.owners, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTeamsForOwnersSplitsUnownedByReason(t *testing.T) {
+	a := &Analyzer{cfg: &config.Config{
+		Attribution: config.AttributionConfig{NoCodeownersFileBucket: "missing", UnmatchedPathsBucket: "gaps"},
+	}}
+
+	if got := a.teamsForOwners(nil, false); !reflect.DeepEqual(got, []string{"missing"}) {
+		t.Errorf("teamsForOwners without CODEOWNERS = %v, want [missing]", got)
+	}
+	if got := a.teamsForOwners(nil, true); !reflect.DeepEqual(got, []string{"gaps"}) {
+		t.Errorf("teamsForOwners with unmatched paths = %v, want [gaps]", got)
+	}
+}
+
+func TestReleaseLeadTime(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	release := func(d int, draft bool) *github.RepositoryRelease {
+		return &github.RepositoryRelease{PublishedAt: &github.Timestamp{Time: day(d)}, Draft: github.Bool(draft)}
+	}
+	releaseTimes := publishedReleaseTimes([]*github.RepositoryRelease{
+		release(20, false),
+		release(5, false),
+		release(10, true),          // Drafts don't ship anything
+		{Draft: github.Bool(true)}, // Unpublished
+	})
+
+	tests := []struct {
+		mergedAt time.Time
+		want     time.Duration
+		wantOK   bool
+	}{
+		{day(1), 4 * 24 * time.Hour, true},
+		{day(5), 15 * 24 * time.Hour, true}, // Merged at the moment of a release: shipped by the next one
+		{day(8), 12 * 24 * time.Hour, true},
+		{day(21), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := releaseLeadTime(tt.mergedAt, releaseTimes, nil)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("releaseLeadTime(%v) = %v, %v, want %v, %v", tt.mergedAt, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if times := publishedReleaseTimes(nil); times == nil {
+		t.Errorf("publishedReleaseTimes(nil) = nil, want empty (releases known, none published)")
+	}
+}
+
+func TestTeamsDetailSortsDescending(t *testing.T) {
+	prsByTeam := map[string]int{"org/a": 2, "org/b": 3}
+	reposByTeam := map[string]map[string]int{
+		"org/a": {"org/x": 2},
+		"org/b": {"org/x": 1, "org/y": 2},
+	}
+	usersByTeam := map[string]map[string]int{
+		"org/a": {"alice": 1, "bob": 1},
+		"org/b": {"bob": 3},
+	}
+
+	got := teamsDetail(prsByTeam, reposByTeam, usersByTeam)
+	want := []exporter.TeamDetail{
+		{
+			Team:  "org/b",
+			PRs:   3,
+			Repos: []exporter.NamedCount{{Name: "org/y", PRs: 2}, {Name: "org/x", PRs: 1}},
+			Users: []exporter.NamedCount{{Name: "bob", PRs: 3}},
+		},
+		{
+			Team:  "org/a",
+			PRs:   2,
+			Repos: []exporter.NamedCount{{Name: "org/x", PRs: 2}},
+			Users: []exporter.NamedCount{{Name: "alice", PRs: 1}, {Name: "bob", PRs: 1}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("teamsDetail() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBusinessUnit(t *testing.T) {
+	units := []config.BusinessUnitConfig{
+		{Name: "Payments", Repos: []string{"payments-*", "myorg/billing"}},
+		{Name: "Platform", Repos: []string{"*"}},
+	}
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"myorg/payments-api", "Payments"},
+		{"myorg/billing", "Payments"},
+		{"otherorg/billing", "Platform"},
+	}
+	for _, tt := range tests {
+		if got := businessUnit(tt.repo, units); got != tt.want {
+			t.Errorf("businessUnit(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+
+	if got := businessUnit("myorg/web", units[:1]); got != unassignedBusinessUnit {
+		t.Errorf("businessUnit of an unmatched repo = %q, want %q", got, unassignedBusinessUnit)
+	}
+}
+
+func TestPRCategory(t *testing.T) {
+	categories := []config.CategoryConfig{
+		{Name: "bug", Labels: []string{"bug", "type/defect*"}},
+		{Name: "feature", Labels: []string{"feature", "enhancement"}},
+		{Name: "chore", Labels: []string{"chore", "dependencies"}},
+	}
+	labeled := func(names ...string) *github.PullRequest {
+		pr := &github.PullRequest{}
+		for _, name := range names {
+			pr.Labels = append(pr.Labels, &github.Label{Name: github.String(name)})
+		}
+		return pr
+	}
+	tests := []struct {
+		name string
+		pr   *github.PullRequest
+		want string
+	}{
+		{"single label", labeled("enhancement"), "feature"},
+		{"case-insensitive", labeled("Bug"), "bug"},
+		{"glob", labeled("type/defect-critical"), "bug"},
+		{"multi-label takes the first category listed", labeled("dependencies", "feature", "bug"), "bug"},
+		{"multi-label with one match", labeled("needs-review", "chore"), "chore"},
+		{"no matching label", labeled("needs-review"), uncategorized},
+		{"no labels", labeled(), uncategorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prCategory(tt.pr, categories); got != tt.want {
+				t.Errorf("prCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContributorStats(t *testing.T) {
+	prsByUser := map[string]int{"a": 1, "b": 2, "c": 4, "d": 6, "e": 10, "ghost": 50}
+
+	stats := contributorStats(prsByUser, 1, "ghost")
+	want := &exporter.ContributorStats{Contributors: 5, MinPRs: 1, Mean: 4.6, Median: 4, P90: 10}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("contributorStats() = %+v, want %+v", stats, want)
+	}
+
+	// One-off contributors are left out
+	stats = contributorStats(prsByUser, 2, "ghost")
+	want = &exporter.ContributorStats{Contributors: 4, MinPRs: 2, Mean: 5.5, Median: 5, P90: 10}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("contributorStats() with min 2 = %+v, want %+v", stats, want)
+	}
+
+	if stats := contributorStats(prsByUser, 100, "ghost"); stats != nil {
+		t.Errorf("expected nil without qualifying contributors, got %+v", stats)
+	}
+}
+
+func TestCountAuthorAssociations(t *testing.T) {
+	prs := []*github.PullRequest{
+		{AuthorAssociation: github.String("MEMBER")},
+		{AuthorAssociation: github.String("MEMBER")},
+		{AuthorAssociation: github.String("CONTRIBUTOR")},
+		{AuthorAssociation: github.String("collaborator")},
+		{AuthorAssociation: github.String("NONE")},
+		{},
+	}
+	counts := map[string]int{"MEMBER": 1}
+	countAuthorAssociations(counts, prs)
+
+	want := map[string]int{"MEMBER": 3, "CONTRIBUTOR": 1, "COLLABORATOR": 1, "NONE": 1, unknownAuthorAssociation: 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("countAuthorAssociations() = %v, want %v", counts, want)
+	}
+}
+
+func TestReviewChurn(t *testing.T) {
+	requested := func(login string) *github.Timeline {
+		return &github.Timeline{Event: github.String("review_requested"), Reviewer: &github.User{Login: github.String(login)}}
+	}
+	requestedTeam := func(slug string) *github.Timeline {
+		return &github.Timeline{Event: github.String("review_requested"), RequestedTeam: &github.Team{Slug: github.String(slug)}}
+	}
+	reviewed := func(login string) *github.Timeline {
+		return &github.Timeline{Event: github.String("reviewed"), User: &github.User{Login: github.String(login)}}
+	}
+	event := func(name string) *github.Timeline { return &github.Timeline{Event: github.String(name)} }
+	tests := []struct {
+		name   string
+		events []*github.Timeline
+		want   int
+	}{
+		{"no events", nil, 0},
+		{"initial requests only", []*github.Timeline{requested("alice"), requested("bob"), reviewed("alice")}, 0},
+		{"re-request after review", []*github.Timeline{requested("alice"), reviewed("alice"), requested("alice"), reviewed("alice")}, 1},
+		{"new reviewer after a review", []*github.Timeline{requested("alice"), reviewed("alice"), requested("bob"), reviewed("bob")}, 0},
+		{"re-request of an unrequested reviewer", []*github.Timeline{reviewed("Alice"), requested("alice")}, 1},
+		{"re-request before any review", []*github.Timeline{requested("alice"), requested("alice")}, 1},
+		{"team re-request", []*github.Timeline{requestedTeam("platform"), reviewed("alice"), requestedTeam("platform"), requestedTeam("web")}, 1},
+		{"dismissals", []*github.Timeline{requested("alice"), reviewed("alice"), event("review_dismissed"), requested("alice"), event("review_dismissed")}, 3},
+		{"removed requests", []*github.Timeline{requested("alice"), event("review_request_removed")}, 0},
+	}
+	for _, tt := range tests {
+		if got := reviewChurn(tt.events); got != tt.want {
+			t.Errorf("%s: reviewChurn() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIdentityMapMergesAliasedLogins(t *testing.T) {
+	a := &Analyzer{
+		cfg: &config.Config{
+			Attribution: config.AttributionConfig{NoCodeownersFileBucket: "no_codeowners_file", GhostAuthorBucket: "ghost"},
+			IdentityMap: map[string]string{"alice-corp": "alice", "alice-bot": "alice"},
+		},
+		logger: zap.NewNop(),
+	}
+	pr := func(number int, login string) *github.PullRequest {
+		return &github.PullRequest{Number: github.Int(number), User: &github.User{Login: github.String(login)}}
+	}
+	results := []RepoResult{{
+		Repo: &github.Repository{Name: github.String("repo"), Owner: &github.User{Login: github.String("myorg")}},
+		PRs:  []*github.PullRequest{pr(1, "alice"), pr(2, "Alice-Corp"), pr(3, "alice-bot"), pr(4, "bob")},
+	}}
+
+	got := a.aggregateResults(context.Background(), results, time.Time{}, time.Now(), nil)
+	want := map[string]int{"alice": 3, "bob": 1}
+	if !reflect.DeepEqual(got.PRsByUser, want) {
+		t.Errorf("PRsByUser = %v, want %v", got.PRsByUser, want)
+	}
+}
+
+func TestRepoResultsRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/repo_results.json"
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	results := []RepoResult{
+		{
+			Repo:       &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}},
+			PRs:        []*github.PullRequest{{Number: github.Int(7)}},
+			CODEOWNERS: &fetcher.CODEOWNERSFile{Path: ".github/CODEOWNERS", Rules: []fetcher.CODEOWNERSRule{{Pattern: "*", Owners: []string{"@myorg/api"}, LineNum: 1}}},
+			Files:      map[int][]*github.CommitFile{7: {{Filename: github.String("main.go")}}},
+			PRTeams:    map[int][]string{7: {"myorg/api"}},
+			PRTeamsKey: "key",
+		},
+		{
+			Repo: &github.Repository{Name: github.String("web"), Owner: &github.User{Login: github.String("myorg")}},
+			Err:  fmt.Errorf("boom"),
+		},
+	}
+
+	if err := dumpRepoResults(path, results, since, until); err != nil {
+		t.Fatalf("dumpRepoResults() error = %v", err)
+	}
+	got, gotSince, gotUntil, err := loadRepoResults(path)
+	if err != nil {
+		t.Fatalf("loadRepoResults() error = %v", err)
+	}
+
+	if !gotSince.Equal(since) || !gotUntil.Equal(until) {
+		t.Errorf("time window = %v - %v, want %v - %v", gotSince, gotUntil, since, until)
+	}
+	if len(got) != 2 {
+		t.Fatalf("loaded %d results, want 2", len(got))
+	}
+	if !reflect.DeepEqual(got[0].CODEOWNERS, results[0].CODEOWNERS) || got[0].Files[7][0].GetFilename() != "main.go" || got[0].PRs[0].GetNumber() != 7 {
+		t.Errorf("loaded result = %+v, want it to match the dumped one", got[0])
+	}
+	if got[0].PRTeams != nil {
+		t.Errorf("loaded PRTeams = %v, want cached owning teams dropped", got[0].PRTeams)
+	}
+	if got[1].Err == nil || got[1].Err.Error() != "boom" {
+		t.Errorf("loaded Err = %v, want boom", got[1].Err)
+	}
+}
+
+func TestCodeownersCoverage(t *testing.T) {
+	codeowners := &fetcher.CODEOWNERSFile{Rules: []fetcher.CODEOWNERSRule{{Pattern: "/api/", Owners: []string{"@myorg/api"}, LineNum: 1}}}
+	file := func(name string) *github.CommitFile { return &github.CommitFile{Filename: github.String(name)} }
+	files := map[int][]*github.CommitFile{
+		1: {file("api/main.go"), file("README.md")},
+		2: {file("api/main.go"), file("api/handler.go"), file("web/index.html")},
+	}
+
+	// Distinct files: api/main.go and api/handler.go are owned, README.md and web/index.html are not
+	if got, ok := codeownersCoverage(codeowners, files); !ok || got != 0.5 {
+		t.Errorf("codeownersCoverage() = %v, %v, want 0.5, true", got, ok)
+	}
+	if _, ok := codeownersCoverage(codeowners, nil); ok {
+		t.Error("codeownersCoverage() without files reported a coverage")
+	}
+
+	coverage := map[string]float64{"myorg/a": 0.9, "myorg/b": 0.5, "myorg/c": 0, "myorg/d": 0.8}
+	if got, want := lowCoverageRepos(coverage, 0.8), []string{"myorg/c", "myorg/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lowCoverageRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestIgnoreCatchall(t *testing.T) {
+	parser := fetcher.NewCODEOWNERSFetcher(nil, nil, zap.NewNop())
+	codeowners, err := parser.ParseCODEOWNERS([]byte("* @myorg/default-team\n/api/ @myorg/api\n"), "CODEOWNERS")
+	if err != nil {
+		t.Fatalf("ParseCODEOWNERS() error = %v", err)
+	}
+	file := func(name string) *github.CommitFile { return &github.CommitFile{Filename: github.String(name)} }
+
+	tests := []struct {
+		name           string
+		ignoreCatchall bool
+		patterns       []string
+		files          []*github.CommitFile
+		want           []string
+	}{
+		{"catch-all owns unowned files", false, nil, []*github.CommitFile{file("README.md")}, []string{"myorg/default-team"}},
+		{"catch-all-only file is unowned", true, []string{"*", "**"}, []*github.CommitFile{file("README.md")}, []string{"unmatched_paths"}},
+		{"specific rule still applies", true, []string{"*", "**"}, []*github.CommitFile{file("api/main.go"), file("README.md")}, []string{"myorg/api"}},
+		{"leading slash is optional", true, []string{"/*"}, []*github.CommitFile{file("README.md")}, []string{"unmatched_paths"}},
+		{"only the configured patterns are catch-alls", true, []string{"**"}, []*github.CommitFile{file("README.md")}, []string{"myorg/default-team"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analyzer{cfg: &config.Config{Attribution: config.AttributionConfig{
+				UnmatchedPathsBucket: "unmatched_paths",
+				IgnoreCatchall:       tt.ignoreCatchall,
+				CatchallPatterns:     tt.patterns,
+			}}}
+			got := a.teamsForOwners(ownersForFiles(a.withoutCatchAllRules(codeowners), tt.files), true)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("teams = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The parsed file is left intact for other repositories sharing it
+	if len(codeowners.Rules) != 2 {
+		t.Errorf("expected the shared CODEOWNERS to keep its 2 rules, got %d", len(codeowners.Rules))
+	}
+}
+
+func TestPointInTimeAttribution(t *testing.T) {
+	parser := fetcher.NewCODEOWNERSFetcher(nil, nil, zap.NewNop())
+	a := &Analyzer{
+		cfg: &config.Config{Attribution: config.AttributionConfig{
+			Enabled:                true,
+			Mode:                   "multi",
+			NoCodeownersFileBucket: "no_codeowners_file",
+			UnmatchedPathsBucket:   "unmatched_paths",
+			PointInTime:            true,
+		}},
+		codeownersFetcher: parser,
+	}
+
+	current, err := a.codeownersVersion([]byte("/api/ @myorg/platform\n"))
+	if err != nil {
+		t.Fatalf("codeownersVersion() error = %v", err)
+	}
+	old, err := a.codeownersVersion([]byte("/api/ @myorg/api\n"))
+	if err != nil {
+		t.Fatalf("codeownersVersion() error = %v", err)
+	}
+	// Identical content at another commit shares the parse
+	if again, _ := a.codeownersVersion([]byte("/api/ @myorg/api\n")); again != old {
+		t.Error("expected identical CODEOWNERS content to be parsed once")
+	}
+
+	files := []*github.CommitFile{{Filename: github.String("api/main.go")}}
+	result := RepoResult{
+		Repo:         &github.Repository{Name: github.String("repo"), Owner: &github.User{Login: github.String("myorg")}},
+		CODEOWNERS:   current,
+		Files:        map[int][]*github.CommitFile{1: files, 2: files, 3: files},
+		PRCODEOWNERS: map[int]*fetcher.CODEOWNERSFile{1: old, 2: nil},
+	}
+
+	tests := []struct {
+		name   string
+		number int
+		want   []string
+	}{
+		{"owners at the merge commit", 1, []string{"myorg/api"}},
+		{"no CODEOWNERS at the merge commit", 2, []string{"no_codeowners_file"}},
+		{"version unknown falls back to the current file", 3, []string{"myorg/platform"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a.prTeams(context.Background(), &github.PullRequest{Number: github.Int(tt.number)}, result)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prTeams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoOverrides(t *testing.T) {
+	parser := fetcher.NewCODEOWNERSFetcher(nil, nil, zap.NewNop())
+	codeowners, err := parser.ParseCODEOWNERS([]byte("/api/ @myorg/api @myorg/platform\n/docs/ @myorg/docs\n"), "CODEOWNERS")
+	if err != nil {
+		t.Fatalf("ParseCODEOWNERS() error = %v", err)
+	}
+	excludeReverts := true
+	a := &Analyzer{cfg: &config.Config{
+		Attribution: config.AttributionConfig{Enabled: true, Mode: "multi", UnmatchedPathsBucket: "unmatched_paths"},
+		RepoOverrides: map[string]config.RepoOverrideConfig{
+			"myorg/special": {
+				AttributionMode: "first-owner-only",
+				ExcludePaths:    []string{"docs/**"},
+				Filters:         config.RepoFiltersOverride{ExcludeReverts: &excludeReverts},
+			},
+		},
+	}}
+
+	files := []*github.CommitFile{{Filename: github.String("api/main.go")}, {Filename: github.String("docs/index.md")}}
+	result := func(name string) RepoResult {
+		return RepoResult{
+			Repo:       &github.Repository{Name: github.String(name), Owner: &github.User{Login: github.String("myorg")}},
+			CODEOWNERS: codeowners,
+			Files:      map[int][]*github.CommitFile{1: files},
+		}
+	}
+	pr := &github.PullRequest{Number: github.Int(1)}
+
+	if got, want := a.prTeams(context.Background(), pr, result("special")), []string{"myorg/api"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("overridden repo: prTeams() = %v, want %v", got, want)
+	}
+	if got, want := a.prTeams(context.Background(), pr, result("other")), []string{"myorg/api", "myorg/docs", "myorg/platform"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("other repo: prTeams() = %v, want %v", got, want)
+	}
+
+	// Repository names match case-insensitively; other repos keep the global filters
+	if !a.repoFilters("MyOrg/Special").ExcludeReverts {
+		t.Error("expected the override to enable filters.exclude_reverts")
+	}
+	if a.repoFilters("myorg/other").ExcludeReverts {
+		t.Error("expected other repos to keep the global filters")
+	}
+}
+
+func TestAggregatorIsIndependentOfCompletionOrder(t *testing.T) {
+	a := &Analyzer{
+		cfg: &config.Config{
+			Attribution: config.AttributionConfig{Enabled: true, NoCodeownersFileBucket: "no_codeowners_file", GhostAuthorBucket: "ghost"},
+			Fetch:       config.FetchConfig{Reviews: true},
+		},
+		logger: zap.NewNop(),
+	}
+	merged := &github.Timestamp{Time: time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC)}
+	repoResult := func(name string, numbers ...int) RepoResult {
+		result := RepoResult{
+			Repo:    &github.Repository{Name: github.String(name), Owner: &github.User{Login: github.String("myorg")}},
+			Reviews: make(map[int][]*github.PullRequestReview),
+		}
+		for _, number := range numbers {
+			result.PRs = append(result.PRs, &github.PullRequest{Number: github.Int(number), User: &github.User{Login: github.String("alice")}, MergedAt: merged})
+			result.Reviews[number] = nil
+		}
+		return result
+	}
+	results := []RepoResult{repoResult("api", 1, 2), repoResult("web", 3)}
+
+	generated := time.Now()
+	want := a.aggregateResults(context.Background(), results, time.Time{}, generated, nil)
+
+	g := a.newAggregator(time.Time{}, generated, len(results), nil)
+	for i := len(results) - 1; i >= 0; i-- {
+		g.add(context.Background(), results[i])
+	}
+	got := g.finish(context.Background())
+
+	got.GeneratedAt, want.GeneratedAt = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregate in reverse completion order = %+v, want %+v", got, want)
+	}
+	if len(got.MergedWithoutApproval) != 3 || got.MergedWithoutApproval[0].Repo != "myorg/api" {
+		t.Errorf("MergedWithoutApproval = %+v, want sorted by repository and PR", got.MergedWithoutApproval)
+	}
+}
+
+func TestAddInOrderAggregatesInEnumerationOrder(t *testing.T) {
+	a := &Analyzer{
+		cfg: &config.Config{
+			Attribution: config.AttributionConfig{Enabled: true, NoCodeownersFileBucket: "no_codeowners_file", GhostAuthorBucket: "ghost"},
+		},
+		logger: zap.NewNop(),
+	}
+	created := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	repoResult := func(name string, hours ...float64) RepoResult {
+		result := RepoResult{Repo: &github.Repository{Name: github.String(name), Owner: &github.User{Login: github.String("myorg")}}}
+		for i, h := range hours {
+			closed := created.Add(time.Duration(h * float64(time.Hour)))
+			result.PRs = append(result.PRs, &github.PullRequest{
+				Number:    github.Int(i + 1),
+				User:      &github.User{Login: github.String("alice")},
+				CreatedAt: &github.Timestamp{Time: created},
+				ClosedAt:  &github.Timestamp{Time: closed},
+				MergedAt:  &github.Timestamp{Time: closed},
+			})
+		}
+		return result
+	}
+	results := []RepoResult{repoResult("api", 0.1, 7.3), repoResult("web", 1e-9), repoResult("cli", 1e6, 0.7)}
+	generated := time.Now()
+
+	// Aggregates the results completing in the given order, returning the
+	// aggregate and the detailed CSV
+	aggregate := func(order ...int) (*exporter.AnalysisResult, string) {
+		dir := t.TempDir()
+		prRows, err := exporter.NewPRCSVWriter(dir, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := a.newAggregator(time.Time{}, generated, len(results), prRows)
+		completed := make(chan completedRepo, len(results))
+		for _, idx := range order {
+			completed <- completedRepo{idx: idx, result: results[idx]}
+		}
+		close(completed)
+		g.addInOrder(context.Background(), completed)
+		got := g.finish(context.Background())
+		if err := prRows.Close(); err != nil {
+			t.Fatal(err)
+		}
+		csv, err := os.ReadFile(filepath.Join(dir, "prs.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.GeneratedAt = time.Time{}
+		return got, string(csv)
+	}
+
+	want, wantCSV := aggregate(0, 1, 2)
+	for _, order := range [][]int{{2, 1, 0}, {1, 2, 0}, {2, 0, 1}} {
+		got, gotCSV := aggregate(order...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("aggregate completing in order %v = %+v, want %+v", order, got, want)
+		}
+		if gotCSV != wantCSV {
+			t.Errorf("prs.csv completing in order %v =\n%s\nwant\n%s", order, gotCSV, wantCSV)
+		}
+	}
+	if !strings.Contains(wantCSV, "myorg/api") || strings.Index(wantCSV, "myorg/api") > strings.Index(wantCSV, "myorg/cli") {
+		t.Errorf("prs.csv = %s, want rows in enumeration order", wantCSV)
+	}
+}
+
+func TestAttributionDisabled(t *testing.T) {
+	a := &Analyzer{
+		cfg: &config.Config{
+			Attribution: config.AttributionConfig{Enabled: false, NoCodeownersFileBucket: "no_codeowners_file", GhostAuthorBucket: "ghost"},
+		},
+		logger: zap.NewNop(),
+	}
+	result := RepoResult{
+		Repo: &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}},
+		PRs: []*github.PullRequest{
+			{Number: github.Int(1), User: &github.User{Login: github.String("alice")}},
+			{Number: github.Int(2), User: &github.User{Login: github.String("bob")}},
+		},
+	}
+
+	got := a.aggregateResults(context.Background(), []RepoResult{result}, time.Time{}, time.Now(), nil)
+	if len(got.PRsByTeam) != 0 {
+		t.Errorf("PRsByTeam = %v, want empty without attribution", got.PRsByTeam)
+	}
+	if got.PRsByRepo["myorg/api"] != 2 {
+		t.Errorf("PRsByRepo = %v, want 2 PRs for myorg/api", got.PRsByRepo)
+	}
+	if got.PRsByUser["alice"] != 1 || got.PRsByUser["bob"] != 1 {
+		t.Errorf("PRsByUser = %v, want 1 PR each for alice and bob", got.PRsByUser)
+	}
+}
+
+func TestExternalMetrics(t *testing.T) {
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), User: &github.User{Login: github.String("alice")}},
+		{Number: github.Int(2), User: &github.User{Login: github.String("bob")}},
+		{Number: github.Int(3), User: &github.User{Login: github.String("alice")}},
+	}
+	newAnalyzer := func(command string) *Analyzer {
+		return &Analyzer{
+			cfg: &config.Config{
+				Attribution: config.AttributionConfig{Enabled: true, NoCodeownersFileBucket: "no_codeowners_file", GhostAuthorBucket: "ghost"},
+				Metrics:     config.MetricsConfig{ExternalCommand: command, ExternalBatchSize: 2, ExternalTimeoutSeconds: 10},
+			},
+			logger: zap.NewNop(),
+		}
+	}
+
+	t.Run("batches", func(t *testing.T) {
+		// Each run numbers its input lines, so line numbers restart per batch
+		a := newAnalyzer(`awk '{ print NR }'`)
+		got := a.externalMetrics(context.Background(), "myorg/api", prs)
+		want := map[int]string{1: "1", 2: "2", 3: "1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("externalMetrics() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("receives PRs as JSON lines", func(t *testing.T) {
+		a := newAnalyzer(`sed -e 's/.*"repo":"\([^"]*\)".*"number":\([0-9]*\).*/\1#\2/'`)
+		got := a.externalMetrics(context.Background(), "myorg/api", prs[:1])
+		if got[1] != "myorg/api#1" {
+			t.Errorf("externalMetrics() = %v, want myorg/api#1 for PR 1", got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		a := newAnalyzer(`echo broken >&2; exit 3`)
+		got := a.externalMetrics(context.Background(), "myorg/api", prs)
+		if len(got) != 0 {
+			t.Errorf("externalMetrics() = %v, want no results from a failing command", got)
+		}
+		if events := a.events.report().Events; len(events) != 2 {
+			t.Errorf("expected one run report event per failed batch, got %v", events)
+		}
+	})
+
+	t.Run("aggregation", func(t *testing.T) {
+		a := newAnalyzer("true")
+		result := RepoResult{
+			Repo:          &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}},
+			PRs:           prs,
+			CustomMetrics: map[int]string{1: "2.5", 2: "risky"},
+		}
+		got := a.aggregateResults(context.Background(), []RepoResult{result}, time.Time{}, time.Now(), nil)
+		if got.CustomMetricByUser["alice"] != 2.5 {
+			t.Errorf("CustomMetricByUser = %v, want 2.5 for alice", got.CustomMetricByUser)
+		}
+		if got.CustomMetricByTeam["no_codeowners_file"] != 2.5 {
+			t.Errorf("CustomMetricByTeam = %v, want 2.5 for no_codeowners_file", got.CustomMetricByTeam)
+		}
+		if !reflect.DeepEqual(got.PRsByCustomMetric, map[string]int{"risky": 1}) {
+			t.Errorf("PRsByCustomMetric = %v, want 1 risky PR", got.PRsByCustomMetric)
+		}
+		if got.CustomMetricFailedPRs != 1 {
+			t.Errorf("CustomMetricFailedPRs = %d, want 1", got.CustomMetricFailedPRs)
+		}
+	})
+
+	t.Run("non-finite results fail", func(t *testing.T) {
+		a := newAnalyzer("true")
+		result := RepoResult{
+			Repo:          &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}},
+			PRs:           prs,
+			CustomMetrics: map[int]string{1: "NaN", 2: "-Inf", 3: "1e999"},
+		}
+		got := a.aggregateResults(context.Background(), []RepoResult{result}, time.Time{}, time.Now(), nil)
+		if got.CustomMetricFailedPRs != 3 {
+			t.Errorf("CustomMetricFailedPRs = %d, want 3", got.CustomMetricFailedPRs)
+		}
+		if len(got.CustomMetricByUser) != 0 || len(got.PRsByCustomMetric) != 0 {
+			t.Errorf("CustomMetricByUser = %v, PRsByCustomMetric = %v, want neither summed nor counted", got.CustomMetricByUser, got.PRsByCustomMetric)
+		}
+	})
+}
+
+func TestCycleTime(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) *github.Timestamp {
+		return &github.Timestamp{Time: created.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	tests := []struct {
+		name string
+		pr   *github.PullRequest
+		want time.Duration
+		ok   bool
+	}{
+		{"merged", &github.PullRequest{CreatedAt: at(0), ClosedAt: at(10), MergedAt: at(8)}, 8 * time.Hour, true},
+		{"closed without merging", &github.PullRequest{CreatedAt: at(0), ClosedAt: at(5)}, 5 * time.Hour, true},
+		{"open", &github.PullRequest{CreatedAt: at(0)}, 0, false},
+		{"no creation time", &github.PullRequest{ClosedAt: at(5)}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := prCycleTime(tt.pr, nil)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: prCycleTime() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	stats := cycleTimeStats([]float64{4, 1, 3, 2, 10, 6, 5, 8, 7, 9})
+	want := &exporter.CycleTimeStats{PRs: 10, Mean: 5.5, Median: 5.5, P50: 5, P90: 9, P95: 10}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("cycleTimeStats() = %+v, want %+v", stats, want)
+	}
+	if cycleTimeStats(nil) != nil {
+		t.Error("expected no statistics without cycle times")
+	}
+}
+
+func TestReviewsByUser(t *testing.T) {
+	a := &Analyzer{
+		cfg:    &config.Config{Fetch: config.FetchConfig{Reviews: true}},
+		logger: zap.NewNop(),
+	}
+	review := func(login, state string) *github.PullRequestReview {
+		return &github.PullRequestReview{User: &github.User{Login: github.String(login)}, State: github.String(state)}
+	}
+	merged := &github.Timestamp{Time: time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC)}
+	result := RepoResult{
+		Repo: &github.Repository{Name: github.String("api"), Owner: &github.User{Login: github.String("myorg")}},
+		PRs: []*github.PullRequest{
+			{Number: github.Int(1), User: &github.User{Login: github.String("alice")}, MergedAt: merged},
+			{Number: github.Int(2), User: &github.User{Login: github.String("bob")}},
+			{Number: github.Int(3), User: &github.User{Login: github.String("alice")}, MergedAt: merged},
+		},
+		Reviews: map[int][]*github.PullRequestReview{
+			// Each reviewer counts once per PR; the author's own reviews do not count
+			1: {review("bob", "COMMENTED"), review("bob", "APPROVED"), review("carol", "APPROVED"), review("alice", "COMMENTED")},
+			// Unmerged PRs count toward review load too
+			2: {review("alice", "CHANGES_REQUESTED")},
+			// PR 3's reviews could not be loaded
+		},
+	}
+
+	g := a.newAggregator(time.Time{}, time.Now(), 1, nil)
+	g.add(context.Background(), result)
+	got := g.finish(context.Background())
+
+	want := map[string]int{"bob": 1, "carol": 1, "alice": 1}
+	if !reflect.DeepEqual(got.ReviewsByUser, want) {
+		t.Errorf("ReviewsByUser = %v, want %v", got.ReviewsByUser, want)
+	}
+}
@@ -0,0 +1,20 @@
+package analyzer
+
+import "github.com/fishnix/ghpr-analyzer/internal/config"
+
+// unassignedBusinessUnit is the business unit of repositories no business unit matches
+const unassignedBusinessUnit = "unassigned"
+
+// businessUnit returns the first business unit with a repo glob matching the
+// repository (owner/name), or the unassigned bucket. Globs without a slash
+// match the repository name alone.
+func businessUnit(repo string, units []config.BusinessUnitConfig) string {
+	for _, unit := range units {
+		for _, pattern := range unit.Repos {
+			if matchesGlob(pattern, repo) {
+				return unit.Name
+			}
+		}
+	}
+	return unassignedBusinessUnit
+}
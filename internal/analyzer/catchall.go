@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+)
+
+// withoutCatchAllRules returns codeowners without its catch-all rules when
+// attribution.ignore_catchall is set, so files only a default rule like
+// "* @org/default-team" matches count as unowned. More specific rules already
+// take precedence over a catch-all, so only those files are affected. The
+// parsed file may be shared (e.g. codeowners.source_repo), so it is copied.
+func (a *Analyzer) withoutCatchAllRules(codeowners *fetcher.CODEOWNERSFile) *fetcher.CODEOWNERSFile {
+	if codeowners == nil || !a.cfg.Attribution.IgnoreCatchall {
+		return codeowners
+	}
+
+	filtered := *codeowners
+	filtered.Rules = make([]fetcher.CODEOWNERSRule, 0, len(codeowners.Rules))
+	for _, rule := range codeowners.Rules {
+		if !isCatchAllPattern(rule.Pattern, a.cfg.Attribution.CatchallPatterns) {
+			filtered.Rules = append(filtered.Rules, rule)
+		}
+	}
+	return &filtered
+}
+
+// isCatchAllPattern reports whether a CODEOWNERS pattern is one of the
+// catch-all patterns. Parsed patterns are anchored with a leading slash, so
+// "*" and "/*" are the same pattern.
+func isCatchAllPattern(pattern string, catchAlls []string) bool {
+	pattern = "/" + strings.TrimPrefix(pattern, "/")
+	for _, catchAll := range catchAlls {
+		if pattern == "/"+strings.TrimPrefix(catchAll, "/") {
+			return true
+		}
+	}
+	return false
+}
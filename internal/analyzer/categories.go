@@ -0,0 +1,28 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/google/go-github/v62/github"
+)
+
+// uncategorized is the category of PRs no category's label globs match
+const uncategorized = "uncategorized"
+
+// prCategory returns the first category with a label glob matching one of the
+// PR's labels (case-insensitively), or the uncategorized bucket. Categories are
+// tried in config order, so a PR labeled both bug and feature falls into
+// whichever of the two is listed first.
+func prCategory(pr *github.PullRequest, categories []config.CategoryConfig) string {
+	for _, category := range categories {
+		for _, pattern := range category.Labels {
+			for _, label := range pr.Labels {
+				if matchesGlob(strings.ToLower(pattern), strings.ToLower(label.GetName())) {
+					return category.Name
+				}
+			}
+		}
+	}
+	return uncategorized
+}
@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+)
+
+// contributorStats summarizes how many PRs a typical author shipped, over the
+// authors with at least minPRs PRs. The ghost bucket is not a contributor and
+// is left out. It returns nil when no author qualifies.
+func contributorStats(prsByUser map[string]int, minPRs int, ghostBucket string) *exporter.ContributorStats {
+	var counts []int
+	total := 0
+	for user, prs := range prsByUser {
+		if user == ghostBucket || prs < minPRs {
+			continue
+		}
+		counts = append(counts, prs)
+		total += prs
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	sort.Ints(counts)
+
+	n := len(counts)
+	median := float64(counts[n/2])
+	if n%2 == 0 {
+		median = float64(counts[n/2-1]+counts[n/2]) / 2
+	}
+
+	return &exporter.ContributorStats{
+		Contributors: n,
+		MinPRs:       minPRs,
+		Mean:         float64(total) / float64(n),
+		Median:       median,
+		P90:          float64(counts[int(math.Ceil(0.9*float64(n)))-1]), // Nearest rank
+	}
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+	"github.com/google/go-github/v62/github"
+)
+
+// codeownersCoverage returns the share of a repository's distinct changed files
+// that some CODEOWNERS rule matches. ok is false when no changed files are known.
+func codeownersCoverage(codeowners *fetcher.CODEOWNERSFile, files map[int][]*github.CommitFile) (coverage float64, ok bool) {
+	owned := make(map[string]bool)
+	for _, prFiles := range files {
+		for _, file := range prFiles {
+			path := file.GetFilename()
+			if _, seen := owned[path]; seen {
+				continue
+			}
+			owned[path] = codeowners != nil && len(codeowners.FindOwners(path)) > 0
+		}
+	}
+	if len(owned) == 0 {
+		return 0, false
+	}
+
+	ownedFiles := 0
+	for _, isOwned := range owned {
+		if isOwned {
+			ownedFiles++
+		}
+	}
+	return float64(ownedFiles) / float64(len(owned)), true
+}
+
+// lowCoverageRepos returns the repositories whose coverage is below min, lowest first
+func lowCoverageRepos(coverageByRepo map[string]float64, min float64) []string {
+	var repos []string
+	for repo, coverage := range coverageByRepo {
+		if coverage < min {
+			repos = append(repos, repo)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if coverageByRepo[repos[i]] != coverageByRepo[repos[j]] {
+			return coverageByRepo[repos[i]] < coverageByRepo[repos[j]]
+		}
+		return repos[i] < repos[j]
+	})
+	return repos
+}
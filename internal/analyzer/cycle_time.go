@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/calendar"
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"github.com/google/go-github/v62/github"
+)
+
+// prCycleTime returns the time from a PR's creation to its merge, or to its
+// close when it was closed without merging. PRs missing either timestamp
+// (such as open PRs with fetch.pr_state all) have none.
+func prCycleTime(pr *github.PullRequest, cal *calendar.Calendar) (time.Duration, bool) {
+	end := pr.MergedAt
+	if end == nil {
+		end = pr.ClosedAt
+	}
+	if pr.CreatedAt == nil || end == nil || end.Before(pr.CreatedAt.Time) {
+		return 0, false
+	}
+	return cal.Duration(pr.CreatedAt.Time, end.Time), true
+}
+
+// cycleTimeStats summarizes the distribution of cycle times, in hours. It
+// returns nil for no cycle times.
+func cycleTimeStats(hours []float64) *exporter.CycleTimeStats {
+	if len(hours) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), hours...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, h := range sorted {
+		total += h
+	}
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	// Nearest rank, like the PRs per contributor statistics
+	percentile := func(p float64) float64 {
+		return sorted[int(math.Ceil(p*float64(n)))-1]
+	}
+
+	return &exporter.CycleTimeStats{
+		PRs:    n,
+		Mean:   total / float64(n),
+		Median: median,
+		P50:    percentile(0.5),
+		P90:    percentile(0.9),
+		P95:    percentile(0.95),
+	}
+}
+
+// cycleTimeStatsBy summarizes the cycle times of each key (team or repository)
+func cycleTimeStatsBy(hoursBy map[string][]float64) map[string]*exporter.CycleTimeStats {
+	stats := make(map[string]*exporter.CycleTimeStats, len(hoursBy))
+	for key, hours := range hoursBy {
+		if s := cycleTimeStats(hours); s != nil {
+			stats[key] = s
+		}
+	}
+	return stats
+}
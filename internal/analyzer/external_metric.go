@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// externalMetricStderrMax is the most stderr of a metrics.external_command run
+// kept for logs and the run report
+const externalMetricStderrMax = 4096
+
+// externalMetricInput is the JSON line a PR is piped to metrics.external_command as
+type externalMetricInput struct {
+	Repo string              `json:"repo"`
+	PR   *github.PullRequest `json:"pr"`
+}
+
+// externalMetrics runs metrics.external_command over a repository's PRs, in
+// batches of metrics.external_batch_size PRs, and returns each PR's result by
+// PR number. An empty result means the command had none for the PR; PRs of
+// batches that failed are left out. It returns nil without a command.
+func (a *Analyzer) externalMetrics(ctx context.Context, repoName string, prs []*github.PullRequest) map[int]string {
+	command := a.cfg.Metrics.ExternalCommand
+	if command == "" {
+		return nil
+	}
+
+	ctx, span := a.tracer.Start(ctx, "external_metric", "prs", len(prs))
+	defer span.End()
+
+	timeout := time.Duration(a.cfg.Metrics.ExternalTimeoutSeconds) * time.Second
+	results := make(map[int]string, len(prs))
+	for start := 0; start < len(prs); start += a.cfg.Metrics.ExternalBatchSize {
+		batch := prs[start:min(start+a.cfg.Metrics.ExternalBatchSize, len(prs))]
+		values, stderr, err := runExternalMetric(ctx, command, timeout, repoName, batch)
+		if stderr != "" {
+			a.logger.Debug("External metric command wrote to stderr",
+				zap.String("repo", repoName),
+				zap.String("stderr", stderr),
+			)
+		}
+		if err != nil {
+			a.logger.Warn("External metric command failed",
+				zap.String("repo", repoName),
+				zap.Int("prs", len(batch)),
+				zap.String("stderr", stderr),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, repoName, 0, fmt.Sprintf("external metric command failed for %d PRs: %v", len(batch), err))
+			continue
+		}
+		for i, pr := range batch {
+			results[pr.GetNumber()] = values[i]
+		}
+	}
+	return results
+}
+
+// runExternalMetric pipes PRs to command, run with sh -c, as one JSON object
+// per line and reads one result per line back, in the same order. It returns
+// the results with the command's (truncated) stderr.
+func runExternalMetric(ctx context.Context, command string, timeout time.Duration, repoName string, prs []*github.PullRequest) ([]string, string, error) {
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, pr := range prs {
+		if err := encoder.Encode(externalMetricInput{Repo: repoName, PR: pr}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode PR #%d: %w", pr.GetNumber(), err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = &input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stderrText := strings.TrimSpace(stderr.String())
+	if len(stderrText) > externalMetricStderrMax {
+		stderrText = stderrText[:externalMetricStderrMax] + "..."
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, stderrText, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return nil, stderrText, err
+	}
+
+	var values []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		values = append(values, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, stderrText, fmt.Errorf("failed to read output: %w", err)
+	}
+	if len(values) != len(prs) {
+		return nil, stderrText, fmt.Errorf("printed %d results for %d PRs", len(values), len(prs))
+	}
+	return values, stderrText, nil
+}
+
+// externalMetricValue interprets a result of metrics.external_command: a
+// number is summed, anything else is a category PRs are counted by. NaN,
+// infinities and numbers out of float64 range are not valid, as they would
+// poison the sums they are added to.
+func externalMetricValue(result string) (number float64, numeric, valid bool) {
+	number, err := strconv.ParseFloat(result, 64)
+	if errors.Is(err, strconv.ErrRange) || (err == nil && (math.IsNaN(number) || math.IsInf(number, 0))) {
+		return 0, false, false
+	}
+	return number, err == nil, true
+}
@@ -1,9 +1,14 @@
 package analyzer
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/fishnix/ghpr-analyzer/internal/cache"
 	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
 )
@@ -53,7 +58,7 @@ func TestApplyFilters(t *testing.T) {
 		},
 	}
 
-	filtered := analyzer.applyFilters(prs)
+	filtered := analyzer.applyFilters("org/repo", prs)
 
 	if len(filtered) != 1 {
 		t.Fatalf("Expected 1 PR after filtering, got %d", len(filtered))
@@ -63,3 +68,412 @@ func TestApplyFilters(t *testing.T) {
 		t.Errorf("Expected PR #1, got PR #%d", filtered[0].GetNumber())
 	}
 }
+
+func TestApplyFiltersExcludeReverts(t *testing.T) {
+	cfg := &config.Config{
+		Filters: config.FiltersConfig{
+			ExcludeReverts: true,
+		},
+	}
+
+	analyzer := &Analyzer{
+		cfg:    cfg,
+		logger: zap.NewNop(),
+	}
+
+	prs := []*github.PullRequest{
+		{
+			Number: github.Int(1),
+			Title:  github.String("Add feature flag"),
+		},
+		{
+			Number: github.Int(2),
+			Title:  github.String(`Revert "Add feature flag"`),
+			Body:   github.String("Reverts my-org/my-repo#1"),
+		},
+		{
+			Number: github.Int(3),
+			Title:  github.String("Document how to revert a deploy"),
+		},
+	}
+
+	filtered := analyzer.applyFilters("My-Org/my-repo", prs)
+
+	if len(filtered) != 1 {
+		t.Fatalf("Expected 1 PR after filtering, got %d", len(filtered))
+	}
+
+	if filtered[0].GetNumber() != 3 {
+		t.Errorf("Expected PR #3, got PR #%d", filtered[0].GetNumber())
+	}
+}
+
+func TestApplyFiltersKeepsPRsRevertedInAnotherRepo(t *testing.T) {
+	analyzer := &Analyzer{
+		cfg:    &config.Config{Filters: config.FiltersConfig{ExcludeReverts: true}},
+		logger: zap.NewNop(),
+	}
+
+	prs := []*github.PullRequest{
+		{
+			Number: github.Int(1),
+			Title:  github.String("Add feature flag"),
+		},
+		{
+			Number: github.Int(2),
+			Title:  github.String(`Revert "Add feature flag"`),
+			Body:   github.String("Reverts my-org/other-repo#1"),
+		},
+	}
+
+	filtered := analyzer.applyFilters("my-org/my-repo", prs)
+
+	// The revert itself is still excluded, but PR #1 of this repo is not the one it reverts
+	if len(filtered) != 1 || filtered[0].GetNumber() != 1 {
+		t.Errorf("Expected PR #1 only, got %d PRs", len(filtered))
+	}
+}
+
+func TestApplyFiltersKeepsRevertsByDefault(t *testing.T) {
+	analyzer := &Analyzer{
+		cfg:    &config.Config{},
+		logger: zap.NewNop(),
+	}
+
+	prs := []*github.PullRequest{
+		{
+			Number: github.Int(1),
+			Title:  github.String(`Revert "Add feature flag"`),
+		},
+	}
+
+	filtered := analyzer.applyFilters("org/repo", prs)
+
+	if len(filtered) != 1 {
+		t.Fatalf("Expected 1 PR after filtering, got %d", len(filtered))
+	}
+}
+
+func TestIsTrivialPR(t *testing.T) {
+	trivialPaths := []string{"*.md", "docs/**", "*.yaml"}
+
+	files := func(names ...string) []*github.CommitFile {
+		var commitFiles []*github.CommitFile
+		for _, name := range names {
+			commitFiles = append(commitFiles, &github.CommitFile{Filename: github.String(name)})
+		}
+		return commitFiles
+	}
+
+	tests := []struct {
+		name  string
+		files []*github.CommitFile
+		want  bool
+	}{
+		{"docs only", files("README.md", "docs/guide/setup.txt"), true},
+		{"config only", files("deploy/values.yaml"), true},
+		{"code and docs", files("README.md", "main.go"), false},
+		{"nested docs dir is not top-level docs", files("src/docs/api.go"), false},
+		{"no files known", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTrivialPR(tt.files, trivialPaths); got != tt.want {
+				t.Errorf("isTrivialPR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludeListedPRs(t *testing.T) {
+	analyzer := &Analyzer{
+		cfg: &config.Config{
+			Filters: config.FiltersConfig{
+				ExcludePRNumbers: []string{"my-org/repo#2", "My-Org/Other#1"},
+			},
+		},
+		logger: zap.NewNop(),
+	}
+
+	prs := []*github.PullRequest{
+		{Number: github.Int(1)},
+		{Number: github.Int(2)},
+		{Number: github.Int(3)},
+	}
+
+	kept := analyzer.excludeListedPRs("my-org/repo", prs)
+	if len(kept) != 2 || kept[0].GetNumber() != 1 || kept[1].GetNumber() != 3 {
+		t.Errorf("expected PRs 1 and 3 to be kept in my-org/repo, got %v", kept)
+	}
+
+	kept = analyzer.excludeListedPRs("my-org/other", prs)
+	if len(kept) != 2 || kept[0].GetNumber() != 2 || kept[1].GetNumber() != 3 {
+		t.Errorf("expected PRs 2 and 3 to be kept in my-org/other, got %v", kept)
+	}
+}
+
+func TestExcludeSelfMerged(t *testing.T) {
+	merged := &github.Timestamp{}
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), User: &github.User{Login: github.String("alice")}, MergedAt: merged, MergedBy: &github.User{Login: github.String("bob")}},
+		{Number: github.Int(2), User: &github.User{Login: github.String("alice")}, MergedAt: merged, MergedBy: &github.User{Login: github.String("Alice")}},
+		{Number: github.Int(3), User: &github.User{Login: github.String("alice")}, MergedAt: merged},                                      // Merger unknown
+		{Number: github.Int(4), User: &github.User{Login: github.String("alice")}, MergedBy: &github.User{Login: github.String("alice")}}, // Not merged
+	}
+
+	for _, exclude := range []bool{false, true} {
+		analyzer := &Analyzer{
+			cfg:    &config.Config{Filters: config.FiltersConfig{ExcludeSelfMerged: exclude}},
+			logger: zap.NewNop(),
+		}
+
+		kept, selfMerged := analyzer.excludeSelfMerged(prs)
+		if len(selfMerged) != 1 || selfMerged[0].GetNumber() != 2 {
+			t.Errorf("exclude=%v: expected PR 2 to be self-merged, got %d PRs", exclude, len(selfMerged))
+		}
+		wantKept := 4
+		if exclude {
+			wantKept = 3
+		}
+		if len(kept) != wantKept {
+			t.Errorf("exclude=%v: expected %d PRs kept, got %d", exclude, wantKept, len(kept))
+		}
+	}
+}
+
+func TestExcludeMassChanges(t *testing.T) {
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), ChangedFiles: github.Int(12)},
+		{Number: github.Int(2), ChangedFiles: github.Int(4000)},
+		{Number: github.Int(3), ChangedFiles: github.Int(500)}, // At the limit
+		{Number: github.Int(4)},                                // Changed file count unknown
+	}
+
+	analyzer := &Analyzer{
+		cfg:    &config.Config{Filters: config.FiltersConfig{MaxChangedFiles: 500}},
+		logger: zap.NewNop(),
+	}
+	kept, excluded := analyzer.excludeMassChanges(prs)
+	if excluded != 1 {
+		t.Errorf("expected 1 mass change PR excluded, got %d", excluded)
+	}
+	if len(kept) != 3 || kept[0].GetNumber() != 1 || kept[1].GetNumber() != 3 || kept[2].GetNumber() != 4 {
+		t.Errorf("expected PRs 1, 3 and 4 to be kept, got %v", kept)
+	}
+
+	// Without a limit every PR is kept
+	analyzer.cfg.Filters.MaxChangedFiles = 0
+	if kept, excluded := analyzer.excludeMassChanges(prs); len(kept) != 4 || excluded != 0 {
+		t.Errorf("expected all 4 PRs kept without a limit, got %d kept and %d excluded", len(kept), excluded)
+	}
+}
+
+func TestApplyFiltersBaseBranchRegexes(t *testing.T) {
+	baseBranches, err := compileBaseBranchRegexes([]string{"^(main|release/.*)$"})
+	if err != nil {
+		t.Fatalf("compileBaseBranchRegexes() error = %v", err)
+	}
+	analyzer := &Analyzer{
+		cfg:          &config.Config{},
+		baseBranches: baseBranches,
+		logger:       zap.NewNop(),
+	}
+
+	bases := []string{"main", "release/1.2", "feature/big-rewrite", "main-old", "hotfix/release/1.2", ""}
+	var prs []*github.PullRequest
+	for i, base := range bases {
+		prs = append(prs, &github.PullRequest{
+			Number: github.Int(i + 1),
+			Base:   &github.PullRequestBranch{Ref: github.String(base)},
+		})
+	}
+
+	filtered := analyzer.applyFilters("org/repo", prs)
+
+	var kept []string
+	for _, pr := range filtered {
+		kept = append(kept, pr.GetBase().GetRef())
+	}
+	if len(kept) != 2 || kept[0] != "main" || kept[1] != "release/1.2" {
+		t.Errorf("expected PRs into main and release/1.2 to be kept, got %v", kept)
+	}
+}
+
+func TestApplyFiltersKeepsAllBaseBranchesByDefault(t *testing.T) {
+	analyzer := &Analyzer{
+		cfg:    &config.Config{},
+		logger: zap.NewNop(),
+	}
+
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), Base: &github.PullRequestBranch{Ref: github.String("feature/x")}},
+		{Number: github.Int(2)}, // Base unknown
+	}
+
+	if filtered := analyzer.applyFilters("org/repo", prs); len(filtered) != 2 {
+		t.Errorf("expected 2 PRs after filtering, got %d", len(filtered))
+	}
+}
+
+func TestCompileBaseBranchRegexesRejectsInvalid(t *testing.T) {
+	if _, err := compileBaseBranchRegexes([]string{"^main$", "release/(.*"}); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestFilterRepos(t *testing.T) {
+	var repos []*github.Repository
+	for _, name := range []string{"service-api", "service-legacy", "web", "service-web"} {
+		repos = append(repos, &github.Repository{Name: github.String(name)})
+	}
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{"no globs keeps all", nil, nil, []string{"service-api", "service-legacy", "web", "service-web"}},
+		{"include", []string{"service-*"}, nil, []string{"service-api", "service-legacy", "service-web"}},
+		{"exclude", nil, []string{"web"}, []string{"service-api", "service-legacy", "service-web"}},
+		{"exclude wins over include", []string{"service-*"}, []string{"*-legacy"}, []string{"service-api", "service-web"}},
+		{"any include glob", []string{"web", "*-api"}, nil, []string{"service-api", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, repo := range filterRepos(repos, tt.include, tt.exclude) {
+				got = append(got, repo.GetName())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveRepos(t *testing.T) {
+	repos := []*github.Repository{
+		{Name: github.String("api")},
+		{Name: github.String("old"), Archived: github.Bool(true)},
+		{Name: github.String("blocked"), Disabled: github.Bool(true)},
+		{Name: github.String("old-blocked"), Archived: github.Bool(true), Disabled: github.Bool(true)},
+	}
+
+	names := func(repos []*github.Repository) []string {
+		var names []string
+		for _, repo := range repos {
+			names = append(names, repo.GetName())
+		}
+		return names
+	}
+
+	active, archived, disabled := activeRepos(repos, false)
+	if got := names(active); !reflect.DeepEqual(got, []string{"api"}) || archived != 1 || disabled != 2 {
+		t.Errorf("activeRepos() = %v, %d archived, %d disabled; want [api], 1 archived, 2 disabled", got, archived, disabled)
+	}
+
+	// Disabled repositories are skipped even with include_archived
+	active, archived, disabled = activeRepos(repos, true)
+	if got := names(active); !reflect.DeepEqual(got, []string{"api", "old"}) || archived != 0 || disabled != 2 {
+		t.Errorf("activeRepos(includeArchived) = %v, %d archived, %d disabled; want [api old], 0 archived, 2 disabled", got, archived, disabled)
+	}
+}
+
+func TestEnumerateReposKeepsNamedRepo(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	c := cache.NewMemoryCache(time.Hour, false, false, logger)
+
+	// An archived repository excluded by name, named explicitly
+	repo := &github.Repository{Name: github.String("legacy-api"), FullName: github.String("myorg/legacy-api"), Archived: github.Bool(true)}
+	if err := c.SetRepos(ctx, "myorg", "repo-legacy-api", []*github.Repository{repo}); err != nil {
+		t.Fatal(err)
+	}
+	analyzer := &Analyzer{
+		cfg: &config.Config{
+			GitHub:  config.GitHubConfig{Org: "myorg", Repo: "myorg/legacy-api"},
+			Filters: config.FiltersConfig{ExcludeRepos: []string{"legacy-*"}},
+		},
+		logger: logger,
+		cache:  c,
+	}
+
+	repos, err := analyzer.enumerateRepos(ctx)
+	if err != nil {
+		t.Fatalf("enumerateRepos() = %v", err)
+	}
+	if len(repos) != 1 || repos[0].GetName() != "legacy-api" {
+		t.Errorf("enumerateRepos() = %v, want the named repository", repos)
+	}
+}
+
+func TestEnumerateReposLimitKeepsMostActiveWithActivityPriority(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	c := cache.NewMemoryCache(time.Hour, false, false, logger)
+
+	pushed := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := func(name string, daysAgo int) *github.Repository {
+		return &github.Repository{
+			Name:     github.String(name),
+			FullName: github.String("myorg/" + name),
+			PushedAt: &github.Timestamp{Time: pushed.AddDate(0, 0, -daysAgo)},
+		}
+	}
+	var enum *fetcher.RepoEnumerator
+	listing := []*github.Repository{repo("api", 30), repo("billing", 20), repo("web", 1), repo("worker", 1)}
+	if err := c.SetRepos(ctx, "myorg", enum.FilterSignature(), listing); err != nil {
+		t.Fatal(err)
+	}
+	analyzer := &Analyzer{
+		cfg: &config.Config{
+			GitHub:      config.GitHubConfig{Org: "myorg"},
+			Concurrency: config.ConcurrencyConfig{LimitRepos: 3, Priority: "activity"},
+		},
+		logger: logger,
+		cache:  c,
+	}
+
+	repos, err := analyzer.enumerateRepos(ctx)
+	if err != nil {
+		t.Fatalf("enumerateRepos() = %v", err)
+	}
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.GetName())
+	}
+	// The most recently pushed, ties by name, rather than the first by name
+	if want := []string{"web", "worker", "billing"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("enumerateRepos() = %v, want %v", names, want)
+	}
+}
+
+func TestApplyFiltersExcludeAuthorsMatchesAliases(t *testing.T) {
+	cfg := &config.Config{
+		Filters: config.FiltersConfig{
+			ExcludeAuthors: []string{"old-bot", "alice"},
+		},
+		IdentityMap: map[string]string{
+			"old-bot":  "release-bot",
+			"alice-gh": "alice",
+		},
+	}
+	analyzer := &Analyzer{cfg: cfg, logger: zap.NewNop()}
+
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), User: &github.User{Login: github.String("old-bot")}},  // Excluded by its raw login
+		{Number: github.Int(2), User: &github.User{Login: github.String("alice-gh")}}, // Excluded by its canonical login
+		{Number: github.Int(3), User: &github.User{Login: github.String("bob")}},
+	}
+
+	filtered := analyzer.applyFilters("org/repo", prs)
+
+	if len(filtered) != 1 || filtered[0].GetNumber() != 3 {
+		var numbers []int
+		for _, pr := range filtered {
+			numbers = append(numbers, pr.GetNumber())
+		}
+		t.Fatalf("Expected only PR #3 after filtering, got %v", numbers)
+	}
+}
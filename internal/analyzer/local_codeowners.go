@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+)
+
+// localCODEOWNERS holds the CODEOWNERS files loaded from the local filesystem
+// in place of the API
+type localCODEOWNERS struct {
+	all    *fetcher.CODEOWNERSFile            // codeowners.local_file; nil unless set
+	byRepo map[string]*fetcher.CODEOWNERSFile // codeowners.local_files, keyed by lower-case owner/repo
+}
+
+// loadLocalCODEOWNERS parses the configured local CODEOWNERS files up front, so
+// a missing or unreadable file fails the run instead of silently falling back
+// to the API
+func loadLocalCODEOWNERS(cfg config.CODEOWNERSConfig, codeownersFetcher *fetcher.CODEOWNERSFetcher) (*localCODEOWNERS, error) {
+	local := &localCODEOWNERS{byRepo: make(map[string]*fetcher.CODEOWNERSFile, len(cfg.LocalFiles))}
+	if cfg.LocalFile != "" {
+		file, err := codeownersFetcher.LoadCODEOWNERSFile(cfg.LocalFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load codeowners.local_file: %w", err)
+		}
+		local.all = file
+	}
+	for repo, path := range cfg.LocalFiles {
+		file, err := codeownersFetcher.LoadCODEOWNERSFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load codeowners.local_files entry %q: %w", repo, err)
+		}
+		local.byRepo[strings.ToLower(repo)] = file
+	}
+	return local, nil
+}
+
+// forRepo returns the local CODEOWNERS of a repository, or nil if it has none
+// and its CODEOWNERS comes from the API
+func (l *localCODEOWNERS) forRepo(owner, name string) *fetcher.CODEOWNERSFile {
+	if l == nil {
+		return nil
+	}
+	if file, ok := l.byRepo[strings.ToLower(owner+"/"+name)]; ok {
+		return file
+	}
+	return l.all
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// notifyGitHubIssue posts the Markdown summary as a comment on the issue set in
+// notify.github_issue. Publishing is best-effort: failures are warnings and
+// never fail the run.
+func (a *Analyzer) notifyGitHubIssue(ctx context.Context, result *exporter.AnalysisResult) {
+	ref := a.cfg.Notify.GitHubIssue
+	if ref == "" {
+		return
+	}
+	if a.skipAPICalls {
+		a.logger.Info("Skipping GitHub issue notification (cache-only mode)", zap.String("issue", ref))
+		return
+	}
+
+	match := config.PRRefPattern.FindStringSubmatch(ref)
+	if match == nil {
+		a.logger.Warn("Invalid notify.github_issue, expected owner/repo#number", zap.String("issue", ref))
+		return
+	}
+	owner, repo := match[1], match[2]
+	number, _ := strconv.Atoi(match[3])
+
+	comment := &github.IssueComment{Body: github.String(exporter.MarkdownSummary(result, a.locale))}
+	created, _, err := a.ghClient.GetClient().Issues.CreateComment(ctx, owner, repo, number, comment)
+	if err != nil {
+		a.logger.Warn("Failed to post summary to GitHub issue", zap.String("issue", ref), zap.Error(err))
+		a.events.record(severityWarning, owner+"/"+repo, 0, fmt.Sprintf("failed to post summary to issue #%d: %v", number, err))
+		return
+	}
+
+	a.logger.Info("Posted summary to GitHub issue",
+		zap.String("issue", ref),
+		zap.String("url", created.GetHTMLURL()),
+	)
+}
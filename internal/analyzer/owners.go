@@ -0,0 +1,56 @@
+package analyzer
+
+import "strings"
+
+// ownerKind is the type of a CODEOWNERS owner
+type ownerKind string
+
+const (
+	ownerUser  ownerKind = "user"  // @user
+	ownerTeam  ownerKind = "team"  // @org/team, or a deeper slug such as @enterprise/org/team
+	ownerEmail ownerKind = "email" // user@example.com
+)
+
+// codeOwner is a classified CODEOWNERS owner
+type codeOwner struct {
+	Kind ownerKind
+	Name string // Canonical name: the login, team path (org/team, enterprise/org/team) or email address, lowercased
+}
+
+// classifyOwner classifies an owner as written in CODEOWNERS or in the config
+// and canonicalizes its name. GitHub logins, team slugs and email addresses are
+// case-insensitive, so names are lowercased; the @ prefix is optional.
+func classifyOwner(owner string) codeOwner {
+	owner = strings.ToLower(strings.TrimSpace(owner))
+
+	// An @ anywhere but the start is an email address
+	if at := strings.Index(owner, "@"); at > 0 {
+		return codeOwner{Kind: ownerEmail, Name: owner}
+	}
+
+	name := strings.TrimPrefix(owner, "@")
+	if strings.Contains(name, "/") {
+		return codeOwner{Kind: ownerTeam, Name: name}
+	}
+	return codeOwner{Kind: ownerUser, Name: name}
+}
+
+// matches reports whether o, as written in the config (e.g. a team_rollup
+// entry), refers to owner. Besides the canonical forms, a shorter reference
+// matches a team whose trailing path segments it names: "payments" matches
+// "@myorg/payments" in any organization, and "myorg/payments" matches
+// "@enterprise/myorg/payments" in any enterprise.
+func (o codeOwner) matches(owner codeOwner) bool {
+	if o == owner {
+		return true
+	}
+	if owner.Kind != ownerTeam || (o.Kind != ownerUser && o.Kind != ownerTeam) {
+		return false
+	}
+	return strings.HasSuffix(owner.Name, "/"+o.Name)
+}
+
+// normalizeOwner returns the canonical name an owner is counted under
+func normalizeOwner(owner string) string {
+	return classifyOwner(owner).Name
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+	"go.uber.org/zap"
+)
+
+// DetectOwnershipChanges resolves the owners of every path touched by a PR in the
+// time window against CODEOWNERS at two git refs and exports the paths whose
+// owners differ to ownership_changes.csv. An empty ref means the default branch.
+// Only repositories with a CODEOWNERS file on the default branch are compared,
+// since changed files are only fetched for those.
+func (a *Analyzer) DetectOwnershipChanges(ctx context.Context, fromRef, toRef string) error {
+	since, until, err := a.cfg.GetTimeWindow()
+	if err != nil {
+		return fmt.Errorf("failed to get time window: %w", err)
+	}
+
+	repos, err := a.enumerateRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := a.processRepos(ctx, repos, since, until, nil)
+
+	// Close cache
+	if a.cache != nil {
+		if err := a.cache.Close(); err != nil {
+			a.logger.Warn("Failed to close cache", zap.Error(err))
+		}
+	}
+
+	var changes []exporter.OwnershipChange
+	for _, result := range results {
+		if result.Err != nil || len(result.Files) == 0 {
+			continue
+		}
+
+		owner := result.Repo.GetOwner().GetLogin()
+		name := result.Repo.GetName()
+		repoName := fmt.Sprintf("%s/%s", owner, name)
+
+		// An oversized file counts as none, as it does in the analysis
+		before, _, err := a.codeownersFetcher.FetchCODEOWNERSAtRef(ctx, owner, name, fromRef)
+		if err != nil && !errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+			a.logger.Warn("Failed to fetch CODEOWNERS", zap.String("repo", repoName), zap.String("ref", fromRef), zap.Error(err))
+			continue
+		}
+		after, _, err := a.codeownersFetcher.FetchCODEOWNERSAtRef(ctx, owner, name, toRef)
+		if err != nil && !errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+			a.logger.Warn("Failed to fetch CODEOWNERS", zap.String("repo", repoName), zap.String("ref", toRef), zap.Error(err))
+			continue
+		}
+
+		changes = append(changes, diffOwnership(repoName, touchedPaths(result), before, after)...)
+	}
+
+	// Summarize coverage gained and lost per team
+	gained := make(map[string]int)
+	lost := make(map[string]int)
+	for _, change := range changes {
+		for _, team := range change.TeamsGained {
+			gained[team]++
+		}
+		for _, team := range change.TeamsLost {
+			lost[team]++
+		}
+	}
+	a.logger.Info("Ownership change detection complete",
+		zap.Int("changed_paths", len(changes)),
+		zap.Any("paths_gained_by_team", gained),
+		zap.Any("paths_lost_by_team", lost),
+	)
+
+	csvExporter := exporter.NewCSVExporter(a.cfg.Output.OutputDir, nil, a.logger)
+	if err := csvExporter.ExportOwnershipChanges(changes); err != nil {
+		return fmt.Errorf("failed to export ownership changes: %w", err)
+	}
+
+	return nil
+}
+
+// touchedPaths returns the distinct paths changed by a repository's PRs, sorted
+func touchedPaths(result RepoResult) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, files := range result.Files {
+		for _, file := range files {
+			path := file.GetFilename()
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// diffOwnership compares the owners of each path under two CODEOWNERS files
+// (either may be nil) and returns the paths whose owners differ
+func diffOwnership(repo string, paths []string, before, after *fetcher.CODEOWNERSFile) []exporter.OwnershipChange {
+	var changes []exporter.OwnershipChange
+	for _, path := range paths {
+		previous := before.FindOwners(path)
+		current := after.FindOwners(path)
+
+		teamsGained := ownerDifference(current, previous)
+		teamsLost := ownerDifference(previous, current)
+		if len(teamsGained) == 0 && len(teamsLost) == 0 {
+			continue
+		}
+
+		changes = append(changes, exporter.OwnershipChange{
+			Repo:           repo,
+			Path:           path,
+			PreviousOwners: previous,
+			CurrentOwners:  current,
+			TeamsGained:    teamsGained,
+			TeamsLost:      teamsLost,
+		})
+	}
+	return changes
+}
+
+// ownerDifference returns the normalized owners in a that are not in b
+func ownerDifference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, owner := range b {
+		inB[normalizeOwner(owner)] = true
+	}
+
+	var diff []string
+	for _, owner := range a {
+		if normalized := normalizeOwner(owner); !inB[normalized] {
+			diff = append(diff, normalized)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fishnix/ghpr-analyzer/internal/cache"
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// codeownersAtMerges returns the CODEOWNERS file as of the merge commit of each
+// merged PR, keyed by PR number, for attribution.point_in_time. A nil file
+// means the repository had no CODEOWNERS at that commit. PRs whose owning
+// teams are cached, or whose version could not be loaded, are left out and
+// attributed with the current file.
+func (a *Analyzer) codeownersAtMerges(ctx context.Context, owner, name string, prs []*github.PullRequest, prTeams map[int][]string) map[int]*fetcher.CODEOWNERSFile {
+	ctx, span := a.tracer.Start(ctx, "fetch_codeowners_at_merges", "prs", len(prs))
+	defer span.End()
+
+	versions := make(map[int]*fetcher.CODEOWNERSFile)
+	for _, pr := range prs {
+		if _, ok := prTeams[pr.GetNumber()]; ok || pr.MergedAt == nil || pr.GetMergeCommitSHA() == "" {
+			continue
+		}
+		codeowners, ok := a.codeownersAtCommit(ctx, owner, name, pr.GetNumber(), pr.GetMergeCommitSHA())
+		if ok {
+			versions[pr.GetNumber()] = codeowners
+		}
+	}
+	return versions
+}
+
+// codeownersAtCommit returns the parsed CODEOWNERS file of a repository as of
+// a commit, from the cache or the API. Commits never change, so neither does
+// their CODEOWNERS, and cached versions are reused forever. It reports false
+// when the version is unavailable.
+func (a *Analyzer) codeownersAtCommit(ctx context.Context, owner, name string, prNumber int, sha string) (*fetcher.CODEOWNERSFile, bool) {
+	var content []byte
+	if a.cache != nil {
+		if cached, err := a.cache.GetCODEOWNERSAtCommit(ctx, owner, name, sha); err == nil && len(cached) > 0 {
+			content = cached
+		}
+	}
+
+	if content == nil {
+		if a.skipAPICalls {
+			return nil, false
+		}
+		fetched, err := a.fetchOnce("codeowners:"+owner+"/"+name+"@"+sha, func() (interface{}, error) {
+			parsed, rawContent, err := a.codeownersFetcher.FetchCODEOWNERSAtRef(ctx, owner, name, sha)
+			if errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+				// Absent at this commit, but not cached as such (see loadCODEOWNERS)
+				return cache.AbsentCODEOWNERS, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if parsed == nil {
+				rawContent = cache.AbsentCODEOWNERS
+			}
+			if a.cache != nil {
+				if err := a.cache.SetCODEOWNERSAtCommit(ctx, owner, name, sha, rawContent); err != nil {
+					a.logger.Warn("Failed to cache CODEOWNERS at commit", zap.Error(err))
+				}
+			}
+			return rawContent, nil
+		})
+		if err != nil {
+			a.logger.Warn("Failed to fetch CODEOWNERS at merge commit, using the current file",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+				zap.Int("pr_number", prNumber),
+				zap.String("sha", sha),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, owner+"/"+name, prNumber, fmt.Sprintf("failed to fetch CODEOWNERS at merge commit %s: %v", sha, err))
+			return nil, false
+		}
+		content = fetched.([]byte)
+	}
+
+	if cache.IsAbsentCODEOWNERS(content) {
+		return nil, true
+	}
+	codeowners, err := a.codeownersVersion(content)
+	if err != nil {
+		a.logger.Warn("Failed to parse CODEOWNERS at merge commit, using the current file",
+			zap.String("repo", fmt.Sprintf("%s/%s", owner, name)),
+			zap.String("sha", sha),
+			zap.Error(err),
+		)
+		a.events.record(severityWarning, owner+"/"+name, prNumber, fmt.Sprintf("failed to parse CODEOWNERS at merge commit %s: %v", sha, err))
+		return nil, false
+	}
+	return codeowners, true
+}
+
+// codeownersVersion returns the parse of CODEOWNERS content, without catch-all
+// rules if they are ignored. Versions are kept by content hash, so the many
+// commits sharing a CODEOWNERS file share one parse.
+func (a *Analyzer) codeownersVersion(content []byte) (*fetcher.CODEOWNERSFile, error) {
+	key := a.codeownersParseKey(content)
+	if codeowners, ok := a.versions.Load(key); ok {
+		return codeowners.(*fetcher.CODEOWNERSFile), nil
+	}
+
+	parsed, err := a.codeownersFetcher.ParseCODEOWNERS(content, "")
+	if err != nil {
+		return nil, err
+	}
+	codeowners, _ := a.versions.LoadOrStore(key, a.withoutCatchAllRules(parsed))
+	return codeowners.(*fetcher.CODEOWNERSFile), nil
+}
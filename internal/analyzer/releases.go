@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/calendar"
+	"github.com/google/go-github/v62/github"
+)
+
+// publishedReleaseTimes returns the publication times of a repository's
+// published releases in ascending order. Drafts have no publication time and
+// are skipped. The result is non-nil even without releases, so callers can
+// tell a repository without releases from one whose releases are unknown.
+func publishedReleaseTimes(releases []*github.RepositoryRelease) []time.Time {
+	times := make([]time.Time, 0, len(releases))
+	for _, release := range releases {
+		if release.GetDraft() || release.PublishedAt == nil {
+			continue
+		}
+		times = append(times, release.GetPublishedAt().Time)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// releaseLeadTime returns the time from a merge to the first release published
+// after it, measured on cal; ok is false when no release has been published
+// since the merge
+func releaseLeadTime(mergedAt time.Time, releaseTimes []time.Time, cal *calendar.Calendar) (lead time.Duration, ok bool) {
+	i := sort.Search(len(releaseTimes), func(i int) bool { return releaseTimes[i].After(mergedAt) })
+	if i == len(releaseTimes) {
+		return 0, false
+	}
+	return cal.Duration(mergedAt, releaseTimes[i]), true
+}
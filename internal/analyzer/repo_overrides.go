@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/google/go-github/v62/github"
+)
+
+// repoOverride returns the repo_overrides entry of a repository (owner/repo),
+// or the zero override, which keeps every global setting
+func (a *Analyzer) repoOverride(repo string) config.RepoOverrideConfig {
+	return a.cfg.RepoOverrides[strings.ToLower(repo)]
+}
+
+// repoFilters returns the filters of a repository: the global filters with
+// those its override sets replaced
+func (a *Analyzer) repoFilters(repo string) config.FiltersConfig {
+	filters := a.cfg.Filters
+	override := a.repoOverride(repo).Filters
+	if override.ExcludeAuthors != nil {
+		filters.ExcludeAuthors = override.ExcludeAuthors
+	}
+	if override.ExcludeTitlePrefixes != nil {
+		filters.ExcludeTitlePrefixes = override.ExcludeTitlePrefixes
+	}
+	if override.ExcludeReverts != nil {
+		filters.ExcludeReverts = *override.ExcludeReverts
+	}
+	return filters
+}
+
+// repoAttributionMode returns the attribution mode of a repository
+func (a *Analyzer) repoAttributionMode(repo string) string {
+	if mode := a.repoOverride(repo).AttributionMode; mode != "" {
+		return mode
+	}
+	return a.cfg.Attribution.Mode
+}
+
+// withoutExcludedPaths drops the changed files of a repository its override
+// leaves out of attribution
+func (a *Analyzer) withoutExcludedPaths(repo string, files []*github.CommitFile) []*github.CommitFile {
+	excludePaths := a.repoOverride(repo).ExcludePaths
+	if len(excludePaths) == 0 {
+		return files
+	}
+
+	kept := make([]*github.CommitFile, 0, len(files))
+	for _, file := range files {
+		excluded := false
+		for _, pattern := range excludePaths {
+			if matchesGlob(pattern, file.GetFilename()) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
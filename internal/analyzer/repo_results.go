@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// repoResultsVersion is the version of the repo results dump format; bump it
+// whenever RepoResult changes incompatibly
+const repoResultsVersion = 2
+
+// repoResultsDump is the processed repositories of a run, frozen so that
+// aggregation can be re-run against them (output.dump_repo_results)
+type repoResultsDump struct {
+	Version int              `json:"version"`
+	Since   time.Time        `json:"since"`
+	Until   time.Time        `json:"until"`
+	Results []repoResultDump `json:"results"`
+}
+
+// repoResultDump is a RepoResult with its error, which does not serialize, as text
+type repoResultDump struct {
+	RepoResult
+	Error string `json:"error,omitempty"`
+}
+
+// dumpRepoResults writes the processed repositories and their time window to path
+func dumpRepoResults(path string, results []RepoResult, since, until time.Time) error {
+	dump := repoResultsDump{
+		Version: repoResultsVersion,
+		Since:   since,
+		Until:   until,
+		Results: make([]repoResultDump, len(results)),
+	}
+	for i, result := range results {
+		dump.Results[i] = repoResultDump{RepoResult: result}
+		if result.Err != nil {
+			dump.Results[i].Error = result.Err.Error()
+		}
+	}
+
+	data, err := json.Marshal(dump)
+	if err != nil {
+		return fmt.Errorf("failed to marshal repo results: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for repo results: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write repo results: %w", err)
+	}
+	return nil
+}
+
+// loadRepoResults reads processed repositories written by dumpRepoResults,
+// with the time window they were processed for. Cached owning teams are
+// dropped so PRs are attributed again by the current logic.
+func loadRepoResults(path string) (results []RepoResult, since, until time.Time, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("failed to read repo results: %w", err)
+	}
+
+	var dump repoResultsDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("failed to parse repo results: %w", err)
+	}
+	if dump.Version != repoResultsVersion {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("unsupported repo results version %d (expected %d)", dump.Version, repoResultsVersion)
+	}
+
+	results = make([]RepoResult, len(dump.Results))
+	for i, result := range dump.Results {
+		results[i] = result.RepoResult
+		if result.Error != "" {
+			results[i].Err = errors.New(result.Error)
+		}
+		results[i].PRTeams = nil
+		results[i].PRTeamsKey = ""
+	}
+	return results, dump.Since, dump.Until, nil
+}
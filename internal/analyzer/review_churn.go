@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// fetchPRReviewEvents fetches the review events of each PR's timeline (checking
+// the cache first). PRs whose events could not be loaded are absent from the
+// returned map.
+func (a *Analyzer) fetchPRReviewEvents(ctx context.Context, owner, repo string, prs []*github.PullRequest) map[int][]*github.Timeline {
+	events := make(map[int][]*github.Timeline)
+	for _, pr := range prs {
+		prNumber := pr.GetNumber()
+
+		if a.cache != nil {
+			cachedEvents, err := a.cache.GetPRReviewEvents(ctx, owner, repo, prNumber)
+			if err == nil {
+				events[prNumber] = cachedEvents
+				continue
+			}
+		}
+
+		if a.skipAPICalls {
+			a.logger.Debug("Skipping PR review events fetch (cache-only mode)",
+				zap.Int("pr_number", prNumber),
+			)
+			continue
+		}
+
+		fetched, err := a.fetchOnce(fmt.Sprintf("review_events:%s/%s#%d", owner, repo, prNumber), func() (interface{}, error) {
+			prEvents, err := a.prFetcher.FetchPRReviewEvents(ctx, owner, repo, prNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			// Cache PR review events
+			if a.cache != nil {
+				if err := a.cache.SetPRReviewEvents(ctx, owner, repo, prNumber, prEvents); err != nil {
+					a.logger.Warn("Failed to cache PR review events", zap.Error(err))
+				}
+			}
+			return prEvents, nil
+		})
+		if err != nil {
+			a.logger.Debug("Failed to fetch PR review events",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+				zap.Int("pr_number", prNumber),
+				zap.Error(err),
+			)
+			a.events.record(severityWarning, owner+"/"+repo, prNumber, fmt.Sprintf("failed to fetch PR review events: %v", err))
+			continue
+		}
+		events[prNumber] = fetched.([]*github.Timeline)
+	}
+
+	return events
+}
+
+// reviewChurn counts the times a PR bounced between author and reviewer: each
+// dismissed review, and each re-request of a reviewer or team that was already
+// requested or has already reviewed (which includes every reviewer whose
+// review was dismissed). The first request of each reviewer is not churn, even
+// when it follows others' reviews. Events must be in timeline (chronological)
+// order.
+func reviewChurn(events []*github.Timeline) int {
+	churn := 0
+	seen := make(map[string]bool) // Reviewers requested or having reviewed, and requested teams
+	for _, event := range events {
+		switch event.GetEvent() {
+		case "reviewed":
+			if login := event.GetUser().GetLogin(); login != "" {
+				seen[strings.ToLower(login)] = true
+			}
+		case "review_dismissed":
+			churn++
+		case "review_requested":
+			key := strings.ToLower(event.GetReviewer().GetLogin())
+			if key == "" {
+				key = "team:" + strings.ToLower(event.GetRequestedTeam().GetSlug())
+			}
+			if seen[key] {
+				churn++
+			}
+			seen[key] = true
+		}
+	}
+	return churn
+}
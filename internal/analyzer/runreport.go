@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+)
+
+// Severities of run report events
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// runEvents accumulates the errors and warnings of a run for run_report.json.
+// Repos are processed concurrently, so recording is synchronized.
+type runEvents struct {
+	mu     sync.Mutex
+	events []exporter.RunEvent
+}
+
+// record adds an event; repo and prNumber may be empty when not applicable
+func (r *runEvents) record(severity, repo string, prNumber int, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, exporter.RunEvent{
+		Time:     time.Now(),
+		Severity: severity,
+		Repo:     repo,
+		PRNumber: prNumber,
+		Message:  message,
+	})
+}
+
+// report summarizes the recorded events
+func (r *runEvents) report() *exporter.RunReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &exporter.RunReport{Events: append([]exporter.RunEvent{}, r.events...)}
+	for _, event := range r.events {
+		switch event.Severity {
+		case severityError:
+			report.Errors++
+		case severityWarning:
+			report.Warnings++
+		}
+	}
+	return report
+}
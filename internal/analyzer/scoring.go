@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/google/go-github/v62/github"
+)
+
+// prScore scores a PR as the sum of the scores of every scoring rule it
+// matches, or the default score when it matches none
+func prScore(pr *github.PullRequest, scoring config.ScoringConfig) float64 {
+	score, matched := 0.0, false
+	for _, rule := range scoring.Rules {
+		if matchesScoringRule(pr, rule) {
+			score += rule.Score
+			matched = true
+		}
+	}
+	if !matched {
+		return scoring.Default
+	}
+	return score
+}
+
+// matchesScoringRule reports whether a PR meets all conditions of a rule. Size
+// conditions never match PRs whose size is unknown (fetch.pr_details disabled).
+func matchesScoringRule(pr *github.PullRequest, rule config.ScoringRuleConfig) bool {
+	if rule.Label != "" && !hasLabel(pr, rule.Label) {
+		return false
+	}
+	if rule.TitlePrefix != "" && !strings.HasPrefix(strings.ToLower(pr.GetTitle()), strings.ToLower(rule.TitlePrefix)) {
+		return false
+	}
+	if rule.MinLines > 0 || rule.MaxLines > 0 {
+		if !hasPRDetails(pr) {
+			return false
+		}
+		lines := pr.GetAdditions() + pr.GetDeletions()
+		if rule.MinLines > 0 && lines < rule.MinLines {
+			return false
+		}
+		if rule.MaxLines > 0 && lines > rule.MaxLines {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLabel reports whether a PR carries a label (case-insensitive)
+func hasLabel(pr *github.PullRequest, name string) bool {
+	for _, label := range pr.Labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fishnix/ghpr-analyzer/internal/fetcher"
+	"github.com/google/go-github/v62/github"
+)
+
+// PRTrace is the step-by-step attribution of a single PR
+type PRTrace struct {
+	PR               *github.PullRequest
+	CODEOWNERSPath   string // Empty when the repository has no CODEOWNERS file
+	Files            []FileTrace
+	Owners           []string // Owners of all changed files
+	AttributedOwners []string // Owners left after the attribution mode
+	Teams            []string // Team buckets the PR is counted under
+}
+
+// FileTrace is the CODEOWNERS resolution of one changed file
+type FileTrace struct {
+	Filename string
+	Rule     *fetcher.CODEOWNERSRule // Most specific matching rule, nil if none matched
+}
+
+// TracePR fetches a single PR, its files and the repository's CODEOWNERS from
+// the API and records each attribution step. The cache is bypassed so the trace
+// always reflects the current state on GitHub (except for the shared CODEOWNERS
+// of codeowners.source_repo, used when the repository has none of its own, and
+// local CODEOWNERS files, which replace the repository's).
+func (a *Analyzer) TracePR(ctx context.Context, owner, repo string, number int) (*PRTrace, error) {
+	pr, err := a.prFetcher.FetchPRDetails(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	codeowners := a.local.forRepo(owner, repo)
+	atMerge := false
+	if codeowners == nil {
+		// An oversized file counts as none, as it does in the analysis
+		codeowners, _, err = a.codeownersFetcher.FetchCODEOWNERS(ctx, owner, repo)
+		if err != nil && !errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+			return nil, fmt.Errorf("failed to fetch CODEOWNERS: %w", err)
+		}
+		// With attribution.point_in_time, the file as of the merge commit
+		// (which may have none) replaces the repository's current one
+		if codeowners != nil && a.cfg.Attribution.PointInTime && pr.MergedAt != nil && pr.GetMergeCommitSHA() != "" {
+			codeowners, _, err = a.codeownersFetcher.FetchCODEOWNERSAtRef(ctx, owner, repo, pr.GetMergeCommitSHA())
+			if err != nil && !errors.Is(err, fetcher.ErrCODEOWNERSTooLarge) {
+				return nil, fmt.Errorf("failed to fetch CODEOWNERS at merge commit: %w", err)
+			}
+			atMerge = true
+		}
+	}
+	if codeowners == nil && !atMerge {
+		codeowners = a.sharedCODEOWNERS(ctx)
+	}
+	codeowners = a.withoutCatchAllRules(codeowners)
+
+	trace := &PRTrace{PR: pr}
+
+	var prFiles []*github.CommitFile
+	if codeowners != nil {
+		trace.CODEOWNERSPath = codeowners.Path
+
+		prFiles, err = a.prFetcher.FetchPRFiles(ctx, owner, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch PR files: %w", err)
+		}
+		prFiles = a.withoutExcludedPaths(owner+"/"+repo, prFiles)
+		for _, file := range prFiles {
+			trace.Files = append(trace.Files, FileTrace{
+				Filename: file.GetFilename(),
+				Rule:     codeowners.FindRule(file.GetFilename()),
+			})
+		}
+	}
+
+	trace.Owners = ownersForFiles(codeowners, prFiles)
+	trace.AttributedOwners = a.applyAttributionMode(owner+"/"+repo, trace.Owners)
+	trace.Teams = a.teamsForOwners(trace.AttributedOwners, codeowners != nil)
+
+	return trace, nil
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// WarmCache fetches everything an analysis of the configured time window needs
+// (repositories, CODEOWNERS, PRs and, as enabled, their files, details, reviews
+// and commits, and releases) into the cache without aggregating or exporting anything, so a
+// later analyze can run with --skip-api-calls
+func (a *Analyzer) WarmCache(ctx context.Context) error {
+	if a.cache == nil {
+		return fmt.Errorf("cache backend not configured, nothing to warm")
+	}
+
+	since, until, err := a.cfg.GetTimeWindow()
+	if err != nil {
+		return fmt.Errorf("failed to get time window: %w", err)
+	}
+
+	repos, err := a.enumerateRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.logger.Info("Warming cache", zap.Int("repos", len(repos)))
+	results := a.processRepos(ctx, repos, since, until, nil)
+
+	// Close cache
+	if err := a.cache.Close(); err != nil {
+		a.logger.Warn("Failed to close cache", zap.Error(err))
+	}
+
+	// Count the entities now in the cache
+	var codeowners, prs, prFiles, reviews, reviewEvents, commits, releases, failed int
+	for _, result := range results {
+		if result.Err != nil {
+			a.logger.Warn("Failed to warm repository",
+				zap.String("repo", fmt.Sprintf("%s/%s", result.Repo.GetOwner().GetLogin(), result.Repo.GetName())),
+				zap.Error(result.Err),
+			)
+			failed++
+			continue
+		}
+		if result.CODEOWNERS != nil {
+			codeowners++
+		}
+		prs += len(result.PRs)
+		prFiles += len(result.Files)
+		reviews += len(result.Reviews)
+		reviewEvents += len(result.ReviewEvents)
+		commits += len(result.Commits)
+		releases += len(result.Releases)
+	}
+
+	a.logger.Info("Cache warming complete",
+		zap.Int("repos", len(repos)),
+		zap.Int("repos_failed", failed),
+		zap.Int("codeowners", codeowners),
+		zap.Int("prs", prs),
+		zap.Int("pr_files", prFiles),
+		zap.Int("pr_reviews", reviews),
+		zap.Int("pr_review_events", reviewEvents),
+		zap.Int("pr_commits", commits),
+		zap.Int("releases", releases),
+	)
+	return nil
+}
@@ -1,27 +1,40 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"time"
 
+	"github.com/fishnix/ghpr-analyzer/internal/config"
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
 )
 
 // Cache interface for different cache backends
 type Cache interface {
-	// GetRepos retrieves cached repositories
-	GetRepos(ctx context.Context, org string) ([]*github.Repository, error)
-	// SetRepos caches repositories
-	SetRepos(ctx context.Context, org string, repos []*github.Repository) error
+	// GetRepos retrieves cached repositories enumerated with the given filter signature
+	GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error)
+	// SetRepos caches repositories enumerated with the given filter signature
+	SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error
 
-	// GetCODEOWNERS retrieves cached CODEOWNERS file
+	// GetCODEOWNERS retrieves cached CODEOWNERS file, or AbsentCODEOWNERS for a
+	// repository cached as having none
 	GetCODEOWNERS(ctx context.Context, owner, repo string) ([]byte, error)
 	// SetCODEOWNERS caches CODEOWNERS file
 	SetCODEOWNERS(ctx context.Context, owner, repo string, content []byte) error
+	// GetParsedCODEOWNERS retrieves a serialized parsed CODEOWNERS file, if it
+	// was cached under hash (identifying the content it was parsed from)
+	GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error)
+	// SetParsedCODEOWNERS caches a serialized parsed CODEOWNERS file under hash
+	SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error
+	// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit,
+	// or AbsentCODEOWNERS if the repository had none at that commit
+	GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error)
+	// SetCODEOWNERSAtCommit caches the CODEOWNERS file as of a commit
+	SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error
 
-	// GetPRs retrieves cached PRs for a repository, filtered by time window
+	// GetPRs retrieves cached PRs for a repository closed in the half-open time window [since, until)
 	GetPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error)
 	// SetPRs caches PRs for a repository (stores individual PRs by ID)
 	SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error
@@ -31,6 +44,44 @@ type Cache interface {
 	// SetPRFiles caches PR files
 	SetPRFiles(ctx context.Context, owner, repo string, prNumber int, files []*github.CommitFile) error
 
+	// GetPRReviews retrieves cached PR reviews
+	GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error)
+	// SetPRReviews caches PR reviews
+	SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error
+
+	// GetPRCommits retrieves cached PR commits
+	GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error)
+	// SetPRCommits caches PR commits
+	SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error
+
+	// GetPRReviewEvents retrieves the cached review events of a PR's timeline
+	GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error)
+	// SetPRReviewEvents caches the review events of a PR's timeline
+	SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error
+
+	// GetFetchProgress retrieves the progress of an interrupted PR fetch
+	GetFetchProgress(ctx context.Context, owner, repo string) (*FetchProgress, error)
+	// SetFetchProgress records the progress of an in-flight PR fetch
+	SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error
+	// ClearFetchProgress removes the progress of a completed PR fetch
+	ClearFetchProgress(ctx context.Context, owner, repo string) error
+
+	// GetPRTeams retrieves the owning teams of a repository's PRs by PR number,
+	// if they were cached under key (identifying the attribution inputs)
+	GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error)
+	// SetPRTeams caches the owning teams of a repository's PRs under key
+	SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error
+
+	// GetReleases retrieves the cached releases of a repository
+	GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error)
+	// SetReleases caches the releases of a repository
+	SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error
+
+	// GetUser retrieves a cached user profile
+	GetUser(ctx context.Context, login string) (*github.User, error)
+	// SetUser caches a user profile
+	SetUser(ctx context.Context, login string, user *github.User) error
+
 	// Invalidate invalidates all cache entries
 	Invalidate(ctx context.Context) error
 	// InvalidateRepo invalidates cache for a specific repository
@@ -40,18 +91,91 @@ type Cache interface {
 	Close() error
 }
 
-// NewCache creates a new cache instance based on backend type
-func NewCache(backend, sqlitePath, jsonDir string, ttl time.Duration, ignoreTTL bool, logger *zap.Logger) (Cache, error) {
+// NewCache creates a new cache instance based on backend type.
+// A non-empty namespace isolates entries from other namespaces sharing the same backend.
+// With slimPRs set, PRs are cached in a reduced representation instead of the full object.
+// jsonLayout is the layout of per-PR entries of the JSON backend (JSONLayoutFiles or JSONLayoutNDJSON).
+func NewCache(backend, sqlitePath, jsonDir, jsonLayout, namespace string, ttl time.Duration, ignoreTTL, slimPRs bool, logger *zap.Logger) (Cache, error) {
 	switch backend {
 	case "sqlite":
-		return NewSQLiteCache(sqlitePath, ttl, ignoreTTL, logger)
+		return NewSQLiteCache(sqlitePath, namespace, ttl, ignoreTTL, slimPRs, logger)
 	case "json":
-		return NewJSONCache(jsonDir, ttl, ignoreTTL, logger)
+		return NewJSONCache(jsonDir, jsonLayout, namespace, ttl, ignoreTTL, slimPRs, logger)
+	case "memory":
+		return NewMemoryCache(ttl, ignoreTTL, slimPRs, logger), nil
 	default:
 		return nil, fmt.Errorf("unsupported cache backend: %s", backend)
 	}
 }
 
+// snapshotter is implemented by backends that can be read as of a point in time
+type snapshotter interface {
+	SetSnapshotAt(t time.Time)
+}
+
+// NewCacheFromConfig creates the cache described by the cache config, including
+// a tiered cache composed of the near and far backends. A zero TTL defaults to
+// 24 hours.
+func NewCacheFromConfig(cfg config.CacheConfig, ignoreTTL bool, logger *zap.Logger) (Cache, error) {
+	// Convert TTL from minutes to duration
+	ttl := time.Duration(cfg.TTLMinutes) * time.Minute
+	if ttl == 0 {
+		// Default to 24 hours if not set
+		ttl = 24 * time.Hour
+	}
+
+	var snapshotAt time.Time
+	if cfg.SnapshotAt != "" {
+		var err error
+		snapshotAt, err = time.Parse(time.RFC3339, cfg.SnapshotAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache.snapshot_at: %w", err)
+		}
+		// Entries age from when they were written to now, so a TTL would
+		// make the same snapshot read differently depending on the run date
+		ignoreTTL = true
+	}
+	newBackend := func(backend, sqlitePath, jsonDir string) (Cache, error) {
+		c, err := NewCache(backend, sqlitePath, jsonDir, cfg.JSONLayout, cfg.Namespace, ttl, ignoreTTL, cfg.SlimPRs, logger)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := c.(snapshotter); ok {
+			s.SetSnapshotAt(snapshotAt)
+		}
+		// A snapshot is only read, including by a tiered cache copying far hits
+		if !snapshotAt.IsZero() {
+			return NewReadOnlyCache(c), nil
+		}
+		return c, nil
+	}
+
+	if cfg.Backend != "tiered" {
+		return newBackend(cfg.Backend, cfg.SQLitePath, cfg.JSONDir)
+	}
+
+	near, err := newBackend(cfg.Near.Backend, cfg.Near.SQLitePath, cfg.Near.JSONDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize near cache: %w", err)
+	}
+	far, err := newBackend(cfg.Far.Backend, cfg.Far.SQLitePath, cfg.Far.JSONDir)
+	if err != nil {
+		near.Close()
+		return nil, fmt.Errorf("failed to initialize far cache: %w", err)
+	}
+	return NewTieredCache(near, far, logger), nil
+}
+
+// AbsentCODEOWNERS is cached in place of the content of a repository without a
+// CODEOWNERS file, so later runs skip looking for one until the entry expires.
+// A real CODEOWNERS file cannot start with a NUL byte.
+var AbsentCODEOWNERS = []byte("\x00absent")
+
+// IsAbsentCODEOWNERS reports whether cached CODEOWNERS content is the AbsentCODEOWNERS marker
+func IsAbsentCODEOWNERS(content []byte) bool {
+	return bytes.Equal(content, AbsentCODEOWNERS)
+}
+
 // CacheEntry represents a cached entry with metadata
 type CacheEntry struct {
 	Data      interface{} `json:"data"`
@@ -59,6 +183,23 @@ type CacheEntry struct {
 	TTL       time.Duration
 }
 
+// FetchProgress records how far a PR fetch for a time window got, so a fetch
+// interrupted by an API error can resume instead of starting over
+type FetchProgress struct {
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	LastPage int       `json:"last_page"` // Highest fully-fetched list page
+	// When the fetch started: PRs updated since then moved to the first
+	// pages of the list, ahead of the pages already fetched
+	StartedAt time.Time `json:"started_at"`
+}
+
+// newerThanSnapshot reports whether an entry written at timestamp is hidden by
+// the snapshot at snapshotAt (zero = no snapshot)
+func newerThanSnapshot(timestamp, snapshotAt time.Time) bool {
+	return !snapshotAt.IsZero() && timestamp.After(snapshotAt)
+}
+
 // IsExpired checks if a cache entry is expired
 func (e *CacheEntry) IsExpired(ttl time.Duration) bool {
 	if ttl == 0 {
@@ -66,4 +207,3 @@ func (e *CacheEntry) IsExpired(ttl time.Duration) bool {
 	}
 	return time.Since(e.Timestamp) > ttl
 }
-
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fishnix/ghpr-analyzer/internal/config"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// snapshotCache is a backend whose reads can be pinned to a point in time
+type snapshotCache interface {
+	Cache
+	SetSnapshotAt(t time.Time)
+}
+
+// backends returns a constructor per backend that must behave alike
+func backends(t *testing.T) map[string]func(ttl time.Duration) snapshotCache {
+	return map[string]func(ttl time.Duration) snapshotCache{
+		"memory": func(ttl time.Duration) snapshotCache {
+			return NewMemoryCache(ttl, false, false, zap.NewNop())
+		},
+		"sqlite": func(ttl time.Duration) snapshotCache {
+			c, err := NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"), "", ttl, false, false, zap.NewNop())
+			if err != nil {
+				t.Fatalf("NewSQLiteCache: %v", err)
+			}
+			t.Cleanup(func() { c.Close() })
+			return c
+		},
+	}
+}
+
+// closedPR returns PR number closed at closedAt
+func closedPR(number int, closedAt time.Time) *github.PullRequest {
+	return &github.PullRequest{
+		Number:   github.Int(number),
+		Title:    github.String("pr"),
+		State:    github.String("closed"),
+		ClosedAt: &github.Timestamp{Time: closedAt},
+	}
+}
+
+// prNumbers returns the sorted numbers of prs
+func prNumbers(prs []*github.PullRequest) []int {
+	numbers := make([]int, 0, len(prs))
+	for _, pr := range prs {
+		numbers = append(numbers, pr.GetNumber())
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+func TestBackendsFilterPRsByWindow(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, newCache := range backends(t) {
+		c := newCache(time.Hour)
+		prs := []*github.PullRequest{
+			closedPR(1, since.Add(-time.Second)),
+			closedPR(2, since),
+			closedPR(3, since.Add(15*24*time.Hour)),
+			closedPR(4, until.Add(-time.Second)),
+			closedPR(5, until),
+		}
+		if err := c.SetPRs(ctx, "myorg", "api", prs); err != nil {
+			t.Fatalf("%s: SetPRs: %v", name, err)
+		}
+
+		got, err := c.GetPRs(ctx, "myorg", "api", since, until)
+		if err != nil {
+			t.Fatalf("%s: GetPRs: %v", name, err)
+		}
+		if numbers := prNumbers(got); len(numbers) != 3 || numbers[0] != 2 || numbers[1] != 3 || numbers[2] != 4 {
+			t.Errorf("%s: GetPRs = %v, want PRs 2, 3 and 4 (since inclusive, until exclusive)", name, numbers)
+		}
+
+		if _, err := c.GetPRs(ctx, "myorg", "api", until.Add(time.Hour), until.Add(2*time.Hour)); err == nil {
+			t.Errorf("%s: expected a miss for a window without PRs", name)
+		}
+		if _, err := c.GetPRs(ctx, "myorg", "web", since, until); err == nil {
+			t.Errorf("%s: expected a miss for another repository", name)
+		}
+	}
+}
+
+func TestBackendsExpireEntries(t *testing.T) {
+	ctx := context.Background()
+	closedAt := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+
+	for name, newCache := range backends(t) {
+		c := newCache(10 * time.Millisecond)
+		if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(1, closedAt)}); err != nil {
+			t.Fatalf("%s: SetPRs: %v", name, err)
+		}
+		if err := c.SetPRFiles(ctx, "myorg", "api", 1, []*github.CommitFile{{Filename: github.String("main.go")}}); err != nil {
+			t.Fatalf("%s: SetPRFiles: %v", name, err)
+		}
+		if _, err := c.GetPRs(ctx, "myorg", "api", closedAt, closedAt.Add(time.Hour)); err != nil {
+			t.Fatalf("%s: GetPRs before the TTL = %v", name, err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		if _, err := c.GetPRs(ctx, "myorg", "api", closedAt, closedAt.Add(time.Hour)); err == nil || err.Error() != "cache entry expired" {
+			t.Errorf("%s: GetPRs after the TTL = %v, want cache entry expired", name, err)
+		}
+		if _, err := c.GetPRFiles(ctx, "myorg", "api", 1); err == nil {
+			t.Errorf("%s: expected GetPRFiles to miss after the TTL", name)
+		}
+	}
+}
+
+func TestBackendsHonorSnapshot(t *testing.T) {
+	ctx := context.Background()
+	closedAt := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	since, until := closedAt.Add(-time.Hour), closedAt.Add(time.Hour)
+
+	for name, newCache := range backends(t) {
+		c := newCache(time.Hour)
+		if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(1, closedAt)}); err != nil {
+			t.Fatalf("%s: SetPRs: %v", name, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		snapshot := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(2, closedAt)}); err != nil {
+			t.Fatalf("%s: SetPRs: %v", name, err)
+		}
+		if err := c.SetPRFiles(ctx, "myorg", "api", 2, []*github.CommitFile{{Filename: github.String("main.go")}}); err != nil {
+			t.Fatalf("%s: SetPRFiles: %v", name, err)
+		}
+
+		c.SetSnapshotAt(snapshot)
+		got, err := c.GetPRs(ctx, "myorg", "api", since, until)
+		if err != nil {
+			t.Fatalf("%s: GetPRs as of the snapshot: %v", name, err)
+		}
+		if numbers := prNumbers(got); len(numbers) != 1 || numbers[0] != 1 {
+			t.Errorf("%s: GetPRs as of the snapshot = %v, want PR 1 only", name, numbers)
+		}
+		if _, err := c.GetPRFiles(ctx, "myorg", "api", 2); err == nil {
+			t.Errorf("%s: expected files written after the snapshot to miss", name)
+		}
+
+		c.SetSnapshotAt(time.Time{})
+		got, err = c.GetPRs(ctx, "myorg", "api", since, until)
+		if err != nil {
+			t.Fatalf("%s: GetPRs without a snapshot: %v", name, err)
+		}
+		if numbers := prNumbers(got); len(numbers) != 2 {
+			t.Errorf("%s: GetPRs without a snapshot = %v, want PRs 1 and 2", name, numbers)
+		}
+	}
+}
+
+func TestSnapshotCacheIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.CacheConfig{Backend: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "cache.db")}
+	closedAt := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	since, until := closedAt.Add(-time.Hour), closedAt.Add(time.Hour)
+
+	c, err := NewCacheFromConfig(cfg, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(1, closedAt)}); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	snapshotCfg := cfg
+	snapshotCfg.SnapshotAt = time.Now().Add(time.Second).UTC().Format(time.RFC3339)
+	snapshot, err := NewCacheFromConfig(snapshotCfg, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(2, closedAt)}); err != nil {
+		t.Errorf("SetPRs on a snapshot = %v, want the write dropped", err)
+	}
+	if err := snapshot.Invalidate(ctx); err == nil {
+		t.Error("expected invalidating a snapshot to fail")
+	}
+	snapshot.Close()
+
+	c, err = NewCacheFromConfig(cfg, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	got, err := c.GetPRs(ctx, "myorg", "api", since, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers := prNumbers(got); len(numbers) != 1 || numbers[0] != 1 {
+		t.Errorf("GetPRs after writing to a snapshot = %v, want PR 1 only", numbers)
+	}
+}
+
+func TestSnapshotCacheIgnoresTTL(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.CacheConfig{Backend: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "cache.db"), TTLMinutes: 1}
+	closedAt := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+
+	// An entry written two hours ago, well past the one-minute TTL
+	sqlite, err := NewSQLiteCache(cfg.SQLitePath, "", time.Minute, false, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite.SetPRs(ctx, "myorg", "api", []*github.PullRequest{closedPR(1, closedAt)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.db.ExecContext(ctx, `UPDATE prs SET timestamp = ?`, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	sqlite.Close()
+
+	cfg.SnapshotAt = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	c, err := NewCacheFromConfig(cfg, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	got, err := c.GetPRs(ctx, "myorg", "api", closedAt.Add(-time.Hour), closedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetPRs as of a snapshot older than the TTL = %v, want PR 1", err)
+	}
+	if numbers := prNumbers(got); len(numbers) != 1 || numbers[0] != 1 {
+		t.Errorf("GetPRs as of the snapshot = %v, want PR 1", numbers)
+	}
+}
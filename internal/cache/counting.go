@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+)
+
+// CountingCache wraps a cache and counts the hits and misses of its reads per
+// entity type (repos, codeowners, prs, pr_files, ...). A read is a hit when
+// the wrapped cache returns something the caller uses: an error is a miss,
+// and so is an empty result of the entity types refetched when nothing is
+// cached (repos, prs, pr_files, codeowners_at_commit, pr_teams). Empty
+// reviews, commits, review events and releases are cached as such, so they
+// are hits.
+type CountingCache struct {
+	Cache
+	mu       sync.Mutex
+	entities map[string]*EntityStats
+}
+
+// EntityStats holds the hits and misses of the reads of one entity type
+type EntityStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRate returns the share of reads that hit, or 0 without reads
+func (s EntityStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewCountingCache wraps c to count its hits and misses
+func NewCountingCache(c Cache) *CountingCache {
+	return &CountingCache{Cache: c, entities: make(map[string]*EntityStats)}
+}
+
+// Stats returns the number of reads that hit and missed so far
+func (c *CountingCache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.entities {
+		hits += s.Hits
+		misses += s.Misses
+	}
+	return hits, misses
+}
+
+// EntityStats returns the hits and misses so far by entity type, for the
+// entity types read at least once
+func (c *CountingCache) EntityStats() map[string]EntityStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make(map[string]EntityStats, len(c.entities))
+	for entity, s := range c.entities {
+		stats[entity] = *s
+	}
+	return stats
+}
+
+// count records the outcome of a read of entity, a miss when it failed or
+// found nothing, and passes its error through
+func (c *CountingCache) count(entity string, err error, empty bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.entities[entity]
+	if !ok {
+		s = &EntityStats{}
+		c.entities[entity] = s
+	}
+	if err != nil || empty {
+		s.Misses++
+	} else {
+		s.Hits++
+	}
+	return err
+}
+
+// GetRepos retrieves cached repositories enumerated with the given filter signature
+func (c *CountingCache) GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error) {
+	repos, err := c.Cache.GetRepos(ctx, org, filter)
+	return repos, c.count("repos", err, len(repos) == 0)
+}
+
+// GetCODEOWNERS retrieves cached CODEOWNERS file
+func (c *CountingCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]byte, error) {
+	content, err := c.Cache.GetCODEOWNERS(ctx, owner, repo)
+	return content, c.count("codeowners", err, false)
+}
+
+// GetParsedCODEOWNERS retrieves a parsed CODEOWNERS file cached under hash
+func (c *CountingCache) GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error) {
+	parsed, err := c.Cache.GetParsedCODEOWNERS(ctx, owner, repo, hash)
+	return parsed, c.count("parsed_codeowners", err, false)
+}
+
+// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit
+func (c *CountingCache) GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error) {
+	content, err := c.Cache.GetCODEOWNERSAtCommit(ctx, owner, repo, sha)
+	return content, c.count("codeowners_at_commit", err, len(content) == 0)
+}
+
+// GetPRs retrieves cached PRs for a repository, filtered by time window
+func (c *CountingCache) GetPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	prs, err := c.Cache.GetPRs(ctx, owner, repo, since, until)
+	return prs, c.count("prs", err, len(prs) == 0)
+}
+
+// GetPRFiles retrieves cached PR files
+func (c *CountingCache) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
+	files, err := c.Cache.GetPRFiles(ctx, owner, repo, prNumber)
+	return files, c.count("pr_files", err, len(files) == 0)
+}
+
+// GetPRReviews retrieves cached PR reviews
+func (c *CountingCache) GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	reviews, err := c.Cache.GetPRReviews(ctx, owner, repo, prNumber)
+	return reviews, c.count("pr_reviews", err, false)
+}
+
+// GetPRCommits retrieves cached PR commits
+func (c *CountingCache) GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	commits, err := c.Cache.GetPRCommits(ctx, owner, repo, prNumber)
+	return commits, c.count("pr_commits", err, false)
+}
+
+// GetPRReviewEvents retrieves the cached review events of a PR's timeline
+func (c *CountingCache) GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	events, err := c.Cache.GetPRReviewEvents(ctx, owner, repo, prNumber)
+	return events, c.count("pr_review_events", err, false)
+}
+
+// GetPRTeams retrieves the owning teams of a repository's PRs cached under key
+func (c *CountingCache) GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error) {
+	teams, err := c.Cache.GetPRTeams(ctx, owner, repo, key)
+	return teams, c.count("pr_teams", err, len(teams) == 0)
+}
+
+// GetReleases retrieves the cached releases of a repository
+func (c *CountingCache) GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	releases, err := c.Cache.GetReleases(ctx, owner, repo)
+	return releases, c.count("releases", err, false)
+}
+
+// GetUser retrieves a cached user profile
+func (c *CountingCache) GetUser(ctx context.Context, login string) (*github.User, error) {
+	user, err := c.Cache.GetUser(ctx, login)
+	return user, c.count("users", err, false)
+}
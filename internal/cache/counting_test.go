@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+func TestCountingCacheCountsEmptyResultsAsMisses(t *testing.T) {
+	ctx := context.Background()
+	c := NewCountingCache(NewMemoryCache(time.Hour, false, false, zap.NewNop()))
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	// Nothing cached yet
+	c.GetPRs(ctx, "myorg", "api", since, until)
+
+	// Cached, but outside the window: nothing to use
+	outside := &github.PullRequest{Number: github.Int(1), ClosedAt: &github.Timestamp{Time: until.Add(time.Hour)}}
+	if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{outside}); err != nil {
+		t.Fatal(err)
+	}
+	c.GetPRs(ctx, "myorg", "api", since, until)
+
+	inside := &github.PullRequest{Number: github.Int(2), ClosedAt: &github.Timestamp{Time: since.Add(time.Hour)}}
+	if err := c.SetPRs(ctx, "myorg", "api", []*github.PullRequest{inside}); err != nil {
+		t.Fatal(err)
+	}
+	c.GetPRs(ctx, "myorg", "api", since, until)
+
+	// A PR without reviews is cached as such and used
+	if err := c.SetPRReviews(ctx, "myorg", "api", 2, []*github.PullRequestReview{}); err != nil {
+		t.Fatal(err)
+	}
+	c.GetPRReviews(ctx, "myorg", "api", 2)
+
+	stats := c.EntityStats()
+	if got, want := stats["prs"], (EntityStats{Hits: 1, Misses: 2}); got != want {
+		t.Errorf("prs stats = %+v, want %+v", got, want)
+	}
+	if got, want := stats["pr_reviews"], (EntityStats{Hits: 1}); got != want {
+		t.Errorf("pr_reviews stats = %+v, want %+v", got, want)
+	}
+	if hits, misses := c.Stats(); hits != 2 || misses != 2 {
+		t.Errorf("Stats() = %d hits, %d misses, want 2 and 2", hits, misses)
+	}
+}
@@ -6,38 +6,184 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
 )
 
+// JSON cache layouts of the per-PR entries (PRs, files, reviews, commits and
+// review events)
+const (
+	JSONLayoutFiles  = "files"  // One file per entry
+	JSONLayoutNDJSON = "ndjson" // One append-only ndjson file per repository and entity
+)
+
 // JSONCache implements cache using JSON files
 type JSONCache struct {
 	baseDir   string
+	layout    string
 	logger    *zap.Logger
 	ttl       time.Duration
 	ignoreTTL bool
+	slimPRs   bool
+	// Entries written after snapshotAt are treated as nonexistent (zero = no snapshot)
+	snapshotAt time.Time
+
+	// Open ndjson stores by path (ndjson layout only)
+	storesMu sync.Mutex
+	stores   map[string]*ndjsonStore
 }
 
-// NewJSONCache creates a new JSON file cache
-func NewJSONCache(baseDir string, ttl time.Duration, ignoreTTL bool, logger *zap.Logger) (*JSONCache, error) {
+// NewJSONCache creates a new JSON file cache storing per-PR entries in layout
+// (JSONLayoutFiles or JSONLayoutNDJSON). A non-empty namespace stores entries
+// under their own subdirectory of baseDir.
+func NewJSONCache(baseDir, layout, namespace string, ttl time.Duration, ignoreTTL, slimPRs bool, logger *zap.Logger) (*JSONCache, error) {
+	if namespace != "" {
+		baseDir = filepath.Join(baseDir, "namespaces", namespace)
+	}
+
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	return &JSONCache{
+	if layout == "" {
+		layout = JSONLayoutFiles
+	}
+	if layout != JSONLayoutFiles && layout != JSONLayoutNDJSON {
+		return nil, fmt.Errorf("unsupported JSON cache layout: %s", layout)
+	}
+
+	cache := &JSONCache{
 		baseDir:   baseDir,
+		layout:    layout,
 		logger:    logger,
 		ttl:       ttl,
 		ignoreTTL: ignoreTTL,
-	}, nil
+		slimPRs:   slimPRs,
+		stores:    make(map[string]*ndjsonStore),
+	}
+
+	// Convert PR files of the old layout
+	if err := cache.migrateDateRangePRFiles(); err != nil {
+		return nil, fmt.Errorf("failed to migrate PR cache layout: %w", err)
+	}
+
+	// Move per-PR files into ndjson stores
+	if layout == JSONLayoutNDJSON {
+		if err := cache.migrateToNDJSON(); err != nil {
+			return nil, fmt.Errorf("failed to migrate PR cache to the ndjson layout: %w", err)
+		}
+	}
+
+	return cache, nil
+}
+
+// dateRangePRFile matches the PR files of the old layout, one per fetched time
+// window (e.g. prs_20250101_20250131.json)
+var dateRangePRFile = regexp.MustCompile(`^prs_\d{8}_\d{8}\.json$`)
+
+// migrateDateRangePRFiles converts the PR files of the old layout into one file
+// per PR number, the JSON counterpart of the SQLite v1 to v2 migration. Each old
+// file is removed once converted, so an interrupted migration resumes with the
+// files left on the next start.
+func (c *JSONCache) migrateDateRangePRFiles() error {
+	oldFiles, err := filepath.Glob(filepath.Join(c.baseDir, "repos", "*", "*", "prs", "prs_*.json"))
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, path := range oldFiles {
+		if dateRangePRFile.MatchString(filepath.Base(path)) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	c.logger.Info("Migrating PR cache files from the date-range layout", zap.Int("files", len(paths)))
+	migratedPRs := 0
+	for i, path := range paths {
+		count, err := c.migrateDateRangePRFile(path)
+		if err != nil {
+			// Leave the file in place; GetPRs ignores it
+			c.logger.Warn("Failed to migrate PR cache file", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		migratedPRs += count
+		c.logger.Debug("Migrated PR cache file",
+			zap.String("path", path),
+			zap.Int("prs", count),
+			zap.Int("file", i+1),
+			zap.Int("files", len(paths)),
+		)
+	}
+	c.logger.Info("PR cache migration complete", zap.Int("files", len(paths)), zap.Int("prs", migratedPRs))
+	return nil
+}
+
+// migrateDateRangePRFile writes the PRs of an old-layout file to their own files,
+// keeping the file's timestamp so TTLs and snapshots still apply, then removes it.
+// A PR already cached at least as recently is left alone.
+func (c *JSONCache) migrateDateRangePRFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var entry struct {
+		Data      []*github.PullRequest `json:"data"`
+		Timestamp time.Time             `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return 0, err
+	}
+
+	prsDir := filepath.Dir(path)
+	count := 0
+	for _, pr := range entry.Data {
+		if pr == nil || pr.Number == nil {
+			continue
+		}
+		prPath := filepath.Join(prsDir, fmt.Sprintf("%d.json", pr.GetNumber()))
+		if c.cachedSince(prPath, entry.Timestamp) {
+			continue
+		}
+		if err := c.setJSONAt(prPath, encodePR(pr, c.slimPRs), entry.Timestamp); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, os.Remove(path)
 }
 
-// GetRepos retrieves cached repositories
-func (c *JSONCache) GetRepos(ctx context.Context, org string) ([]*github.Repository, error) {
-	path := filepath.Join(c.baseDir, "orgs", org, "repos.json")
+// cachedSince reports whether the cache file at path was written at or after t
+func (c *JSONCache) cachedSince(path string, t time.Time) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return false
+	}
+	return !entry.Timestamp.Before(t)
+}
+
+// SetSnapshotAt makes reads see the cache as of t: entries written after t are
+// treated as nonexistent. A zero t disables the snapshot.
+func (c *JSONCache) SetSnapshotAt(t time.Time) {
+	c.snapshotAt = t
+}
+
+// GetRepos retrieves cached repositories enumerated with the given filter signature
+func (c *JSONCache) GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error) {
+	path := filepath.Join(c.baseDir, "orgs", org, "repos", filter+".json")
 	var repos []*github.Repository
 	err := c.getJSON(path, &repos)
 	if err != nil {
@@ -46,9 +192,9 @@ func (c *JSONCache) GetRepos(ctx context.Context, org string) ([]*github.Reposit
 	return repos, nil
 }
 
-// SetRepos caches repositories
-func (c *JSONCache) SetRepos(ctx context.Context, org string, repos []*github.Repository) error {
-	path := filepath.Join(c.baseDir, "orgs", org, "repos.json")
+// SetRepos caches repositories enumerated with the given filter signature
+func (c *JSONCache) SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error {
+	path := filepath.Join(c.baseDir, "orgs", org, "repos", filter+".json")
 	return c.setJSON(path, repos)
 }
 
@@ -69,11 +215,57 @@ func (c *JSONCache) SetCODEOWNERS(ctx context.Context, owner, repo string, conte
 	return c.setJSON(path, content)
 }
 
+// parsedCODEOWNERSEntry is a parsed CODEOWNERS file with the hash it is cached under
+type parsedCODEOWNERSEntry struct {
+	Hash   string          `json:"hash"`
+	Parsed json.RawMessage `json:"parsed"`
+}
+
+// GetParsedCODEOWNERS retrieves a parsed CODEOWNERS file cached under hash
+func (c *JSONCache) GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error) {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "codeowners_parsed.json")
+	var entry parsedCODEOWNERSEntry
+	if err := c.getJSON(path, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Hash != hash {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	return entry.Parsed, nil
+}
+
+// SetParsedCODEOWNERS caches a parsed CODEOWNERS file under hash
+func (c *JSONCache) SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "codeowners_parsed.json")
+	return c.setJSON(path, parsedCODEOWNERSEntry{Hash: hash, Parsed: parsed})
+}
+
+// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit
+func (c *JSONCache) GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error) {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "codeowners_at", sha+".json")
+	var content []byte
+	err := c.getJSON(path, &content)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// SetCODEOWNERSAtCommit caches the CODEOWNERS file as of a commit
+func (c *JSONCache) SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "codeowners_at", sha+".json")
+	return c.setJSON(path, content)
+}
+
 // GetPRs retrieves cached PRs for a repository, filtered by time window
 func (c *JSONCache) GetPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	if c.layout == JSONLayoutNDJSON {
+		return c.getPRsNDJSON(owner, repo, since, until)
+	}
+
 	// Read all PR files for this repo
 	prsDir := filepath.Join(c.baseDir, "repos", owner, repo, "prs")
-	
+
 	// Check if directory exists
 	if _, err := os.Stat(prsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("cache entry not found")
@@ -94,13 +286,14 @@ func (c *JSONCache) GetPRs(ctx context.Context, owner, repo string, since, until
 		}
 
 		// Skip old format files (with date range in name like prs_20250101_20250131.json)
+		// that could not be migrated
 		if strings.HasPrefix(entry.Name(), "prs_") && strings.Count(entry.Name(), "_") >= 2 {
 			continue
 		}
 
 		path := filepath.Join(prsDir, entry.Name())
-		var pr github.PullRequest
-		err := c.getJSON(path, &pr)
+		var data json.RawMessage
+		err := c.getJSON(path, &data)
 		if err != nil {
 			// Check if it's expired
 			if strings.Contains(err.Error(), "expired") {
@@ -109,11 +302,18 @@ func (c *JSONCache) GetPRs(ctx context.Context, owner, repo string, since, until
 			continue
 		}
 
-		// Filter by time window
+		// Decode PR (full or slim representation)
+		pr, err := decodePR(data)
+		if err != nil {
+			c.logger.Warn("Failed to unmarshal PR data", zap.String("path", path), zap.Error(err))
+			continue
+		}
+
+		// Filter by the (half-open) time window
 		if pr.ClosedAt != nil {
 			closedAtTime := pr.ClosedAt.Time
-			if !closedAtTime.Before(since) && !closedAtTime.After(until) {
-				allPRs = append(allPRs, &pr)
+			if !closedAtTime.Before(since) && closedAtTime.Before(until) {
+				allPRs = append(allPRs, pr)
 			}
 		}
 	}
@@ -131,6 +331,10 @@ func (c *JSONCache) GetPRs(ctx context.Context, owner, repo string, since, until
 
 // SetPRs caches PRs for a repository (stores individual PRs by ID)
 func (c *JSONCache) SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
+	if c.layout == JSONLayoutNDJSON {
+		return c.setPRsNDJSON(owner, repo, prs)
+	}
+
 	prsDir := filepath.Join(c.baseDir, "repos", owner, repo, "prs")
 	if err := os.MkdirAll(prsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create PRs directory: %w", err)
@@ -142,7 +346,7 @@ func (c *JSONCache) SetPRs(ctx context.Context, owner, repo string, prs []*githu
 		}
 
 		path := filepath.Join(prsDir, fmt.Sprintf("%d.json", *pr.Number))
-		if err := c.setJSON(path, pr); err != nil {
+		if err := c.setJSON(path, encodePR(pr, c.slimPRs)); err != nil {
 			c.logger.Warn("Failed to cache PR", zap.Int("pr_number", *pr.Number), zap.Error(err))
 			continue
 		}
@@ -153,9 +357,8 @@ func (c *JSONCache) SetPRs(ctx context.Context, owner, repo string, prs []*githu
 
 // GetPRFiles retrieves cached PR files
 func (c *JSONCache) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
-	path := filepath.Join(c.baseDir, "repos", owner, repo, "prs", fmt.Sprintf("%d_files.json", prNumber))
 	var files []*github.CommitFile
-	err := c.getJSON(path, &files)
+	err := c.getPREntry(owner, repo, "files", prNumber, &files)
 	if err != nil {
 		return nil, err
 	}
@@ -164,24 +367,173 @@ func (c *JSONCache) GetPRFiles(ctx context.Context, owner, repo string, prNumber
 
 // SetPRFiles caches PR files
 func (c *JSONCache) SetPRFiles(ctx context.Context, owner, repo string, prNumber int, files []*github.CommitFile) error {
-	path := filepath.Join(c.baseDir, "repos", owner, repo, "prs", fmt.Sprintf("%d_files.json", prNumber))
-	return c.setJSON(path, files)
+	return c.setPREntry(owner, repo, "files", prNumber, files)
+}
+
+// GetPRReviews retrieves cached PR reviews
+func (c *JSONCache) GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	var reviews []*github.PullRequestReview
+	err := c.getPREntry(owner, repo, "reviews", prNumber, &reviews)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// SetPRReviews caches PR reviews
+func (c *JSONCache) SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error {
+	return c.setPREntry(owner, repo, "reviews", prNumber, reviews)
+}
+
+// GetPRCommits retrieves cached PR commits
+func (c *JSONCache) GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	var commits []*github.RepositoryCommit
+	err := c.getPREntry(owner, repo, "commits", prNumber, &commits)
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// SetPRCommits caches PR commits
+func (c *JSONCache) SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error {
+	return c.setPREntry(owner, repo, "commits", prNumber, commits)
+}
+
+// GetPRReviewEvents retrieves the cached review events of a PR's timeline
+func (c *JSONCache) GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	var events []*github.Timeline
+	err := c.getPREntry(owner, repo, "review_events", prNumber, &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SetPRReviewEvents caches the review events of a PR's timeline
+func (c *JSONCache) SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error {
+	return c.setPREntry(owner, repo, "review_events", prNumber, events)
+}
+
+// prTeamsEntry is the owning teams of a repository's PRs with the key they are cached under
+type prTeamsEntry struct {
+	Key   string           `json:"key"`
+	Teams map[int][]string `json:"teams"`
+}
+
+// GetPRTeams retrieves the owning teams of a repository's PRs cached under key
+func (c *JSONCache) GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error) {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "pr_teams.json")
+	var entry prTeamsEntry
+	if err := c.getJSON(path, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Key != key {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	return entry.Teams, nil
+}
+
+// SetPRTeams caches the owning teams of a repository's PRs under key
+func (c *JSONCache) SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "pr_teams.json")
+	return c.setJSON(path, prTeamsEntry{Key: key, Teams: teams})
+}
+
+// GetReleases retrieves the cached releases of a repository
+func (c *JSONCache) GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "releases.json")
+	var releases []*github.RepositoryRelease
+	err := c.getJSON(path, &releases)
+	if err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// SetReleases caches the releases of a repository
+func (c *JSONCache) SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "releases.json")
+	return c.setJSON(path, releases)
+}
+
+// GetFetchProgress retrieves the progress of an interrupted PR fetch
+func (c *JSONCache) GetFetchProgress(ctx context.Context, owner, repo string) (*FetchProgress, error) {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "fetch_progress.json")
+	var progress FetchProgress
+	err := c.getJSON(path, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// SetFetchProgress records the progress of an in-flight PR fetch
+func (c *JSONCache) SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "fetch_progress.json")
+	return c.setJSON(path, progress)
 }
 
-// Invalidate invalidates all cache entries
+// ClearFetchProgress removes the progress of a completed PR fetch
+func (c *JSONCache) ClearFetchProgress(ctx context.Context, owner, repo string) error {
+	path := filepath.Join(c.baseDir, "repos", owner, repo, "fetch_progress.json")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove fetch progress: %w", err)
+	}
+	return nil
+}
+
+// GetUser retrieves a cached user profile
+func (c *JSONCache) GetUser(ctx context.Context, login string) (*github.User, error) {
+	path := filepath.Join(c.baseDir, "users", login+".json")
+	var user github.User
+	err := c.getJSON(path, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUser caches a user profile
+func (c *JSONCache) SetUser(ctx context.Context, login string, user *github.User) error {
+	path := filepath.Join(c.baseDir, "users", login+".json")
+	return c.setJSON(path, user)
+}
+
+// Invalidate invalidates all cache entries in the cache's namespace
 func (c *JSONCache) Invalidate(ctx context.Context) error {
-	return os.RemoveAll(c.baseDir)
+	c.dropStores(c.baseDir)
+
+	// Only remove entry directories so other namespaces nested in baseDir survive
+	for _, dir := range []string{"orgs", "repos", "users"} {
+		if err := os.RemoveAll(filepath.Join(c.baseDir, dir)); err != nil {
+			return fmt.Errorf("failed to invalidate %s: %w", dir, err)
+		}
+	}
+	return nil
 }
 
 // InvalidateRepo invalidates cache for a specific repository
 func (c *JSONCache) InvalidateRepo(ctx context.Context, owner, repo string) error {
 	path := filepath.Join(c.baseDir, "repos", owner, repo)
+	c.dropStores(path)
 	return os.RemoveAll(path)
 }
 
-// Close closes the cache
+// Close closes the cache, saving the indexes of the ndjson stores and
+// releasing their locks
 func (c *JSONCache) Close() error {
-	return nil
+	c.storesMu.Lock()
+	defer c.storesMu.Unlock()
+
+	var firstErr error
+	for path, store := range c.stores {
+		if err := store.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stores, path)
+	}
+	return firstErr
 }
 
 // getJSON retrieves JSON data from cache
@@ -203,12 +555,8 @@ func (c *JSONCache) getJSON(path string, result interface{}) error {
 		return fmt.Errorf("failed to unmarshal cache entry: %w", err)
 	}
 
-	// Check expiration (unless ignoreTTL is set)
-	if !c.ignoreTTL {
-		if entry.IsExpired(c.ttl) {
-			c.logger.Debug("Cache entry expired", zap.String("path", path))
-			return fmt.Errorf("cache entry expired")
-		}
+	if err := c.checkEntry(path, entry); err != nil {
+		return err
 	}
 
 	// Unmarshal data
@@ -224,8 +572,31 @@ func (c *JSONCache) getJSON(path string, result interface{}) error {
 	return nil
 }
 
+// checkEntry returns the error of reading an entry: not found when it was
+// written after the snapshot, expired when past the TTL (unless ignored)
+func (c *JSONCache) checkEntry(path string, entry CacheEntry) error {
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(entry.Timestamp, c.snapshotAt) {
+		return fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		if entry.IsExpired(c.ttl) {
+			c.logger.Debug("Cache entry expired", zap.String("path", path))
+			return fmt.Errorf("cache entry expired")
+		}
+	}
+	return nil
+}
+
 // setJSON stores JSON data in cache
 func (c *JSONCache) setJSON(path string, data interface{}) error {
+	return c.setJSONAt(path, data, time.Now())
+}
+
+// setJSONAt stores JSON data in cache as written at timestamp
+func (c *JSONCache) setJSONAt(path string, data interface{}, timestamp time.Time) error {
 	// Create directory if needed
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -235,7 +606,7 @@ func (c *JSONCache) setJSON(path string, data interface{}) error {
 	// Create cache entry
 	entry := CacheEntry{
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: timestamp,
 	}
 
 	// Marshal entry
@@ -252,3 +623,254 @@ func (c *JSONCache) setJSON(path string, data interface{}) error {
 	return nil
 }
 
+// prEntryFile matches the per-PR files of the files layout: the PR itself
+// (42.json) and its files, reviews, commits and review events (42_files.json)
+var prEntryFile = regexp.MustCompile(`^(\d+)(?:_(files|reviews|commits|review_events))?\.json$`)
+
+// getPREntry retrieves a per-PR entry of kind ("files", "reviews", "commits" or
+// "review_events") in the cache's layout
+func (c *JSONCache) getPREntry(owner, repo, kind string, prNumber int, result interface{}) error {
+	if c.layout != JSONLayoutNDJSON {
+		path := filepath.Join(c.baseDir, "repos", owner, repo, "prs", fmt.Sprintf("%d_%s.json", prNumber, kind))
+		return c.getJSON(path, result)
+	}
+
+	store, err := c.store(owner, repo, kind)
+	if err != nil {
+		return err
+	}
+	line, ok, err := store.get(prNumber)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("cache entry not found")
+	}
+	if err := c.checkEntry(store.path, CacheEntry{Timestamp: line.Timestamp}); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(line.Data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal cache data: %w", err)
+	}
+	return nil
+}
+
+// setPREntry caches a per-PR entry of kind in the cache's layout
+func (c *JSONCache) setPREntry(owner, repo, kind string, prNumber int, data interface{}) error {
+	if c.layout != JSONLayoutNDJSON {
+		path := filepath.Join(c.baseDir, "repos", owner, repo, "prs", fmt.Sprintf("%d_%s.json", prNumber, kind))
+		return c.setJSON(path, data)
+	}
+
+	store, err := c.store(owner, repo, kind)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+	return store.append(ndjsonLine{Key: prNumber, Timestamp: time.Now(), Data: raw})
+}
+
+// getPRsNDJSON is GetPRs for the ndjson layout
+func (c *JSONCache) getPRsNDJSON(owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	store, err := c.store(owner, repo, "")
+	if err != nil {
+		return nil, err
+	}
+	lines, err := store.all()
+	if err != nil {
+		return nil, err
+	}
+
+	var allPRs []*github.PullRequest
+	var hasExpiredEntries bool
+	for _, line := range lines {
+		if err := c.checkEntry(store.path, CacheEntry{Timestamp: line.Timestamp}); err != nil {
+			if strings.Contains(err.Error(), "expired") {
+				hasExpiredEntries = true
+			}
+			continue
+		}
+
+		pr, err := decodePR(line.Data)
+		if err != nil {
+			c.logger.Warn("Failed to unmarshal PR data", zap.String("path", store.path), zap.Int("pr_number", line.Key), zap.Error(err))
+			continue
+		}
+
+		// Filter by the (half-open) time window
+		if pr.ClosedAt != nil && !pr.ClosedAt.Time.Before(since) && pr.ClosedAt.Time.Before(until) {
+			allPRs = append(allPRs, pr)
+		}
+	}
+
+	if len(allPRs) == 0 && hasExpiredEntries {
+		return nil, fmt.Errorf("cache entry expired")
+	}
+	if len(allPRs) == 0 {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	return allPRs, nil
+}
+
+// setPRsNDJSON is SetPRs for the ndjson layout, appending the PRs in one write
+func (c *JSONCache) setPRsNDJSON(owner, repo string, prs []*github.PullRequest) error {
+	store, err := c.store(owner, repo, "")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	lines := make([]ndjsonLine, 0, len(prs))
+	for _, pr := range prs {
+		if pr.Number == nil {
+			continue
+		}
+		raw, err := json.Marshal(encodePR(pr, c.slimPRs))
+		if err != nil {
+			c.logger.Warn("Failed to cache PR", zap.Int("pr_number", *pr.Number), zap.Error(err))
+			continue
+		}
+		lines = append(lines, ndjsonLine{Key: pr.GetNumber(), Timestamp: now, Data: raw})
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return store.append(lines...)
+}
+
+// store returns the ndjson store of a repository's per-PR entries of kind (""
+// for the PRs themselves), opening it on first use
+func (c *JSONCache) store(owner, repo, kind string) (*ndjsonStore, error) {
+	name := "prs.ndjson"
+	if kind != "" {
+		name = "pr_" + kind + ".ndjson"
+	}
+	dir := filepath.Join(c.baseDir, "repos", owner, repo)
+	path := filepath.Join(dir, name)
+
+	c.storesMu.Lock()
+	defer c.storesMu.Unlock()
+	if store, ok := c.stores[path]; ok {
+		return store, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	store, err := openNDJSONStore(path)
+	if err != nil {
+		return nil, err
+	}
+	c.stores[path] = store
+	return store, nil
+}
+
+// dropStores forgets the open ndjson stores under dir, whose files are about
+// to be removed
+func (c *JSONCache) dropStores(dir string) {
+	c.storesMu.Lock()
+	defer c.storesMu.Unlock()
+	for path, store := range c.stores {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			store.release()
+			delete(c.stores, path)
+		}
+	}
+}
+
+// migrateToNDJSON moves the per-PR files of the files layout into the ndjson
+// stores of their repositories, keeping their timestamps, then removes each
+// repository's prs directory. Entries already in a store are left alone.
+func (c *JSONCache) migrateToNDJSON() error {
+	prsDirs, err := filepath.Glob(filepath.Join(c.baseDir, "repos", "*", "*", "prs"))
+	if err != nil {
+		return err
+	}
+	if len(prsDirs) == 0 {
+		return nil
+	}
+
+	c.logger.Info("Migrating PR cache files to the ndjson layout", zap.Int("repos", len(prsDirs)))
+	migrated := 0
+	for _, prsDir := range prsDirs {
+		count, err := c.migrateRepoToNDJSON(prsDir)
+		if err != nil {
+			// Leave the directory in place; it is retried on the next start
+			c.logger.Warn("Failed to migrate PR cache files", zap.String("path", prsDir), zap.Error(err))
+			continue
+		}
+		migrated += count
+	}
+	c.logger.Info("PR cache migration complete", zap.Int("repos", len(prsDirs)), zap.Int("entries", migrated))
+
+	// Save the indexes so the next start does not rescan the stores
+	c.storesMu.Lock()
+	defer c.storesMu.Unlock()
+	for _, store := range c.stores {
+		if err := store.saveIndex(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateRepoToNDJSON moves the per-PR files of a repository's prs directory
+// into its ndjson stores and removes the directory
+func (c *JSONCache) migrateRepoToNDJSON(prsDir string) (int, error) {
+	entries, err := os.ReadDir(prsDir)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := make(map[string][]ndjsonLine)
+	for _, entry := range entries {
+		match := prEntryFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		prNumber, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(prsDir, entry.Name()))
+		if err != nil {
+			return 0, err
+		}
+		var cached struct {
+			Data      json.RawMessage `json:"data"`
+			Timestamp time.Time       `json:"timestamp"`
+		}
+		if err := json.Unmarshal(data, &cached); err != nil {
+			c.logger.Warn("Skipping unreadable PR cache file", zap.String("path", filepath.Join(prsDir, entry.Name())), zap.Error(err))
+			continue
+		}
+		lines[match[2]] = append(lines[match[2]], ndjsonLine{Key: prNumber, Timestamp: cached.Timestamp, Data: cached.Data})
+	}
+
+	repoDir := filepath.Dir(prsDir)
+	owner, repo := filepath.Base(filepath.Dir(repoDir)), filepath.Base(repoDir)
+	count := 0
+	for kind, kindLines := range lines {
+		store, err := c.store(owner, repo, kind)
+		if err != nil {
+			return count, err
+		}
+		var missing []ndjsonLine
+		for _, line := range kindLines {
+			if _, ok, _ := store.get(line.Key); !ok {
+				missing = append(missing, line)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if err := store.append(missing...); err != nil {
+			return count, err
+		}
+		count += len(missing)
+	}
+
+	return count, os.RemoveAll(prsDir)
+}
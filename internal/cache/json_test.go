@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// dateRangePRsFixture is a PR cache file of the date-range layout, as older
+// versions wrote one per time window to repos/<owner>/<repo>/prs
+const dateRangePRsFixture = `{
+	"data": [
+		{"number": 1, "title": "one", "state": "closed", "closed_at": "2025-09-10T12:00:00Z"},
+		{"number": 2, "title": "two (stale)", "state": "closed", "closed_at": "2025-09-20T12:00:00Z"},
+		{"number": 3, "title": "three", "state": "closed", "closed_at": "2025-09-30T12:00:00Z"}
+	],
+	"timestamp": "%s"
+}`
+
+func TestJSONCacheMigratesDateRangePRFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	prsDir := filepath.Join(dir, "repos", "myorg", "api", "prs")
+	if err := os.MkdirAll(prsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	written := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	oldFile := filepath.Join(prsDir, "prs_20250901_20251001.json")
+	fixture := []byte(fmt.Sprintf(dateRangePRsFixture, written.Format(time.RFC3339)))
+	if err := os.WriteFile(oldFile, fixture, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// PR 2 was cached again, more recently, in the per-PR layout
+	newer := []byte(fmt.Sprintf(`{"data": {"number": 2, "title": "two", "state": "closed", "closed_at": "2025-09-20T12:00:00Z"}, "timestamp": %q}`,
+		time.Now().UTC().Format(time.RFC3339Nano)))
+	if err := os.WriteFile(filepath.Join(prsDir, "2.json"), newer, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewJSONCache(dir, JSONLayoutFiles, "", 24*time.Hour, false, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("expected the date-range file to be removed after migration, stat: %v", err)
+	}
+
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	prs, err := c.GetPRs(ctx, "myorg", "api", since, since.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("GetPRs after migration: %v", err)
+	}
+	titles := make(map[int]string)
+	for _, pr := range prs {
+		titles[pr.GetNumber()] = pr.GetTitle()
+	}
+	if len(titles) != 3 || titles[1] != "one" || titles[2] != "two" || titles[3] != "three" {
+		t.Errorf("GetPRs after migration = %v, want one, two (the newer entry) and three", titles)
+	}
+
+	// Migrated entries keep the time the old file was written
+	data, err := os.ReadFile(filepath.Join(prsDir, "1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Timestamp.Equal(written) {
+		t.Errorf("migrated entry timestamp = %v, want %v", entry.Timestamp, written)
+	}
+}
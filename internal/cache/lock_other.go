@@ -0,0 +1,19 @@
+//go:build !unix
+
+package cache
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile opens the file at path, creating it if needed. File locks are only
+// taken on unix; elsewhere a cache directory must not be shared by processes
+// running at the same time.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return file, nil
+}
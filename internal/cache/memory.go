@@ -0,0 +1,517 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// memoryEntry is a cached value, serialized like the SQLite backend stores it
+// so callers never share objects with the cache, with the time it was written
+type memoryEntry struct {
+	data      []byte
+	timestamp time.Time
+}
+
+// memoryKeyedEntry is an entry only valid under the key (hash of the CODEOWNERS
+// content, attribution inputs) it was cached with
+type memoryKeyedEntry struct {
+	memoryEntry
+	key string
+}
+
+// memoryRepo holds the entries of one repository
+type memoryRepo struct {
+	codeowners         *memoryEntry
+	parsedCODEOWNERS   *memoryKeyedEntry
+	codeownersAtCommit map[string]memoryEntry
+	prs                map[int]memoryEntry
+	prFiles            map[int]memoryEntry
+	prReviews          map[int]memoryEntry
+	prCommits          map[int]memoryEntry
+	prReviewEvents     map[int]memoryEntry
+	prTeams            *memoryKeyedEntry
+	releases           *memoryEntry
+	fetchProgress      *memoryEntry
+}
+
+// MemoryCache implements cache with maps held in memory, for tests and
+// one-shot runs that should leave no cache file behind. Entries live as long
+// as the process, so namespaces are not needed to isolate them.
+type MemoryCache struct {
+	mu        sync.RWMutex
+	logger    *zap.Logger
+	ttl       time.Duration
+	ignoreTTL bool
+	slimPRs   bool
+	// Entries written after snapshotAt are treated as nonexistent (zero = no snapshot)
+	snapshotAt time.Time
+
+	orgRepos map[string]memoryEntry // Enumerated repositories by org and filter signature
+	repos    map[string]*memoryRepo // By owner/repo
+	users    map[string]memoryEntry // By login
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache(ttl time.Duration, ignoreTTL, slimPRs bool, logger *zap.Logger) *MemoryCache {
+	return &MemoryCache{
+		logger:    logger,
+		ttl:       ttl,
+		ignoreTTL: ignoreTTL,
+		slimPRs:   slimPRs,
+		orgRepos:  make(map[string]memoryEntry),
+		repos:     make(map[string]*memoryRepo),
+		users:     make(map[string]memoryEntry),
+	}
+}
+
+// SetSnapshotAt makes reads see the cache as of t: entries written after t are
+// treated as nonexistent. A zero t disables the snapshot.
+func (c *MemoryCache) SetSnapshotAt(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshotAt = t
+}
+
+// GetRepos retrieves cached repositories enumerated with the given filter signature
+func (c *MemoryCache) GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error) {
+	c.mu.RLock()
+	entry, ok := c.orgRepos[org+"\x00"+filter]
+	c.mu.RUnlock()
+
+	var repos []*github.Repository
+	if err := c.read(entry, ok, true, &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// SetRepos caches repositories enumerated with the given filter signature
+func (c *MemoryCache) SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error {
+	entry, err := newMemoryEntry(repos)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orgRepos[org+"\x00"+filter] = entry
+	return nil
+}
+
+// GetCODEOWNERS retrieves cached CODEOWNERS file
+func (c *MemoryCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil && r.codeowners != nil {
+		entry, ok = *r.codeowners, true
+	}
+	if err := c.check(entry, ok, true); err != nil {
+		return nil, err
+	}
+	return entry.data, nil
+}
+
+// SetCODEOWNERS caches CODEOWNERS file
+func (c *MemoryCache) SetCODEOWNERS(ctx context.Context, owner, repo string, content []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).codeowners = &memoryEntry{data: append([]byte(nil), content...), timestamp: time.Now()}
+	return nil
+}
+
+// GetParsedCODEOWNERS retrieves a parsed CODEOWNERS file cached under hash.
+// Entries are keyed by the content they were parsed from, so they never expire.
+func (c *MemoryCache) GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil && r.parsedCODEOWNERS != nil && r.parsedCODEOWNERS.key == hash {
+		entry, ok = r.parsedCODEOWNERS.memoryEntry, true
+	}
+	if err := c.check(entry, ok, false); err != nil {
+		return nil, err
+	}
+	return entry.data, nil
+}
+
+// SetParsedCODEOWNERS caches a parsed CODEOWNERS file under hash, replacing
+// the repository's previous entry
+func (c *MemoryCache) SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).parsedCODEOWNERS = &memoryKeyedEntry{
+		memoryEntry: memoryEntry{data: append([]byte(nil), parsed...), timestamp: time.Now()},
+		key:         hash,
+	}
+	return nil
+}
+
+// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit.
+// The file at a commit never changes, so entries never expire.
+func (c *MemoryCache) GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil {
+		entry, ok = r.codeownersAtCommit[sha]
+	}
+	if err := c.check(entry, ok, false); err != nil {
+		return nil, err
+	}
+	return entry.data, nil
+}
+
+// SetCODEOWNERSAtCommit caches the CODEOWNERS file as of a commit
+func (c *MemoryCache) SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).codeownersAtCommit[sha] = memoryEntry{data: append([]byte(nil), content...), timestamp: time.Now()}
+	return nil
+}
+
+// GetPRs retrieves cached PRs for a repository, filtered by time window
+func (c *MemoryCache) GetPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var prs []*github.PullRequest
+	var hasExpiredEntries bool
+	if r := c.repos[owner+"/"+repo]; r != nil {
+		for number, entry := range r.prs {
+			if err := c.check(entry, true, true); err != nil {
+				if err.Error() == "cache entry expired" {
+					hasExpiredEntries = true
+				}
+				continue
+			}
+
+			// Decode PR (full or slim representation)
+			pr, err := decodePR(entry.data)
+			if err != nil {
+				c.logger.Warn("Failed to unmarshal PR data", zap.Int("pr_number", number), zap.Error(err))
+				continue
+			}
+
+			// Filter by the (half-open) time window
+			if pr.ClosedAt != nil && !pr.ClosedAt.Time.Before(since) && pr.ClosedAt.Time.Before(until) {
+				prs = append(prs, pr)
+			}
+		}
+	}
+
+	if len(prs) == 0 && hasExpiredEntries {
+		return nil, fmt.Errorf("cache entry expired")
+	}
+	if len(prs) == 0 {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	return prs, nil
+}
+
+// SetPRs caches PRs for a repository (stores individual PRs by ID)
+func (c *MemoryCache) SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
+	now := time.Now()
+	entries := make(map[int]memoryEntry, len(prs))
+	for _, pr := range prs {
+		if pr.Number == nil {
+			continue
+		}
+		data, err := json.Marshal(encodePR(pr, c.slimPRs))
+		if err != nil {
+			c.logger.Warn("Failed to marshal PR", zap.Error(err))
+			continue
+		}
+		entries[pr.GetNumber()] = memoryEntry{data: data, timestamp: now}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.repo(owner, repo)
+	for number, entry := range entries {
+		r.prs[number] = entry
+	}
+	return nil
+}
+
+// GetPRFiles retrieves cached PR files
+func (c *MemoryCache) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
+	var files []*github.CommitFile
+	if err := c.getPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prFiles }, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// SetPRFiles caches PR files
+func (c *MemoryCache) SetPRFiles(ctx context.Context, owner, repo string, prNumber int, files []*github.CommitFile) error {
+	return c.setPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prFiles }, files)
+}
+
+// GetPRReviews retrieves cached PR reviews
+func (c *MemoryCache) GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	var reviews []*github.PullRequestReview
+	if err := c.getPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prReviews }, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// SetPRReviews caches PR reviews
+func (c *MemoryCache) SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error {
+	return c.setPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prReviews }, reviews)
+}
+
+// GetPRCommits retrieves cached PR commits
+func (c *MemoryCache) GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	var commits []*github.RepositoryCommit
+	if err := c.getPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prCommits }, &commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// SetPRCommits caches PR commits
+func (c *MemoryCache) SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error {
+	return c.setPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prCommits }, commits)
+}
+
+// GetPRReviewEvents retrieves the cached review events of a PR's timeline
+func (c *MemoryCache) GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	var events []*github.Timeline
+	if err := c.getPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prReviewEvents }, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SetPRReviewEvents caches the review events of a PR's timeline
+func (c *MemoryCache) SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error {
+	return c.setPREntry(owner, repo, prNumber, func(r *memoryRepo) map[int]memoryEntry { return r.prReviewEvents }, events)
+}
+
+// GetPRTeams retrieves the owning teams of a repository's PRs cached under key
+func (c *MemoryCache) GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error) {
+	c.mu.RLock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil && r.prTeams != nil && r.prTeams.key == key {
+		entry, ok = r.prTeams.memoryEntry, true
+	}
+	c.mu.RUnlock()
+
+	var teams map[int][]string
+	if err := c.read(entry, ok, false, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// SetPRTeams caches the owning teams of a repository's PRs under key,
+// replacing the repository's previous entry
+func (c *MemoryCache) SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error {
+	entry, err := newMemoryEntry(teams)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).prTeams = &memoryKeyedEntry{memoryEntry: entry, key: key}
+	return nil
+}
+
+// GetReleases retrieves the cached releases of a repository
+func (c *MemoryCache) GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	c.mu.RLock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil && r.releases != nil {
+		entry, ok = *r.releases, true
+	}
+	c.mu.RUnlock()
+
+	var releases []*github.RepositoryRelease
+	if err := c.read(entry, ok, true, &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// SetReleases caches the releases of a repository
+func (c *MemoryCache) SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error {
+	entry, err := newMemoryEntry(releases)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).releases = &entry
+	return nil
+}
+
+// GetFetchProgress retrieves the progress of an interrupted PR fetch
+func (c *MemoryCache) GetFetchProgress(ctx context.Context, owner, repo string) (*FetchProgress, error) {
+	c.mu.RLock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil && r.fetchProgress != nil {
+		entry, ok = *r.fetchProgress, true
+	}
+	c.mu.RUnlock()
+
+	var progress FetchProgress
+	if err := c.read(entry, ok, true, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// SetFetchProgress records the progress of an in-flight PR fetch
+func (c *MemoryCache) SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error {
+	entry, err := newMemoryEntry(progress)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repo(owner, repo).fetchProgress = &entry
+	return nil
+}
+
+// ClearFetchProgress removes the progress of a completed PR fetch
+func (c *MemoryCache) ClearFetchProgress(ctx context.Context, owner, repo string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r := c.repos[owner+"/"+repo]; r != nil {
+		r.fetchProgress = nil
+	}
+	return nil
+}
+
+// GetUser retrieves a cached user profile
+func (c *MemoryCache) GetUser(ctx context.Context, login string) (*github.User, error) {
+	c.mu.RLock()
+	entry, ok := c.users[login]
+	c.mu.RUnlock()
+
+	var user github.User
+	if err := c.read(entry, ok, true, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUser caches a user profile
+func (c *MemoryCache) SetUser(ctx context.Context, login string, user *github.User) error {
+	entry, err := newMemoryEntry(user)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[login] = entry
+	return nil
+}
+
+// Invalidate invalidates all cache entries
+func (c *MemoryCache) Invalidate(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orgRepos = make(map[string]memoryEntry)
+	c.repos = make(map[string]*memoryRepo)
+	c.users = make(map[string]memoryEntry)
+	return nil
+}
+
+// InvalidateRepo invalidates cache for a specific repository
+func (c *MemoryCache) InvalidateRepo(ctx context.Context, owner, repo string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.repos, owner+"/"+repo)
+	return nil
+}
+
+// Close closes the cache. Entries are kept, so a closed cache can still be read.
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// repo returns the entries of a repository, creating them if needed. The
+// caller holds c.mu for writing.
+func (c *MemoryCache) repo(owner, repo string) *memoryRepo {
+	key := owner + "/" + repo
+	r := c.repos[key]
+	if r == nil {
+		r = &memoryRepo{
+			codeownersAtCommit: make(map[string]memoryEntry),
+			prs:                make(map[int]memoryEntry),
+			prFiles:            make(map[int]memoryEntry),
+			prReviews:          make(map[int]memoryEntry),
+			prCommits:          make(map[int]memoryEntry),
+			prReviewEvents:     make(map[int]memoryEntry),
+		}
+		c.repos[key] = r
+	}
+	return r
+}
+
+// getPREntry reads a per-PR entry from the map entries returns of the repository
+func (c *MemoryCache) getPREntry(owner, repo string, prNumber int, entries func(*memoryRepo) map[int]memoryEntry, result interface{}) error {
+	c.mu.RLock()
+	entry, ok := memoryEntry{}, false
+	if r := c.repos[owner+"/"+repo]; r != nil {
+		entry, ok = entries(r)[prNumber]
+	}
+	c.mu.RUnlock()
+	return c.read(entry, ok, true, result)
+}
+
+// setPREntry writes a per-PR entry to the map entries returns of the repository
+func (c *MemoryCache) setPREntry(owner, repo string, prNumber int, entries func(*memoryRepo) map[int]memoryEntry, value interface{}) error {
+	entry, err := newMemoryEntry(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries(c.repo(owner, repo))[prNumber] = entry
+	return nil
+}
+
+// read checks an entry like check and unmarshals its data into result
+func (c *MemoryCache) read(entry memoryEntry, ok, expires bool, result interface{}) error {
+	if err := c.check(entry, ok, expires); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(entry.data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+	return nil
+}
+
+// check returns the error of reading an entry: not found when it is missing
+// (!ok) or was written after the snapshot, expired when it expires and is
+// past the TTL (unless ignored)
+func (c *MemoryCache) check(entry memoryEntry, ok, expires bool) error {
+	if !ok || newerThanSnapshot(entry.timestamp, c.snapshotAt) {
+		return fmt.Errorf("cache entry not found")
+	}
+	if expires && !c.ignoreTTL {
+		cached := CacheEntry{Timestamp: entry.timestamp}
+		if cached.IsExpired(c.ttl) {
+			return fmt.Errorf("cache entry expired")
+		}
+	}
+	return nil
+}
+
+// newMemoryEntry serializes value into an entry written now
+func newMemoryEntry(value interface{}) (memoryEntry, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return memoryEntry{}, fmt.Errorf("failed to marshal data: %w", err)
+	}
+	return memoryEntry{data: data, timestamp: time.Now()}, nil
+}
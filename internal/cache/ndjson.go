@@ -0,0 +1,346 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ndjsonCompactMin is the number of superseded lines a store needs, besides
+// outnumbering the live ones, before it is compacted
+const ndjsonCompactMin = 1000
+
+// ndjsonLine is a line of an ndjson store: a cache entry keyed by PR number
+type ndjsonLine struct {
+	Key       int             `json:"key"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ndjsonSpan locates a line in an ndjson store (without its newline)
+type ndjsonSpan struct {
+	Offset int64 `json:"offset"`
+	Length int   `json:"length"`
+}
+
+// ndjsonIndex is the index of an ndjson store as saved next to it. Lines past
+// Size were appended after it was saved and are scanned on open.
+type ndjsonIndex struct {
+	Size    int64              `json:"size"`
+	Stale   int                `json:"stale"`
+	Entries map[int]ndjsonSpan `json:"entries"`
+}
+
+// ndjsonStore is an append-only file of newline-delimited cache entries keyed
+// by PR number, the json_layout: ndjson replacement for a directory of one
+// file per PR. The last line of a key wins. The index of each key's latest
+// line is kept in memory and saved next to the file (path.idx) by saveIndex.
+// A store mostly made of superseded lines is compacted, on open and as lines
+// are appended, so a long-running process does not grow it without bound.
+//
+// It is safe for concurrent use within one process. A single process may have
+// a store open at a time: it holds a lock on path.lock until close, and other
+// processes fail to open the store meanwhile.
+type ndjsonStore struct {
+	mu    sync.Mutex
+	path  string
+	lock  *os.File // Held until close
+	size  int64
+	index map[int]ndjsonSpan
+	stale int  // Lines superseded by a later line of the same key
+	dirty bool // Index changed since it was saved
+}
+
+// openNDJSONStore opens the store at path, which need not exist yet. The saved
+// index is reused when it still fits the file; lines appended since are
+// scanned, and a store mostly made of superseded lines is compacted.
+func openNDJSONStore(path string) (*ndjsonStore, error) {
+	lock, err := lockFile(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	s := &ndjsonStore{path: path, lock: lock, index: make(map[int]ndjsonSpan)}
+	if err := s.load(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load indexes the store's file, if it exists, on open
+func (s *ndjsonStore) load() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", s.path, err)
+	}
+
+	var from int64
+	if data, err := os.ReadFile(s.path + ".idx"); err == nil {
+		var index ndjsonIndex
+		if json.Unmarshal(data, &index) == nil && index.Entries != nil && index.Size <= info.Size() {
+			s.index, s.stale, from = index.Entries, index.Stale, index.Size
+		}
+	}
+	if err := s.scan(from); err != nil {
+		return err
+	}
+	return s.compactIfStale()
+}
+
+// compactIfStale compacts the store once it is mostly made of superseded
+// lines. The caller holds s.mu (or has the store to itself).
+func (s *ndjsonStore) compactIfStale() error {
+	if s.stale >= ndjsonCompactMin && s.stale > len(s.index) {
+		return s.compact()
+	}
+	return nil
+}
+
+// scan indexes the lines from offset from to the end of the file. A partial
+// last line, left by an interrupted append, is truncated away.
+func (s *ndjsonStore) scan(from int64) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(from, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek %s: %w", s.path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	offset := from
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				if err := os.Truncate(s.path, offset); err != nil {
+					return fmt.Errorf("failed to truncate partial line of %s: %w", s.path, err)
+				}
+				s.dirty = true
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.path, err)
+		}
+
+		var key struct {
+			Key *int `json:"key"`
+		}
+		if json.Unmarshal(line, &key) != nil || key.Key == nil {
+			s.stale++
+		} else {
+			if _, ok := s.index[*key.Key]; ok {
+				s.stale++
+			}
+			s.index[*key.Key] = ndjsonSpan{Offset: offset, Length: len(line) - 1}
+		}
+		offset += int64(len(line))
+		s.dirty = true
+	}
+	s.size = offset
+	return nil
+}
+
+// compact rewrites the store with only the latest line of each key
+func (s *ndjsonStore) compact() error {
+	lines, err := s.readAll()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	index := make(map[int]ndjsonSpan, len(lines))
+	for _, line := range lines {
+		index[line.key] = ndjsonSpan{Offset: int64(buf.Len()), Length: len(line.raw)}
+		buf.Write(line.raw)
+		buf.WriteByte('\n')
+	}
+	if err := writeFileAtomic(s.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to compact %s: %w", s.path, err)
+	}
+
+	s.index, s.stale, s.size, s.dirty = index, 0, int64(buf.Len()), true
+	return s.saveIndexLocked()
+}
+
+// get returns the latest line of key, reporting false when there is none.
+// It holds s.mu while reading, so a compaction cannot move the line meanwhile.
+func (s *ndjsonStore) get(key int) (ndjsonLine, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	span, ok := s.index[key]
+	if !ok {
+		return ndjsonLine{}, false, nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return ndjsonLine{}, false, fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	raw := make([]byte, span.Length)
+	if _, err := file.ReadAt(raw, span.Offset); err != nil {
+		return ndjsonLine{}, false, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	var line ndjsonLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return ndjsonLine{}, false, fmt.Errorf("failed to unmarshal line of %s: %w", s.path, err)
+	}
+	if line.Key != key {
+		return ndjsonLine{}, false, fmt.Errorf("index of %s points to key %d for key %d", s.path, line.Key, key)
+	}
+	return line, true, nil
+}
+
+// all returns the latest line of every key, by key
+func (s *ndjsonStore) all() ([]ndjsonLine, error) {
+	s.mu.Lock()
+	raws, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]ndjsonLine, 0, len(raws))
+	for _, raw := range raws {
+		var line ndjsonLine
+		if err := json.Unmarshal(raw.raw, &line); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal line of %s: %w", s.path, err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// rawLine is the undecoded latest line of a key
+type rawLine struct {
+	key int
+	raw []byte
+}
+
+// readAll reads the latest line of every key, by key. The caller holds s.mu.
+func (s *ndjsonStore) readAll() ([]rawLine, error) {
+	if len(s.index) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	lines := make([]rawLine, 0, len(s.index))
+	for key, span := range s.index {
+		if span.Offset+int64(span.Length) > int64(len(data)) {
+			return nil, fmt.Errorf("index of %s points past its end", s.path)
+		}
+		lines = append(lines, rawLine{key: key, raw: data[span.Offset : span.Offset+int64(span.Length)]})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].key < lines[j].key })
+	return lines, nil
+}
+
+// append writes lines to the end of the store in a single write, superseding
+// earlier lines of the same keys
+func (s *ndjsonStore) append(lines ...ndjsonLine) error {
+	var buf bytes.Buffer
+	spans := make([]ndjsonSpan, len(lines))
+	for i, line := range lines {
+		raw, err := json.Marshal(line)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache entry: %w", err)
+		}
+		spans[i] = ndjsonSpan{Offset: int64(buf.Len()), Length: len(raw)}
+		buf.Write(raw)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to append to %s: %w", s.path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", s.path, err)
+	}
+
+	for i, line := range lines {
+		if _, ok := s.index[line.Key]; ok {
+			s.stale++
+		}
+		s.index[line.Key] = ndjsonSpan{Offset: s.size + spans[i].Offset, Length: spans[i].Length}
+	}
+	s.size += int64(buf.Len())
+	s.dirty = true
+	return s.compactIfStale()
+}
+
+// saveIndex saves the index next to the store if it changed, so the next
+// open does not scan the whole file
+func (s *ndjsonStore) saveIndex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveIndexLocked()
+}
+
+// close saves the index and releases the store's lock; the store must not be
+// used afterwards
+func (s *ndjsonStore) close() error {
+	err := s.saveIndex()
+	s.release()
+	return err
+}
+
+// release releases the store's lock without saving the index, for a store
+// whose files are about to be removed; the store must not be used afterwards
+func (s *ndjsonStore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lock != nil {
+		s.lock.Close()
+		s.lock = nil
+	}
+}
+
+// saveIndexLocked is saveIndex for a caller holding s.mu
+func (s *ndjsonStore) saveIndexLocked() error {
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(ndjsonIndex{Size: s.size, Stale: s.stale, Entries: s.index})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index of %s: %w", s.path, err)
+	}
+	if err := writeFileAtomic(s.path+".idx", data); err != nil {
+		return fmt.Errorf("failed to save index of %s: %w", s.path, err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with data, so readers never see
+// a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// testLine returns a line of key whose data is value
+func testLine(key int, value string) ndjsonLine {
+	return ndjsonLine{Key: key, Timestamp: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), Data: json.RawMessage(fmt.Sprintf("%q", value))}
+}
+
+// wantLine fails the test unless the store's latest line of key holds value
+func wantLine(t *testing.T, s *ndjsonStore, key int, value string) {
+	t.Helper()
+	line, ok, err := s.get(key)
+	if err != nil || !ok {
+		t.Fatalf("get(%d) = %v, %v, want a line", key, ok, err)
+	}
+	if got := string(line.Data); got != fmt.Sprintf("%q", value) {
+		t.Errorf("get(%d) = %s, want %q", key, got, value)
+	}
+}
+
+func openTestStore(t *testing.T, path string) *ndjsonStore {
+	t.Helper()
+	s, err := openNDJSONStore(path)
+	if err != nil {
+		t.Fatalf("openNDJSONStore: %v", err)
+	}
+	t.Cleanup(s.release)
+	return s
+}
+
+func TestNDJSONStoreAppendAndGet(t *testing.T) {
+	s := openTestStore(t, filepath.Join(t.TempDir(), "prs.ndjson"))
+
+	if _, ok, err := s.get(1); ok || err != nil {
+		t.Fatalf("get on an empty store = %v, %v, want no line", ok, err)
+	}
+	if err := s.append(testLine(1, "a"), testLine(2, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.append(testLine(1, "c")); err != nil {
+		t.Fatal(err)
+	}
+
+	wantLine(t, s, 1, "c")
+	wantLine(t, s, 2, "b")
+	if s.stale != 1 {
+		t.Errorf("stale = %d, want 1", s.stale)
+	}
+
+	lines, err := s.all()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Key != 1 || lines[1].Key != 2 {
+		t.Errorf("all() = %+v, want the latest lines of keys 1 and 2", lines)
+	}
+}
+
+func TestNDJSONStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.ndjson")
+
+	s := openTestStore(t, path)
+	if err := s.append(testLine(1, "a"), testLine(2, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".idx"); err != nil {
+		t.Fatalf("index not saved on close: %v", err)
+	}
+
+	// Lines appended after the index was saved are scanned on open
+	s = openTestStore(t, path)
+	if err := s.append(testLine(2, "c"), testLine(3, "d")); err != nil {
+		t.Fatal(err)
+	}
+	s.release()
+
+	s = openTestStore(t, path)
+	wantLine(t, s, 1, "a")
+	wantLine(t, s, 2, "c")
+	wantLine(t, s, 3, "d")
+	if s.stale != 1 {
+		t.Errorf("stale = %d, want 1", s.stale)
+	}
+}
+
+func TestNDJSONStoreTruncatesPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.ndjson")
+
+	s := openTestStore(t, path)
+	if err := s.append(testLine(1, "a")); err != nil {
+		t.Fatal(err)
+	}
+	size := s.size
+	s.release()
+
+	// An append interrupted mid-line
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"key":2,"timest`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	s = openTestStore(t, path)
+	if info, err := os.Stat(path); err != nil || info.Size() != size {
+		t.Fatalf("store size after open = %v, %v, want %d", info.Size(), err, size)
+	}
+	wantLine(t, s, 1, "a")
+	if _, ok, _ := s.get(2); ok {
+		t.Error("expected the partial line to be dropped")
+	}
+
+	// Later appends start on a line of their own
+	if err := s.append(testLine(2, "b")); err != nil {
+		t.Fatal(err)
+	}
+	s.release()
+	s = openTestStore(t, path)
+	wantLine(t, s, 2, "b")
+}
+
+func TestNDJSONStoreCompactsOnAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.ndjson")
+	s := openTestStore(t, path)
+
+	if err := s.append(testLine(1, "a")); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= ndjsonCompactMin; i++ {
+		if err := s.append(testLine(2, fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.stale != 0 {
+		t.Errorf("stale = %d, want 0 after compaction", s.stale)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("store has %d lines after compaction, want 2", lines)
+	}
+	wantLine(t, s, 1, "a")
+	wantLine(t, s, 2, fmt.Sprint(ndjsonCompactMin))
+
+	// The compacted store reopens from its saved index
+	s.release()
+	s = openTestStore(t, path)
+	wantLine(t, s, 1, "a")
+	wantLine(t, s, 2, fmt.Sprint(ndjsonCompactMin))
+}
+
+func TestNDJSONStoreCompactsOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prs.ndjson")
+
+	// A store written before compaction ran as lines were appended
+	var data []byte
+	for i := 0; i <= ndjsonCompactMin; i++ {
+		raw, err := json.Marshal(testLine(1, fmt.Sprint(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(append(data, raw...), '\n')
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openTestStore(t, path)
+	if info, err := os.Stat(path); err != nil || info.Size() != s.size || s.stale != 0 {
+		t.Errorf("store after open: size %d (file %v, %v), stale %d, want compacted", s.size, info.Size(), err, s.stale)
+	}
+	wantLine(t, s, 1, fmt.Sprint(ndjsonCompactMin))
+}
+
+func TestNDJSONStoreGetChecksKey(t *testing.T) {
+	s := openTestStore(t, filepath.Join(t.TempDir(), "prs.ndjson"))
+	if err := s.append(testLine(1, "a"), testLine(2, "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	// An index out of step with the file
+	s.index[1] = s.index[2]
+	if _, _, err := s.get(1); err == nil {
+		t.Error("expected an error for an index pointing to another key's line")
+	}
+}
+
+func TestNDJSONStoreRefusesConcurrentOpen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stores are only locked on unix")
+	}
+	path := filepath.Join(t.TempDir(), "prs.ndjson")
+
+	s := openTestStore(t, path)
+	if _, err := openNDJSONStore(path); err == nil {
+		t.Fatal("expected opening a store held open elsewhere to fail")
+	}
+
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	openTestStore(t, path)
+}
+
+func TestJSONCacheMigratesToNDJSON(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	files, err := NewJSONCache(dir, JSONLayoutFiles, "", time.Hour, false, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := &github.Timestamp{Time: time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC)}
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), Title: github.String("one"), ClosedAt: closed},
+		{Number: github.Int(2), Title: github.String("two"), ClosedAt: closed},
+	}
+	if err := files.SetPRs(ctx, "myorg", "api", prs); err != nil {
+		t.Fatal(err)
+	}
+	if err := files.SetPRFiles(ctx, "myorg", "api", 1, []*github.CommitFile{{Filename: github.String("main.go")}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ndjson, err := NewJSONCache(dir, JSONLayoutNDJSON, "", time.Hour, false, false, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ndjson.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "repos", "myorg", "api", "prs")); !os.IsNotExist(err) {
+		t.Errorf("expected the per-PR files to be removed after migration, stat: %v", err)
+	}
+	got, err := ndjson.GetPRs(ctx, "myorg", "api", closed.Add(-time.Hour), closed.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].GetTitle() != "one" || got[1].GetTitle() != "two" {
+		t.Errorf("GetPRs after migration = %v, want PRs 1 and 2", got)
+	}
+	prFiles, err := ndjson.GetPRFiles(ctx, "myorg", "api", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prFiles) != 1 || prFiles[0].GetFilename() != "main.go" {
+		t.Errorf("GetPRFiles after migration = %v, want main.go", prFiles)
+	}
+}
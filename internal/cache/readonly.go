@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v62/github"
+)
+
+// errReadOnly is returned by the invalidations of a read-only cache
+var errReadOnly = errors.New("cache is read-only while cache.snapshot_at is set")
+
+// ReadOnlyCache wraps a cache read as of a snapshot and drops its writes.
+// Entries written after the snapshot are invisible to reads, so writing what
+// was fetched in their place would only overwrite newer entries and make
+// the next run at the same snapshot fetch them again. Invalidations fail.
+type ReadOnlyCache struct {
+	Cache
+}
+
+// NewReadOnlyCache wraps c to drop its writes
+func NewReadOnlyCache(c Cache) *ReadOnlyCache {
+	return &ReadOnlyCache{Cache: c}
+}
+
+// SetRepos drops the write
+func (c *ReadOnlyCache) SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error {
+	return nil
+}
+
+// SetCODEOWNERS drops the write
+func (c *ReadOnlyCache) SetCODEOWNERS(ctx context.Context, owner, repo string, content []byte) error {
+	return nil
+}
+
+// SetParsedCODEOWNERS drops the write
+func (c *ReadOnlyCache) SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error {
+	return nil
+}
+
+// SetCODEOWNERSAtCommit drops the write
+func (c *ReadOnlyCache) SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error {
+	return nil
+}
+
+// SetPRs drops the write
+func (c *ReadOnlyCache) SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
+	return nil
+}
+
+// SetPRFiles drops the write
+func (c *ReadOnlyCache) SetPRFiles(ctx context.Context, owner, repo string, prNumber int, files []*github.CommitFile) error {
+	return nil
+}
+
+// SetPRReviews drops the write
+func (c *ReadOnlyCache) SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error {
+	return nil
+}
+
+// SetPRCommits drops the write
+func (c *ReadOnlyCache) SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error {
+	return nil
+}
+
+// SetPRReviewEvents drops the write
+func (c *ReadOnlyCache) SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error {
+	return nil
+}
+
+// SetFetchProgress drops the write
+func (c *ReadOnlyCache) SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error {
+	return nil
+}
+
+// ClearFetchProgress drops the write
+func (c *ReadOnlyCache) ClearFetchProgress(ctx context.Context, owner, repo string) error {
+	return nil
+}
+
+// SetPRTeams drops the write
+func (c *ReadOnlyCache) SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error {
+	return nil
+}
+
+// SetReleases drops the write
+func (c *ReadOnlyCache) SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error {
+	return nil
+}
+
+// SetUser drops the write
+func (c *ReadOnlyCache) SetUser(ctx context.Context, login string, user *github.User) error {
+	return nil
+}
+
+// Invalidate fails: a snapshot is read, never cleared
+func (c *ReadOnlyCache) Invalidate(ctx context.Context) error {
+	return errReadOnly
+}
+
+// InvalidateRepo fails: a snapshot is read, never cleared
+func (c *ReadOnlyCache) InvalidateRepo(ctx context.Context, owner, repo string) error {
+	return errReadOnly
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v62/github"
+)
+
+// slimPRVersion is the version of the slim PR representation. Bump it whenever
+// the kept fields change; entries of another version are treated as missing so
+// they get refetched with the current fields.
+const slimPRVersion = 5
+
+// slimPR is the reduced PR representation cached when cache.slim_prs is set.
+// It keeps only the fields the analysis reads.
+type slimPR struct {
+	SlimVersion  int               `json:"slim_version"`
+	Number       int               `json:"number"`
+	Title        string            `json:"title,omitempty"`
+	Body         string            `json:"body,omitempty"`
+	State        string            `json:"state,omitempty"`
+	HTMLURL      string            `json:"html_url,omitempty"`
+	Author       string            `json:"author,omitempty"`
+	Association  string            `json:"author_association,omitempty"`
+	CreatedAt    *github.Timestamp `json:"created_at,omitempty"`
+	ClosedAt     *github.Timestamp `json:"closed_at,omitempty"`
+	MergedAt     *github.Timestamp `json:"merged_at,omitempty"`
+	MergedBy     string            `json:"merged_by,omitempty"`
+	MergeCommit  string            `json:"merge_commit_sha,omitempty"`
+	Labels       []string          `json:"labels,omitempty"`
+	BaseRef      string            `json:"base_ref,omitempty"`
+	Additions    *int              `json:"additions,omitempty"`
+	Deletions    *int              `json:"deletions,omitempty"`
+	Commits      *int              `json:"commits,omitempty"`
+	ChangedFiles *int              `json:"changed_files,omitempty"`
+}
+
+// encodePR returns the value to cache for a PR: the full object, or its slim
+// representation when slim is set
+func encodePR(pr *github.PullRequest, slim bool) interface{} {
+	if !slim {
+		return pr
+	}
+
+	s := &slimPR{
+		SlimVersion:  slimPRVersion,
+		Number:       pr.GetNumber(),
+		Title:        pr.GetTitle(),
+		Body:         pr.GetBody(),
+		State:        pr.GetState(),
+		HTMLURL:      pr.GetHTMLURL(),
+		Author:       pr.GetUser().GetLogin(),
+		Association:  pr.GetAuthorAssociation(),
+		CreatedAt:    pr.CreatedAt,
+		ClosedAt:     pr.ClosedAt,
+		MergedAt:     pr.MergedAt,
+		MergedBy:     pr.GetMergedBy().GetLogin(),
+		MergeCommit:  pr.GetMergeCommitSHA(),
+		BaseRef:      pr.GetBase().GetRef(),
+		Additions:    pr.Additions,
+		Deletions:    pr.Deletions,
+		Commits:      pr.Commits,
+		ChangedFiles: pr.ChangedFiles,
+	}
+	for _, label := range pr.Labels {
+		s.Labels = append(s.Labels, label.GetName())
+	}
+	return s
+}
+
+// decodePR decodes a cached PR stored in either the full or the slim representation
+func decodePR(data []byte) (*github.PullRequest, error) {
+	var probe struct {
+		SlimVersion int `json:"slim_version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	// Full object
+	if probe.SlimVersion == 0 {
+		var pr github.PullRequest
+		if err := json.Unmarshal(data, &pr); err != nil {
+			return nil, err
+		}
+		return &pr, nil
+	}
+
+	if probe.SlimVersion != slimPRVersion {
+		return nil, fmt.Errorf("unsupported slim PR version %d", probe.SlimVersion)
+	}
+
+	var s slimPR
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	pr := &github.PullRequest{
+		Number:       github.Int(s.Number),
+		Title:        github.String(s.Title),
+		CreatedAt:    s.CreatedAt,
+		ClosedAt:     s.ClosedAt,
+		MergedAt:     s.MergedAt,
+		Additions:    s.Additions,
+		Deletions:    s.Deletions,
+		Commits:      s.Commits,
+		ChangedFiles: s.ChangedFiles,
+	}
+	if s.Body != "" {
+		pr.Body = github.String(s.Body)
+	}
+	if s.State != "" {
+		pr.State = github.String(s.State)
+	}
+	if s.HTMLURL != "" {
+		pr.HTMLURL = github.String(s.HTMLURL)
+	}
+	if s.Author != "" {
+		pr.User = &github.User{Login: github.String(s.Author)}
+	}
+	if s.Association != "" {
+		pr.AuthorAssociation = github.String(s.Association)
+	}
+	if s.MergedBy != "" {
+		pr.MergedBy = &github.User{Login: github.String(s.MergedBy)}
+	}
+	if s.MergeCommit != "" {
+		pr.MergeCommitSHA = github.String(s.MergeCommit)
+	}
+	if s.BaseRef != "" {
+		pr.Base = &github.PullRequestBranch{Ref: github.String(s.BaseRef)}
+	}
+	for _, name := range s.Labels {
+		pr.Labels = append(pr.Labels, &github.Label{Name: github.String(name)})
+	}
+	return pr, nil
+}
@@ -4,29 +4,46 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 const (
 	// Current schema version
-	schemaVersion = 2
+	schemaVersion = 3
+
+	// namespaceSeparator separates the namespace from org/owner keys.
+	// GitHub logins cannot contain it, so un-namespaced keys never do.
+	namespaceSeparator = ":"
+
+	// writeRetries is how many times a write is retried while the database is busy
+	writeRetries = 5
+
+	// writeRetryBackoff is the wait before the first retry of a busy write,
+	// doubled for each further retry
+	writeRetryBackoff = 50 * time.Millisecond
 )
 
 // SQLiteCache implements cache using SQLite
 type SQLiteCache struct {
 	db        *sql.DB
 	logger    *zap.Logger
+	namespace string
 	ttl       time.Duration
 	ignoreTTL bool
+	slimPRs   bool
+	// Entries written after snapshotAt are treated as nonexistent (zero = no snapshot)
+	snapshotAt time.Time
 }
 
 // NewSQLiteCache creates a new SQLite cache
-func NewSQLiteCache(dbPath string, ttl time.Duration, ignoreTTL bool, logger *zap.Logger) (*SQLiteCache, error) {
+func NewSQLiteCache(dbPath, namespace string, ttl time.Duration, ignoreTTL, slimPRs bool, logger *zap.Logger) (*SQLiteCache, error) {
 	// Set SQLite connection parameters to handle busy database
 	db, err := sql.Open("sqlite", dbPath+"?_busy_timeout=5000&_journal_mode=WAL")
 	if err != nil {
@@ -40,8 +57,10 @@ func NewSQLiteCache(dbPath string, ttl time.Duration, ignoreTTL bool, logger *za
 	cache := &SQLiteCache{
 		db:        db,
 		logger:    logger,
+		namespace: namespace,
 		ttl:       ttl,
 		ignoreTTL: ignoreTTL,
+		slimPRs:   slimPRs,
 	}
 
 	// Initialize schema
@@ -59,6 +78,12 @@ func NewSQLiteCache(dbPath string, ttl time.Duration, ignoreTTL bool, logger *za
 	return cache, nil
 }
 
+// SetSnapshotAt makes reads see the cache as of t: entries written after t are
+// treated as nonexistent. A zero t disables the snapshot.
+func (c *SQLiteCache) SetSnapshotAt(t time.Time) {
+	c.snapshotAt = t
+}
+
 // initSchema initializes the database schema
 func (c *SQLiteCache) initSchema() error {
 	schema := `
@@ -70,9 +95,10 @@ func (c *SQLiteCache) initSchema() error {
 	
 	CREATE TABLE IF NOT EXISTS repos (
 		org TEXT NOT NULL,
+		filter TEXT NOT NULL,
 		data BLOB NOT NULL,
 		timestamp DATETIME NOT NULL,
-		PRIMARY KEY (org)
+		PRIMARY KEY (org, filter)
 	);
 	
 	CREATE TABLE IF NOT EXISTS codeowners (
@@ -83,6 +109,24 @@ func (c *SQLiteCache) initSchema() error {
 		PRIMARY KEY (owner, repo)
 	);
 	
+	CREATE TABLE IF NOT EXISTS codeowners_parsed (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo)
+	);
+	
+	CREATE TABLE IF NOT EXISTS codeowners_at_commit (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		sha TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo, sha)
+	);
+	
 	CREATE TABLE IF NOT EXISTS prs (
 		owner TEXT NOT NULL,
 		repo TEXT NOT NULL,
@@ -102,6 +146,65 @@ func (c *SQLiteCache) initSchema() error {
 		timestamp DATETIME NOT NULL,
 		PRIMARY KEY (owner, repo, pr_number)
 	);
+	
+	CREATE TABLE IF NOT EXISTS pr_reviews (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		pr_number INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo, pr_number)
+	);
+	
+	CREATE TABLE IF NOT EXISTS pr_commits (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		pr_number INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo, pr_number)
+	);
+	
+	CREATE TABLE IF NOT EXISTS pr_review_events (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		pr_number INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo, pr_number)
+	);
+	
+	CREATE TABLE IF NOT EXISTS fetch_progress (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo)
+	);
+	
+	CREATE TABLE IF NOT EXISTS pr_teams (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		key TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo)
+	);
+	
+	CREATE TABLE IF NOT EXISTS releases (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (owner, repo)
+	);
+	
+	CREATE TABLE IF NOT EXISTS users (
+		login TEXT NOT NULL,
+		data BLOB NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (login)
+	);
 	`
 
 	_, err := c.db.Exec(schema)
@@ -199,7 +302,16 @@ func (c *SQLiteCache) migrateSchema() error {
 	// Migrate from version 1 to version 2
 	if currentVersion == 1 {
 		c.logger.Debug("Running migration from version 1 to version 2")
-		return c.migrateFromV1ToV2()
+		if err := c.migrateFromV1ToV2(); err != nil {
+			return err
+		}
+		currentVersion = 2
+	}
+
+	// Migrate from version 2 to version 3
+	if currentVersion == 2 {
+		c.logger.Debug("Running migration from version 2 to version 3")
+		return c.migrateFromV2ToV3()
 	}
 
 	// Future migrations can be added here
@@ -233,11 +345,11 @@ func (c *SQLiteCache) migrateFromV1ToV2() error {
 			UPDATE cache_schema 
 			SET version = ?, created_at = ?
 			WHERE version = 1
-		`, schemaVersion, time.Now())
+		`, 2, time.Now())
 		if err != nil {
 			return fmt.Errorf("failed to update schema version: %w", err)
 		}
-		c.logger.Info("Updated cache schema version", zap.Int("version", schemaVersion))
+		c.logger.Info("Updated cache schema version", zap.Int("version", 2))
 		return nil
 	}
 
@@ -252,11 +364,11 @@ func (c *SQLiteCache) migrateFromV1ToV2() error {
 			UPDATE cache_schema 
 			SET version = ?, created_at = ?
 			WHERE version = 1
-		`, schemaVersion, time.Now())
+		`, 2, time.Now())
 		if err != nil {
 			return fmt.Errorf("failed to update schema version: %w", err)
 		}
-		c.logger.Info("Updated cache schema version", zap.Int("version", schemaVersion))
+		c.logger.Info("Updated cache schema version", zap.Int("version", 2))
 		return nil
 	}
 	checkRows.Close()
@@ -589,12 +701,12 @@ func (c *SQLiteCache) migrateFromV1ToV2() error {
 	c.logger.Debug("Table renamed successfully")
 
 	// Update schema version
-	c.logger.Debug("Updating schema version", zap.Int("new_version", schemaVersion))
+	c.logger.Debug("Updating schema version", zap.Int("new_version", 2))
 	_, err = c.db.Exec(`
 		UPDATE cache_schema 
 		SET version = ?, created_at = ?
 		WHERE version = 1
-	`, schemaVersion, time.Now())
+	`, 2, time.Now())
 	if err != nil {
 		c.logger.Debug("Failed to update schema version", zap.Error(err))
 		return fmt.Errorf("failed to update schema version: %w", err)
@@ -603,20 +715,53 @@ func (c *SQLiteCache) migrateFromV1ToV2() error {
 
 	c.logger.Info("PR cache migration complete",
 		zap.Int("migrated_prs", migratedCount),
-		zap.Int("schema_version", schemaVersion))
+		zap.Int("schema_version", 2))
 	return nil
 }
 
-// GetRepos retrieves cached repositories
-func (c *SQLiteCache) GetRepos(ctx context.Context, org string) ([]*github.Repository, error) {
+// migrateFromV2ToV3 re-keys the repos table by org and enumeration filter.
+// Repository lists are cheap to re-fetch, so the table is recreated instead of migrated.
+func (c *SQLiteCache) migrateFromV2ToV3() error {
+	c.logger.Info("Migrating repos cache to per-filter entries")
+
+	if _, err := c.db.Exec(`DROP TABLE IF EXISTS repos`); err != nil {
+		return fmt.Errorf("failed to drop old repos table: %w", err)
+	}
+	_, err := c.db.Exec(`
+		CREATE TABLE repos (
+			org TEXT NOT NULL,
+			filter TEXT NOT NULL,
+			data BLOB NOT NULL,
+			timestamp DATETIME NOT NULL,
+			PRIMARY KEY (org, filter)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create repos table: %w", err)
+	}
+
+	_, err = c.db.Exec(`
+		UPDATE cache_schema 
+		SET version = ?, created_at = ?
+	`, schemaVersion, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+
+	c.logger.Info("Updated cache schema version", zap.Int("version", schemaVersion))
+	return nil
+}
+
+// GetRepos retrieves cached repositories enumerated with the given filter signature
+func (c *SQLiteCache) GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error) {
 	var data []byte
 	var timestamp time.Time
 
-	c.logger.Debug("Getting cached repositories", zap.String("org", org))
+	c.logger.Debug("Getting cached repositories", zap.String("org", org), zap.String("filter", filter))
 
 	err := c.db.QueryRowContext(ctx,
-		"SELECT data, timestamp FROM repos WHERE org = ?",
-		org,
+		"SELECT data, timestamp FROM repos WHERE org = ? AND filter = ?",
+		c.key(org), filter,
 	).Scan(&data, &timestamp)
 
 	if err == sql.ErrNoRows {
@@ -627,6 +772,11 @@ func (c *SQLiteCache) GetRepos(ctx context.Context, org string) ([]*github.Repos
 		return nil, fmt.Errorf("failed to query cache: %w", err)
 	}
 
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
 	// Check expiration (unless ignoreTTL is set)
 	if !c.ignoreTTL {
 		entry := CacheEntry{Timestamp: timestamp}
@@ -646,16 +796,16 @@ func (c *SQLiteCache) GetRepos(ctx context.Context, org string) ([]*github.Repos
 	return repos, nil
 }
 
-// SetRepos caches repositories
-func (c *SQLiteCache) SetRepos(ctx context.Context, org string, repos []*github.Repository) error {
+// SetRepos caches repositories enumerated with the given filter signature
+func (c *SQLiteCache) SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error {
 	data, err := json.Marshal(repos)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
 
-	_, err = c.db.ExecContext(ctx,
-		`INSERT OR REPLACE INTO repos (org, data, timestamp) VALUES (?, ?, ?)`,
-		org, data, time.Now(),
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO repos (org, filter, data, timestamp) VALUES (?, ?, ?, ?)`,
+		c.key(org), filter, data, time.Now(),
 	)
 
 	return err
@@ -668,7 +818,7 @@ func (c *SQLiteCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]
 
 	err := c.db.QueryRowContext(ctx,
 		"SELECT data, timestamp FROM codeowners WHERE owner = ? AND repo = ?",
-		owner, repo,
+		c.key(owner), repo,
 	).Scan(&data, &timestamp)
 
 	if err == sql.ErrNoRows {
@@ -678,6 +828,11 @@ func (c *SQLiteCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]
 		return nil, fmt.Errorf("failed to query cache: %w", err)
 	}
 
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
 	// Check expiration (unless ignoreTTL is set)
 	if !c.ignoreTTL {
 		entry := CacheEntry{Timestamp: timestamp}
@@ -691,9 +846,82 @@ func (c *SQLiteCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]
 
 // SetCODEOWNERS caches CODEOWNERS file
 func (c *SQLiteCache) SetCODEOWNERS(ctx context.Context, owner, repo string, content []byte) error {
-	_, err := c.db.ExecContext(ctx,
+	_, err := c.exec(ctx,
 		`INSERT OR REPLACE INTO codeowners (owner, repo, data, timestamp) VALUES (?, ?, ?, ?)`,
-		owner, repo, content, time.Now(),
+		c.key(owner), repo, content, time.Now(),
+	)
+
+	return err
+}
+
+// GetParsedCODEOWNERS retrieves a parsed CODEOWNERS file cached under hash.
+// Entries are keyed by the content they were parsed from, so they never expire.
+func (c *SQLiteCache) GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM codeowners_parsed WHERE owner = ? AND repo = ? AND hash = ?",
+		c.key(owner), repo, hash,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	return data, nil
+}
+
+// SetParsedCODEOWNERS caches a parsed CODEOWNERS file under hash, replacing
+// the repository's previous entry
+func (c *SQLiteCache) SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error {
+	_, err := c.exec(ctx,
+		`INSERT OR REPLACE INTO codeowners_parsed (owner, repo, hash, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, hash, parsed, time.Now(),
+	)
+
+	return err
+}
+
+// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit.
+// The file at a commit never changes, so entries never expire.
+func (c *SQLiteCache) GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM codeowners_at_commit WHERE owner = ? AND repo = ? AND sha = ?",
+		c.key(owner), repo, sha,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	return data, nil
+}
+
+// SetCODEOWNERSAtCommit caches the CODEOWNERS file as of a commit
+func (c *SQLiteCache) SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error {
+	_, err := c.exec(ctx,
+		`INSERT OR REPLACE INTO codeowners_at_commit (owner, repo, sha, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, sha, content, time.Now(),
 	)
 
 	return err
@@ -706,8 +934,8 @@ func (c *SQLiteCache) GetPRs(ctx context.Context, owner, repo string, since, unt
 		 FROM prs 
 		 WHERE owner = ? AND repo = ? 
 		 AND closed_at IS NOT NULL 
-		 AND closed_at >= ? AND closed_at <= ?`,
-		owner, repo, since, until,
+		 AND closed_at >= ? AND closed_at < ?`,
+		c.key(owner), repo, since, until,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query cache: %w", err)
@@ -728,6 +956,11 @@ func (c *SQLiteCache) GetPRs(ctx context.Context, owner, repo string, since, unt
 			continue
 		}
 
+		// Entries written after the snapshot don't exist as of the snapshot
+		if newerThanSnapshot(timestamp, c.snapshotAt) {
+			continue
+		}
+
 		// Check expiration (unless ignoreTTL is set)
 		if !c.ignoreTTL {
 			entry := CacheEntry{Timestamp: timestamp}
@@ -740,18 +973,18 @@ func (c *SQLiteCache) GetPRs(ctx context.Context, owner, repo string, since, unt
 			}
 		}
 
-		// Unmarshal PR
-		var pr github.PullRequest
-		if err := json.Unmarshal(data, &pr); err != nil {
+		// Unmarshal PR (full or slim representation)
+		pr, err := decodePR(data)
+		if err != nil {
 			c.logger.Warn("Failed to unmarshal PR data", zap.Error(err))
 			continue
 		}
 
-		// Double-check the PR is within the time window
+		// Double-check the PR is within the (half-open) time window
 		if pr.ClosedAt != nil {
 			closedAtTime := pr.ClosedAt.Time
-			if !closedAtTime.Before(since) && !closedAtTime.After(until) {
-				prs = append(prs, &pr)
+			if !closedAtTime.Before(since) && closedAtTime.Before(until) {
+				prs = append(prs, pr)
 			}
 		}
 	}
@@ -775,6 +1008,11 @@ func (c *SQLiteCache) GetPRs(ctx context.Context, owner, repo string, since, unt
 
 // SetPRs caches PRs for a repository (stores individual PRs by ID)
 func (c *SQLiteCache) SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
+	return c.retryBusy(ctx, func() error { return c.setPRs(ctx, owner, repo, prs) })
+}
+
+// setPRs writes PRs in a single transaction
+func (c *SQLiteCache) setPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
 	tx, err := c.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -787,7 +1025,7 @@ func (c *SQLiteCache) SetPRs(ctx context.Context, owner, repo string, prs []*git
 			continue
 		}
 
-		prData, err := json.Marshal(pr)
+		prData, err := json.Marshal(encodePR(pr, c.slimPRs))
 		if err != nil {
 			c.logger.Warn("Failed to marshal PR", zap.Error(err))
 			continue
@@ -804,7 +1042,7 @@ func (c *SQLiteCache) SetPRs(ctx context.Context, owner, repo string, prs []*git
 		_, err = tx.ExecContext(ctx,
 			`INSERT OR REPLACE INTO prs (owner, repo, pr_number, data, created_at, closed_at, timestamp) 
 			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-			owner, repo, *pr.Number, prData, createdAt, closedAt, now,
+			c.key(owner), repo, *pr.Number, prData, createdAt, closedAt, now,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert PR: %w", err)
@@ -825,7 +1063,7 @@ func (c *SQLiteCache) GetPRFiles(ctx context.Context, owner, repo string, prNumb
 
 	err := c.db.QueryRowContext(ctx,
 		"SELECT data, timestamp FROM pr_files WHERE owner = ? AND repo = ? AND pr_number = ?",
-		owner, repo, prNumber,
+		c.key(owner), repo, prNumber,
 	).Scan(&data, &timestamp)
 
 	if err == sql.ErrNoRows {
@@ -835,6 +1073,11 @@ func (c *SQLiteCache) GetPRFiles(ctx context.Context, owner, repo string, prNumb
 		return nil, fmt.Errorf("failed to query cache: %w", err)
 	}
 
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
 	// Check expiration (unless ignoreTTL is set)
 	if !c.ignoreTTL {
 		entry := CacheEntry{Timestamp: timestamp}
@@ -859,20 +1102,434 @@ func (c *SQLiteCache) SetPRFiles(ctx context.Context, owner, repo string, prNumb
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
 
-	_, err = c.db.ExecContext(ctx,
+	_, err = c.exec(ctx,
 		`INSERT OR REPLACE INTO pr_files (owner, repo, pr_number, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
-		owner, repo, prNumber, data, time.Now(),
+		c.key(owner), repo, prNumber, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetPRReviews retrieves cached PR reviews
+func (c *SQLiteCache) GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM pr_reviews WHERE owner = ? AND repo = ? AND pr_number = ?",
+		c.key(owner), repo, prNumber,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var reviews []*github.PullRequestReview
+	if err := json.Unmarshal(data, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// SetPRReviews caches PR reviews
+func (c *SQLiteCache) SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error {
+	data, err := json.Marshal(reviews)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO pr_reviews (owner, repo, pr_number, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, prNumber, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetPRCommits retrieves cached PR commits
+func (c *SQLiteCache) GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM pr_commits WHERE owner = ? AND repo = ? AND pr_number = ?",
+		c.key(owner), repo, prNumber,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var commits []*github.RepositoryCommit
+	if err := json.Unmarshal(data, &commits); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return commits, nil
+}
+
+// SetPRCommits caches PR commits
+func (c *SQLiteCache) SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error {
+	data, err := json.Marshal(commits)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO pr_commits (owner, repo, pr_number, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, prNumber, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetPRReviewEvents retrieves the cached review events of a PR's timeline
+func (c *SQLiteCache) GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM pr_review_events WHERE owner = ? AND repo = ? AND pr_number = ?",
+		c.key(owner), repo, prNumber,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var events []*github.Timeline
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return events, nil
+}
+
+// SetPRReviewEvents caches the review events of a PR's timeline
+func (c *SQLiteCache) SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO pr_review_events (owner, repo, pr_number, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, prNumber, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetPRTeams retrieves the owning teams of a repository's PRs cached under key.
+// Entries are keyed by the inputs of the attribution, so they never expire.
+func (c *SQLiteCache) GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM pr_teams WHERE owner = ? AND repo = ? AND key = ?",
+		c.key(owner), repo, key,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Unmarshal
+	var teams map[int][]string
+	if err := json.Unmarshal(data, &teams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return teams, nil
+}
+
+// SetPRTeams caches the owning teams of a repository's PRs under key,
+// replacing the repository's previous entry
+func (c *SQLiteCache) SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error {
+	data, err := json.Marshal(teams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO pr_teams (owner, repo, key, data, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		c.key(owner), repo, key, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetReleases retrieves the cached releases of a repository
+func (c *SQLiteCache) GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM releases WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var releases []*github.RepositoryRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return releases, nil
+}
+
+// SetReleases caches the releases of a repository
+func (c *SQLiteCache) SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error {
+	data, err := json.Marshal(releases)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO releases (owner, repo, data, timestamp) VALUES (?, ?, ?, ?)`,
+		c.key(owner), repo, data, time.Now(),
+	)
+
+	return err
+}
+
+// GetFetchProgress retrieves the progress of an interrupted PR fetch
+func (c *SQLiteCache) GetFetchProgress(ctx context.Context, owner, repo string) (*FetchProgress, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM fetch_progress WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var progress FetchProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return &progress, nil
+}
+
+// SetFetchProgress records the progress of an in-flight PR fetch
+func (c *SQLiteCache) SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO fetch_progress (owner, repo, data, timestamp) VALUES (?, ?, ?, ?)`,
+		c.key(owner), repo, data, time.Now(),
 	)
 
 	return err
 }
 
-// Invalidate invalidates all cache entries
+// ClearFetchProgress removes the progress of a completed PR fetch
+func (c *SQLiteCache) ClearFetchProgress(ctx context.Context, owner, repo string) error {
+	_, err := c.exec(ctx,
+		"DELETE FROM fetch_progress WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+
+	return err
+}
+
+// GetUser retrieves a cached user profile
+func (c *SQLiteCache) GetUser(ctx context.Context, login string) (*github.User, error) {
+	var data []byte
+	var timestamp time.Time
+
+	err := c.db.QueryRowContext(ctx,
+		"SELECT data, timestamp FROM users WHERE login = ?",
+		c.key(login),
+	).Scan(&data, &timestamp)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	// Entries written after the snapshot don't exist as of the snapshot
+	if newerThanSnapshot(timestamp, c.snapshotAt) {
+		return nil, fmt.Errorf("cache entry not found")
+	}
+
+	// Check expiration (unless ignoreTTL is set)
+	if !c.ignoreTTL {
+		entry := CacheEntry{Timestamp: timestamp}
+		if entry.IsExpired(c.ttl) {
+			return nil, fmt.Errorf("cache entry expired")
+		}
+	}
+
+	// Unmarshal
+	var user github.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return &user, nil
+}
+
+// SetUser caches a user profile
+func (c *SQLiteCache) SetUser(ctx context.Context, login string, user *github.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		`INSERT OR REPLACE INTO users (login, data, timestamp) VALUES (?, ?, ?)`,
+		c.key(login), data, time.Now(),
+	)
+
+	return err
+}
+
+// Invalidate invalidates all cache entries in the cache's namespace
 func (c *SQLiteCache) Invalidate(ctx context.Context) error {
-	tables := []string{"repos", "codeowners", "prs", "pr_files"}
+	// Each table with the key column that carries the namespace prefix
+	tables := []struct {
+		name   string
+		column string
+	}{
+		{"repos", "org"},
+		{"codeowners", "owner"},
+		{"codeowners_parsed", "owner"},
+		{"codeowners_at_commit", "owner"},
+		{"prs", "owner"},
+		{"pr_files", "owner"},
+		{"pr_reviews", "owner"},
+		{"pr_commits", "owner"},
+		{"pr_review_events", "owner"},
+		{"fetch_progress", "owner"},
+		{"pr_teams", "owner"},
+		{"releases", "owner"},
+		{"users", "login"},
+	}
 	for _, table := range tables {
-		if _, err := c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table)); err != nil {
-			return fmt.Errorf("failed to invalidate %s: %w", table, err)
+		var err error
+		if c.namespace == "" {
+			// Leave entries of named namespaces alone
+			_, err = c.exec(ctx,
+				fmt.Sprintf("DELETE FROM %s WHERE instr(%s, ?) = 0", table.name, table.column),
+				namespaceSeparator,
+			)
+		} else {
+			prefix := c.key("")
+			_, err = c.exec(ctx,
+				fmt.Sprintf("DELETE FROM %s WHERE substr(%s, 1, ?) = ?", table.name, table.column),
+				len(prefix), prefix,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to invalidate %s: %w", table.name, err)
 		}
 	}
 	return nil
@@ -880,30 +1537,94 @@ func (c *SQLiteCache) Invalidate(ctx context.Context) error {
 
 // InvalidateRepo invalidates cache for a specific repository
 func (c *SQLiteCache) InvalidateRepo(ctx context.Context, owner, repo string) error {
-	_, err := c.db.ExecContext(ctx,
+	_, err := c.exec(ctx,
 		"DELETE FROM codeowners WHERE owner = ? AND repo = ?",
-		owner, repo,
+		c.key(owner), repo,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to invalidate codeowners: %w", err)
 	}
 
-	_, err = c.db.ExecContext(ctx,
+	_, err = c.exec(ctx,
 		"DELETE FROM prs WHERE owner = ? AND repo = ?",
-		owner, repo,
+		c.key(owner), repo,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to invalidate prs: %w", err)
 	}
 
-	_, err = c.db.ExecContext(ctx,
+	_, err = c.exec(ctx,
 		"DELETE FROM pr_files WHERE owner = ? AND repo = ?",
-		owner, repo,
+		c.key(owner), repo,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to invalidate pr_files: %w", err)
 	}
 
+	_, err = c.exec(ctx,
+		"DELETE FROM pr_reviews WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate pr_reviews: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM pr_commits WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate pr_commits: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM pr_review_events WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate pr_review_events: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM fetch_progress WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate fetch_progress: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM releases WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate releases: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM codeowners_parsed WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate codeowners_parsed: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM codeowners_at_commit WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate codeowners_at_commit: %w", err)
+	}
+
+	_, err = c.exec(ctx,
+		"DELETE FROM pr_teams WHERE owner = ? AND repo = ?",
+		c.key(owner), repo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate pr_teams: %w", err)
+	}
+
 	return nil
 }
 
@@ -911,3 +1632,59 @@ func (c *SQLiteCache) InvalidateRepo(ctx context.Context, owner, repo string) er
 func (c *SQLiteCache) Close() error {
 	return c.db.Close()
 }
+
+// exec runs a write statement, retrying it while the database is busy
+func (c *SQLiteCache) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := c.retryBusy(ctx, func() error {
+		var err error
+		result, err = c.db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// retryBusy runs a write, retrying it with backoff while SQLite reports the
+// database busy or locked. _busy_timeout absorbs most lock waits, but not the
+// ones SQLite gives up on immediately (e.g. to avoid a deadlock between writers).
+func (c *SQLiteCache) retryBusy(ctx context.Context, write func() error) error {
+	backoff := writeRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || attempt > writeRetries || !isBusy(err) {
+			return err
+		}
+
+		c.logger.Debug("SQLite database busy, retrying write",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isBusy reports whether err is SQLite's busy or locked error, which go away
+// once the other writer is done
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// Extended result codes keep the primary code in the low byte
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// key prefixes an org/owner key column value with the cache namespace
+func (c *SQLiteCache) key(value string) string {
+	if c.namespace == "" {
+		return value
+	}
+	return c.namespace + namespaceSeparator + value
+}
@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// TieredCache composes two caches: a fast near cache (e.g. a local SQLite file)
+// in front of a shared far cache. Reads check the near cache first and fall
+// back to the far cache, copying far hits into the near cache; writes and
+// invalidations go to both.
+type TieredCache struct {
+	near   Cache
+	far    Cache
+	logger *zap.Logger
+}
+
+// NewTieredCache creates a cache reading through near to far and writing to both
+func NewTieredCache(near, far Cache, logger *zap.Logger) *TieredCache {
+	return &TieredCache{
+		near:   near,
+		far:    far,
+		logger: logger,
+	}
+}
+
+// readThrough returns the near cache's entry, or else the far cache's entry
+// after copying it into the near cache. Empty entries count as misses, as they
+// do for the callers of the cache.
+func readThrough[T any](c *TieredCache, kind string, get func(Cache) (T, error), set func(Cache, T) error, empty func(T) bool) (T, error) {
+	if value, err := get(c.near); err == nil && !empty(value) {
+		return value, nil
+	}
+
+	value, err := get(c.far)
+	if err != nil || empty(value) {
+		return value, err
+	}
+
+	if err := set(c.near, value); err != nil {
+		c.logger.Warn("Failed to populate near cache", zap.String("kind", kind), zap.Error(err))
+	}
+	return value, nil
+}
+
+// writeBoth runs a write against both caches
+func (c *TieredCache) writeBoth(write func(Cache) error) error {
+	return errors.Join(write(c.near), write(c.far))
+}
+
+// GetRepos retrieves cached repositories enumerated with the given filter signature
+func (c *TieredCache) GetRepos(ctx context.Context, org, filter string) ([]*github.Repository, error) {
+	return readThrough(c, "repos",
+		func(t Cache) ([]*github.Repository, error) { return t.GetRepos(ctx, org, filter) },
+		func(t Cache, repos []*github.Repository) error { return t.SetRepos(ctx, org, filter, repos) },
+		func(repos []*github.Repository) bool { return len(repos) == 0 },
+	)
+}
+
+// SetRepos caches repositories enumerated with the given filter signature
+func (c *TieredCache) SetRepos(ctx context.Context, org, filter string, repos []*github.Repository) error {
+	return c.writeBoth(func(t Cache) error { return t.SetRepos(ctx, org, filter, repos) })
+}
+
+// GetCODEOWNERS retrieves cached CODEOWNERS file
+func (c *TieredCache) GetCODEOWNERS(ctx context.Context, owner, repo string) ([]byte, error) {
+	return readThrough(c, "codeowners",
+		func(t Cache) ([]byte, error) { return t.GetCODEOWNERS(ctx, owner, repo) },
+		func(t Cache, content []byte) error { return t.SetCODEOWNERS(ctx, owner, repo, content) },
+		func(content []byte) bool { return len(content) == 0 },
+	)
+}
+
+// SetCODEOWNERS caches CODEOWNERS file
+func (c *TieredCache) SetCODEOWNERS(ctx context.Context, owner, repo string, content []byte) error {
+	return c.writeBoth(func(t Cache) error { return t.SetCODEOWNERS(ctx, owner, repo, content) })
+}
+
+// GetParsedCODEOWNERS retrieves a parsed CODEOWNERS file cached under hash
+func (c *TieredCache) GetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string) ([]byte, error) {
+	return readThrough(c, "codeowners_parsed",
+		func(t Cache) ([]byte, error) { return t.GetParsedCODEOWNERS(ctx, owner, repo, hash) },
+		func(t Cache, parsed []byte) error { return t.SetParsedCODEOWNERS(ctx, owner, repo, hash, parsed) },
+		func(parsed []byte) bool { return len(parsed) == 0 },
+	)
+}
+
+// SetParsedCODEOWNERS caches a parsed CODEOWNERS file under hash
+func (c *TieredCache) SetParsedCODEOWNERS(ctx context.Context, owner, repo, hash string, parsed []byte) error {
+	return c.writeBoth(func(t Cache) error { return t.SetParsedCODEOWNERS(ctx, owner, repo, hash, parsed) })
+}
+
+// GetCODEOWNERSAtCommit retrieves the cached CODEOWNERS file as of a commit
+func (c *TieredCache) GetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string) ([]byte, error) {
+	return readThrough(c, "codeowners_at_commit",
+		func(t Cache) ([]byte, error) { return t.GetCODEOWNERSAtCommit(ctx, owner, repo, sha) },
+		func(t Cache, content []byte) error { return t.SetCODEOWNERSAtCommit(ctx, owner, repo, sha, content) },
+		func(content []byte) bool { return len(content) == 0 },
+	)
+}
+
+// SetCODEOWNERSAtCommit caches the CODEOWNERS file as of a commit
+func (c *TieredCache) SetCODEOWNERSAtCommit(ctx context.Context, owner, repo, sha string, content []byte) error {
+	return c.writeBoth(func(t Cache) error { return t.SetCODEOWNERSAtCommit(ctx, owner, repo, sha, content) })
+}
+
+// GetPRs retrieves cached PRs for a repository, filtered by time window
+func (c *TieredCache) GetPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	return readThrough(c, "prs",
+		func(t Cache) ([]*github.PullRequest, error) { return t.GetPRs(ctx, owner, repo, since, until) },
+		func(t Cache, prs []*github.PullRequest) error { return t.SetPRs(ctx, owner, repo, prs) },
+		func(prs []*github.PullRequest) bool { return len(prs) == 0 },
+	)
+}
+
+// SetPRs caches PRs for a repository (stores individual PRs by ID)
+func (c *TieredCache) SetPRs(ctx context.Context, owner, repo string, prs []*github.PullRequest) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRs(ctx, owner, repo, prs) })
+}
+
+// GetPRFiles retrieves cached PR files
+func (c *TieredCache) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
+	return readThrough(c, "pr_files",
+		func(t Cache) ([]*github.CommitFile, error) { return t.GetPRFiles(ctx, owner, repo, prNumber) },
+		func(t Cache, files []*github.CommitFile) error {
+			return t.SetPRFiles(ctx, owner, repo, prNumber, files)
+		},
+		func(files []*github.CommitFile) bool { return len(files) == 0 },
+	)
+}
+
+// SetPRFiles caches PR files
+func (c *TieredCache) SetPRFiles(ctx context.Context, owner, repo string, prNumber int, files []*github.CommitFile) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRFiles(ctx, owner, repo, prNumber, files) })
+}
+
+// GetPRReviews retrieves cached PR reviews. A PR without reviews is a valid
+// entry, so only errors count as misses.
+func (c *TieredCache) GetPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	return readThrough(c, "pr_reviews",
+		func(t Cache) ([]*github.PullRequestReview, error) { return t.GetPRReviews(ctx, owner, repo, prNumber) },
+		func(t Cache, reviews []*github.PullRequestReview) error {
+			return t.SetPRReviews(ctx, owner, repo, prNumber, reviews)
+		},
+		func([]*github.PullRequestReview) bool { return false },
+	)
+}
+
+// SetPRReviews caches PR reviews
+func (c *TieredCache) SetPRReviews(ctx context.Context, owner, repo string, prNumber int, reviews []*github.PullRequestReview) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRReviews(ctx, owner, repo, prNumber, reviews) })
+}
+
+// GetPRCommits retrieves cached PR commits. Like reviews, only errors count as misses.
+func (c *TieredCache) GetPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	return readThrough(c, "pr_commits",
+		func(t Cache) ([]*github.RepositoryCommit, error) { return t.GetPRCommits(ctx, owner, repo, prNumber) },
+		func(t Cache, commits []*github.RepositoryCommit) error {
+			return t.SetPRCommits(ctx, owner, repo, prNumber, commits)
+		},
+		func([]*github.RepositoryCommit) bool { return false },
+	)
+}
+
+// SetPRCommits caches PR commits
+func (c *TieredCache) SetPRCommits(ctx context.Context, owner, repo string, prNumber int, commits []*github.RepositoryCommit) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRCommits(ctx, owner, repo, prNumber, commits) })
+}
+
+// GetPRReviewEvents retrieves the cached review events of a PR's timeline.
+// Like reviews, only errors count as misses.
+func (c *TieredCache) GetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	return readThrough(c, "pr_review_events",
+		func(t Cache) ([]*github.Timeline, error) { return t.GetPRReviewEvents(ctx, owner, repo, prNumber) },
+		func(t Cache, events []*github.Timeline) error {
+			return t.SetPRReviewEvents(ctx, owner, repo, prNumber, events)
+		},
+		func([]*github.Timeline) bool { return false },
+	)
+}
+
+// SetPRReviewEvents caches the review events of a PR's timeline
+func (c *TieredCache) SetPRReviewEvents(ctx context.Context, owner, repo string, prNumber int, events []*github.Timeline) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRReviewEvents(ctx, owner, repo, prNumber, events) })
+}
+
+// GetFetchProgress retrieves the progress of an interrupted PR fetch
+func (c *TieredCache) GetFetchProgress(ctx context.Context, owner, repo string) (*FetchProgress, error) {
+	return readThrough(c, "fetch_progress",
+		func(t Cache) (*FetchProgress, error) { return t.GetFetchProgress(ctx, owner, repo) },
+		func(t Cache, progress *FetchProgress) error { return t.SetFetchProgress(ctx, owner, repo, progress) },
+		func(progress *FetchProgress) bool { return progress == nil },
+	)
+}
+
+// SetFetchProgress records the progress of an in-flight PR fetch
+func (c *TieredCache) SetFetchProgress(ctx context.Context, owner, repo string, progress *FetchProgress) error {
+	return c.writeBoth(func(t Cache) error { return t.SetFetchProgress(ctx, owner, repo, progress) })
+}
+
+// ClearFetchProgress removes the progress of a completed PR fetch
+func (c *TieredCache) ClearFetchProgress(ctx context.Context, owner, repo string) error {
+	return c.writeBoth(func(t Cache) error { return t.ClearFetchProgress(ctx, owner, repo) })
+}
+
+// GetPRTeams retrieves the owning teams of a repository's PRs cached under key
+func (c *TieredCache) GetPRTeams(ctx context.Context, owner, repo, key string) (map[int][]string, error) {
+	return readThrough(c, "pr_teams",
+		func(t Cache) (map[int][]string, error) { return t.GetPRTeams(ctx, owner, repo, key) },
+		func(t Cache, teams map[int][]string) error { return t.SetPRTeams(ctx, owner, repo, key, teams) },
+		func(teams map[int][]string) bool { return len(teams) == 0 },
+	)
+}
+
+// SetPRTeams caches the owning teams of a repository's PRs under key
+func (c *TieredCache) SetPRTeams(ctx context.Context, owner, repo, key string, teams map[int][]string) error {
+	return c.writeBoth(func(t Cache) error { return t.SetPRTeams(ctx, owner, repo, key, teams) })
+}
+
+// GetReleases retrieves the cached releases of a repository. A repository
+// without releases is a valid entry, so only errors count as misses.
+func (c *TieredCache) GetReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	return readThrough(c, "releases",
+		func(t Cache) ([]*github.RepositoryRelease, error) { return t.GetReleases(ctx, owner, repo) },
+		func(t Cache, releases []*github.RepositoryRelease) error {
+			return t.SetReleases(ctx, owner, repo, releases)
+		},
+		func([]*github.RepositoryRelease) bool { return false },
+	)
+}
+
+// SetReleases caches the releases of a repository
+func (c *TieredCache) SetReleases(ctx context.Context, owner, repo string, releases []*github.RepositoryRelease) error {
+	return c.writeBoth(func(t Cache) error { return t.SetReleases(ctx, owner, repo, releases) })
+}
+
+// GetUser retrieves a cached user profile
+func (c *TieredCache) GetUser(ctx context.Context, login string) (*github.User, error) {
+	return readThrough(c, "user",
+		func(t Cache) (*github.User, error) { return t.GetUser(ctx, login) },
+		func(t Cache, user *github.User) error { return t.SetUser(ctx, login, user) },
+		func(user *github.User) bool { return user == nil },
+	)
+}
+
+// SetUser caches a user profile
+func (c *TieredCache) SetUser(ctx context.Context, login string, user *github.User) error {
+	return c.writeBoth(func(t Cache) error { return t.SetUser(ctx, login, user) })
+}
+
+// Invalidate invalidates all entries of both caches
+func (c *TieredCache) Invalidate(ctx context.Context) error {
+	return c.writeBoth(func(t Cache) error { return t.Invalidate(ctx) })
+}
+
+// InvalidateRepo invalidates a repository in both caches
+func (c *TieredCache) InvalidateRepo(ctx context.Context, owner, repo string) error {
+	return c.writeBoth(func(t Cache) error { return t.InvalidateRepo(ctx, owner, repo) })
+}
+
+// Close closes both caches
+func (c *TieredCache) Close() error {
+	return c.writeBoth(func(t Cache) error { return t.Close() })
+}
@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// holidayLayout is the date format of configured holidays
+const holidayLayout = "2006-01-02"
+
+// Calendar measures durations between timestamps, optionally counting only
+// business time: weekends and holidays are left out. Days are taken in the
+// location of the start time (UTC for GitHub timestamps). A nil Calendar
+// measures plain wall-clock durations.
+type Calendar struct {
+	holidays map[string]bool // Keyed by YYYY-MM-DD
+}
+
+// NewCalendar creates a calendar. Without businessDaysOnly it returns nil,
+// which measures wall-clock durations; holidays are dates (YYYY-MM-DD) that
+// count as non-business days.
+func NewCalendar(businessDaysOnly bool, holidays []string) (*Calendar, error) {
+	if !businessDaysOnly {
+		return nil, nil
+	}
+
+	c := &Calendar{holidays: make(map[string]bool, len(holidays))}
+	for _, holiday := range holidays {
+		if _, err := time.Parse(holidayLayout, holiday); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q (must be YYYY-MM-DD): %w", holiday, err)
+		}
+		c.holidays[holiday] = true
+	}
+	return c, nil
+}
+
+// Duration returns the time from start to end, counting only business days
+// unless the calendar is nil. A Friday-to-Monday span at the same time of day
+// is one business day. An end before start yields zero.
+func (c *Calendar) Duration(start, end time.Time) time.Duration {
+	if c == nil {
+		return end.Sub(start)
+	}
+
+	var total time.Duration
+	end = end.In(start.Location())
+	for cur := start; cur.Before(end); {
+		next := time.Date(cur.Year(), cur.Month(), cur.Day()+1, 0, 0, 0, 0, cur.Location())
+		if next.After(end) {
+			next = end
+		}
+		if c.isBusinessDay(cur) {
+			total += next.Sub(cur)
+		}
+		cur = next
+	}
+	return total
+}
+
+// isBusinessDay reports whether t falls on a weekday that is not a holiday
+func (c *Calendar) isBusinessDay(t time.Time) bool {
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !c.holidays[t.Format(holidayLayout)]
+}
@@ -0,0 +1,54 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC) } // Jan 3 2025 is a Friday
+	day := 24 * time.Hour
+
+	c, err := NewCalendar(true, []string{"2025-01-01", "2025-01-08"})
+	if err != nil {
+		t.Fatalf("NewCalendar() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       time.Duration
+	}{
+		{"within a weekday", at(6, 9), at(6, 17), 8 * time.Hour},
+		{"friday to monday", at(3, 10), at(6, 10), day},
+		{"friday evening to monday morning", at(3, 17), at(6, 9), 16 * time.Hour},
+		{"opened on saturday", at(4, 12), at(6, 12), 12 * time.Hour},
+		{"entirely on a weekend", at(4, 9), at(5, 18), 0},
+		{"spanning two weekends", at(3, 0), at(13, 0), 5 * day},
+		{"skips a holiday", at(7, 12), at(9, 12), day},
+		{"end before start", at(6, 10), at(6, 9), 0},
+	}
+	for _, tt := range tests {
+		if got := c.Duration(tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: Duration() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNilCalendarMeasuresWallClock(t *testing.T) {
+	c, err := NewCalendar(false, []string{"2025-01-06"})
+	if err != nil || c != nil {
+		t.Fatalf("NewCalendar(false) = %v, %v, want nil, nil", c, err)
+	}
+
+	start := time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)
+	if got, want := c.Duration(start, start.Add(72*time.Hour)), 72*time.Hour; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+}
+
+func TestNewCalendarRejectsInvalidHolidays(t *testing.T) {
+	if _, err := NewCalendar(true, []string{"01/06/2025"}); err == nil {
+		t.Error("NewCalendar() accepted a holiday that is not YYYY-MM-DD")
+	}
+}
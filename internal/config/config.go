@@ -2,66 +2,149 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// PRRefPattern matches a PR reference like owner/repo#123
+var PRRefPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+
+// repoRefPattern matches a repository reference like owner/repo
+var repoRefPattern = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// namespacePattern matches a cache namespace, which the JSON backend uses as a
+// directory name and the SQLite backend as a key prefix
+var namespacePattern = regexp.MustCompile(`^[\w.-]+$`)
+
+// relativeTimePattern matches a time relative to now: now, now-30d, -4w or 12h
+var relativeTimePattern = regexp.MustCompile(`^(now)?(?:([+-])?(\d+)([hdw]))?$`)
+
 // Config holds the application configuration
 type Config struct {
-	GitHub       GitHubConfig       `mapstructure:"github"`
-	TimeWindow   TimeWindowConfig   `mapstructure:"time_window"`
-	Filters      FiltersConfig      `mapstructure:"filters"`
-	Attribution  AttributionConfig  `mapstructure:"attribution"`
-	Cache        CacheConfig        `mapstructure:"cache"`
-	RateLimiter  RateLimiterConfig  `mapstructure:"rate_limiter"`
-	Output       OutputConfig       `mapstructure:"output"`
-	Logging      LoggingConfig      `mapstructure:"logging"`
-	Concurrency  ConcurrencyConfig  `mapstructure:"concurrency"`
-	TeamRollup   []TeamRollupConfig `mapstructure:"team_rollup"`
+	GitHub        GitHubConfig         `mapstructure:"github"`
+	TimeWindow    TimeWindowConfig     `mapstructure:"time_window"`
+	Filters       FiltersConfig        `mapstructure:"filters"`
+	Attribution   AttributionConfig    `mapstructure:"attribution"`
+	Cache         CacheConfig          `mapstructure:"cache"`
+	RateLimiter   RateLimiterConfig    `mapstructure:"rate_limiter"`
+	Output        OutputConfig         `mapstructure:"output"`
+	Logging       LoggingConfig        `mapstructure:"logging"`
+	Concurrency   ConcurrencyConfig    `mapstructure:"concurrency"`
+	Fetch         FetchConfig          `mapstructure:"fetch"`
+	CODEOWNERS    CODEOWNERSConfig     `mapstructure:"codeowners"`
+	TeamRollup    []TeamRollupConfig   `mapstructure:"team_rollup"`
+	BusinessUnits []BusinessUnitConfig `mapstructure:"business_units"`
+	Categories    []CategoryConfig     `mapstructure:"categories"`
+	Tracing       TracingConfig        `mapstructure:"tracing"`
+	Scoring       ScoringConfig        `mapstructure:"scoring"`
+	Notify        NotifyConfig         `mapstructure:"notify"`
+	Metrics       MetricsConfig        `mapstructure:"metrics"`
+	IdentityMap   map[string]string    `mapstructure:"identity_map"` // Canonical identity by aliased login, merging the accounts of one person
+
+	RepoOverrides map[string]RepoOverrideConfig `mapstructure:"repo_overrides"` // Attribution and filter settings by owner/repo, merged over the global ones
+
+	startedAt time.Time // Time relative time window bounds are resolved against
+}
+
+// RepoOverrideConfig holds the settings replacing the global ones for a single
+// repository. Unset fields keep the global value.
+type RepoOverrideConfig struct {
+	AttributionMode string              `mapstructure:"attribution_mode"` // Replaces attribution.mode
+	ExcludePaths    []string            `mapstructure:"exclude_paths"`    // Globs of changed paths left out of attribution
+	Filters         RepoFiltersOverride `mapstructure:"filters"`
+}
+
+// RepoFiltersOverride holds the filters a repository override can replace;
+// nil fields keep the global filter
+type RepoFiltersOverride struct {
+	ExcludeAuthors       []string `mapstructure:"exclude_authors"`
+	ExcludeTitlePrefixes []string `mapstructure:"exclude_title_prefixes"`
+	ExcludeReverts       *bool    `mapstructure:"exclude_reverts"`
 }
 
 // GitHubConfig holds GitHub API configuration
 type GitHubConfig struct {
 	Org         string `mapstructure:"org"`
+	Repo        string `mapstructure:"repo"` // Analyze only this owner/repo instead of the organization's repositories
 	TokenEnvVar string `mapstructure:"token_env_var"`
 }
 
 // TimeWindowConfig holds the time window for PR analysis
 type TimeWindowConfig struct {
-	Since string `mapstructure:"since"`
-	Until string `mapstructure:"until"`
+	Since        string `mapstructure:"since"`         // RFC3339, or relative to the run start like "-30d" or "now-4w"
+	Until        string `mapstructure:"until"`         // Like since; defaults to the run start time when empty
+	InclusiveEnd bool   `mapstructure:"inclusive_end"` // Also count PRs closed exactly at until (the window is [since, until) otherwise)
 }
 
 // FiltersConfig holds filter configuration
 type FiltersConfig struct {
-	ExcludeAuthors      []string `mapstructure:"exclude_authors"`
+	ExcludeAuthors       []string `mapstructure:"exclude_authors"`
 	ExcludeTitlePrefixes []string `mapstructure:"exclude_title_prefixes"`
+	ExcludeReverts       bool     `mapstructure:"exclude_reverts"`     // Drop revert PRs and, best-effort, the PRs they revert
+	ExcludeSelfMerged    bool     `mapstructure:"exclude_self_merged"` // Drop PRs merged by their own author (requires fetch.pr_details)
+	MeaningfulOnly       bool     `mapstructure:"meaningful_only"`     // Drop PRs whose changed files all match TrivialPaths
+	TrivialPaths         []string `mapstructure:"trivial_paths"`       // Globs of paths that alone do not make a PR meaningful work
+	ExcludePRNumbers     []string `mapstructure:"exclude_pr_numbers"`  // Individual PRs to drop, as owner/repo#number
+	BaseBranchRegexes    []string `mapstructure:"base_branch_regexes"` // Keep only PRs whose base branch matches one of these regexes; empty keeps all
+	MaxChangedFiles      int      `mapstructure:"max_changed_files"`   // Drop PRs changing more files than this as mass changes (requires fetch.pr_details); 0 = no limit
+	IncludeRepos         []string `mapstructure:"include_repos"`       // Globs of repository names to analyze; empty analyzes all
+	ExcludeRepos         []string `mapstructure:"exclude_repos"`       // Globs of repository names to skip, taking precedence over IncludeRepos
+	IncludeArchived      bool     `mapstructure:"include_archived"`    // Also analyze archived repositories (disabled ones are always skipped)
 }
 
 // AttributionConfig holds attribution mode configuration
 type AttributionConfig struct {
-	Mode string `mapstructure:"mode"` // "multi" | "primary" | "first-owner-only"
+	Enabled                bool     `mapstructure:"enabled"`                   // Attribute PRs to teams; false skips CODEOWNERS and PR file fetching
+	Mode                   string   `mapstructure:"mode"`                      // "multi" | "primary" | "first-owner-only"
+	NoCodeownersFileBucket string   `mapstructure:"no_codeowners_file_bucket"` // Team bucket of PRs in repos without a CODEOWNERS file
+	UnmatchedPathsBucket   string   `mapstructure:"unmatched_paths_bucket"`    // Team bucket of PRs whose paths matched no CODEOWNERS rule
+	GhostAuthorBucket      string   `mapstructure:"ghost_author_bucket"`       // User bucket of PRs whose author account was deleted
+	CountRollupMembers     bool     `mapstructure:"count_rollup_members"`      // Also count teams in a rollup under their own name
+	IgnoreCatchall         bool     `mapstructure:"ignore_catchall"`           // Treat files only a catch-all CODEOWNERS rule matches as unowned
+	CatchallPatterns       []string `mapstructure:"catchall_patterns"`         // CODEOWNERS patterns that count as catch-alls with ignore_catchall
+	PointInTime            bool     `mapstructure:"point_in_time"`             // Attribute merged PRs with the CODEOWNERS of their merge commit
 }
 
 // CacheConfig holds cache configuration
 type CacheConfig struct {
-	Backend     string `mapstructure:"backend"` // "sqlite" | "json"
-	SQLitePath  string `mapstructure:"sqlite_path"`
-	JSONDir     string `mapstructure:"json_dir"`
-	TTLMinutes  int    `mapstructure:"ttl_minutes"`
+	Backend    string          `mapstructure:"backend"` // "sqlite" | "json" | "memory" | "tiered"
+	SQLitePath string          `mapstructure:"sqlite_path"`
+	JSONDir    string          `mapstructure:"json_dir"`
+	JSONLayout string          `mapstructure:"json_layout"` // Per-PR entries of the JSON backend: "files" (one file each) | "ndjson" (one file per repo and entity)
+	TTLMinutes int             `mapstructure:"ttl_minutes"`
+	Namespace  string          `mapstructure:"namespace"`   // Isolates entries of different environments sharing one backend
+	SlimPRs    bool            `mapstructure:"slim_prs"`    // Cache only the PR fields the analysis uses instead of the full object
+	SnapshotAt string          `mapstructure:"snapshot_at"` // RFC3339; reads ignore entries written after it
+	Near       CacheTierConfig `mapstructure:"near"`        // Backend read first by the tiered cache
+	Far        CacheTierConfig `mapstructure:"far"`         // Backend behind the near one in the tiered cache
+}
+
+// CacheTierConfig holds the backend configuration of one tier of the tiered cache
+type CacheTierConfig struct {
+	Backend    string `mapstructure:"backend"` // "sqlite" | "json"
+	SQLitePath string `mapstructure:"sqlite_path"`
+	JSONDir    string `mapstructure:"json_dir"`
 }
 
 // RateLimiterConfig holds rate limiter configuration
 type RateLimiterConfig struct {
-	Type        string `mapstructure:"type"` // "token-bucket"
-	QPS         int    `mapstructure:"qps"`
-	Burst       int    `mapstructure:"burst"`
-	Retry       RetryConfig `mapstructure:"retry"`
-	Threshold   int    `mapstructure:"threshold"`   // Rate limit threshold to trigger sleep
-	SleepMinutes int   `mapstructure:"sleep_minutes"` // Minutes to sleep when threshold is reached
+	Type             string      `mapstructure:"type"` // "token-bucket"
+	QPS              int         `mapstructure:"qps"`
+	Burst            int         `mapstructure:"burst"`
+	SearchQPS        float64     `mapstructure:"search_qps"`   // Queries per second of the search API, which has its own, lower limit
+	SearchBurst      int         `mapstructure:"search_burst"` // Burst size of the search API
+	Retry            RetryConfig `mapstructure:"retry"`
+	Threshold        int         `mapstructure:"threshold"`           // Rate limit threshold to trigger sleep
+	SleepMinutes     int         `mapstructure:"sleep_minutes"`       // Minutes to sleep when threshold is reached
+	InterRepoDelayMs int         `mapstructure:"inter_repo_delay_ms"` // Pause between starting repositories, smoothing bursts that trip secondary limits; 0 = disabled
 }
 
 // RetryConfig holds retry configuration
@@ -72,8 +155,27 @@ type RetryConfig struct {
 
 // OutputConfig holds output configuration
 type OutputConfig struct {
-	Format    string `mapstructure:"format"` // "json" | "csv"
-	OutputDir string `mapstructure:"output_dir"`
+	Format          string   `mapstructure:"format"` // "json" | "csv" | "sqlite"
+	OutputDir       string   `mapstructure:"output_dir"`
+	PRSizeBuckets   []int    `mapstructure:"pr_size_buckets"`    // Inclusive upper bounds (lines changed) of the PR size histogram buckets
+	CrossTab        bool     `mapstructure:"cross_tab"`          // Export the author × team PR count matrix
+	Detailed        bool     `mapstructure:"detailed"`           // Export teams_detail.json, each team with its repos and users nested
+	FilesTouched    bool     `mapstructure:"files_touched"`      // Count the distinct files each team owns among the changed files
+	HTMLReport      bool     `mapstructure:"html_report"`        // Export all breakdowns as a single report.html
+	ReportSections  []string `mapstructure:"report_sections"`    // Sections of report.html; empty means all
+	Breakdowns      []string `mapstructure:"breakdowns"`         // Breakdowns written by the CSV and summary exporters; empty means all
+	Combined        bool     `mapstructure:"combined"`           // Also export everything as a single report.json
+	JSONCompact     bool     `mapstructure:"json_compact"`       // Write JSON files without indentation
+	EffectiveConfig bool     `mapstructure:"effective_config"`   // Write the resolved configuration to effective_config.yaml
+	DetailedCSV     bool     `mapstructure:"detailed_csv"`       // Stream one row per PR, with its teams, to prs.csv
+	IncludePRBody   bool     `mapstructure:"include_pr_body"`    // Include PR bodies in prs_by_repo.json
+	PRBodyMaxLength int      `mapstructure:"pr_body_max_length"` // Characters of a PR body kept with include_pr_body; 0 means no limit
+	AllowEmpty      bool     `mapstructure:"allow_empty"`        // Export an empty result instead of failing when no repositories are found
+	DumpRepoResults string   `mapstructure:"dump_repo_results"`  // Write the processed repositories to this file, for re-running aggregation
+	LoadRepoResults string   `mapstructure:"load_repo_results"`  // Aggregate the processed repositories of this file instead of fetching
+	ResultsDB       string   `mapstructure:"results_db"`         // SQLite database each run is appended to with format sqlite; empty means results.db in output_dir
+	Locale          string   `mapstructure:"locale"`             // Locale of dates and numbers in the summary and Markdown, e.g. "de-DE"; empty keeps ISO dates and plain numbers
+	Timezone        string   `mapstructure:"timezone"`           // IANA time zone of timestamps in the summary, Markdown and summary.csv; empty keeps them as they are
 }
 
 // LoggingConfig holds logging configuration
@@ -83,7 +185,86 @@ type LoggingConfig struct {
 
 // ConcurrencyConfig holds concurrency configuration
 type ConcurrencyConfig struct {
-	RepoWorkers int `mapstructure:"repo_workers"`
+	RepoWorkers        int    `mapstructure:"repo_workers"`
+	FileWorkersPerRepo int    `mapstructure:"file_workers_per_repo"` // Concurrent PR file fetches within one repository
+	RepoRetries        int    `mapstructure:"repo_retries"`          // Re-attempts of a repository that failed transiently
+	SortRepos          bool   `mapstructure:"sort_repos"`            // Process repositories in full-name order for reproducible logs
+	LimitRepos         int    `mapstructure:"limit_repos"`           // Process only the first N repositories by full name, for smoke tests (0 = all)
+	Priority           string `mapstructure:"priority"`              // "none" | "activity": start the most recently pushed repositories first
+}
+
+// FetchConfig holds configuration for what is fetched from the GitHub API
+type FetchConfig struct {
+	Mode         string `mapstructure:"mode"`          // "list" | "search"
+	WindowSplits int    `mapstructure:"window_splits"` // Sub-windows the time window is split into and searched concurrently (search mode only)
+	PRState      string `mapstructure:"pr_state"`      // "closed" | "merged" | "all": which PRs are analyzed; all adds open PRs updated within the window, counted apart
+	PRDetails    bool   `mapstructure:"pr_details"`    // Fetch each PR individually for fields the list endpoint omits (commits, additions, ...)
+	Reviews      bool   `mapstructure:"reviews"`       // Fetch the reviews of each PR
+	ReviewEvents bool   `mapstructure:"review_events"` // Fetch the review events of each PR's timeline to measure review churn
+	UserDetails  bool   `mapstructure:"user_details"`  // Fetch each author's profile for company attribution
+	Commits      bool   `mapstructure:"commits"`       // Fetch the commits of each PR to credit Co-authored-by co-authors
+	Releases     bool   `mapstructure:"releases"`      // Fetch the releases of each repository for merge-to-release lead time
+}
+
+// CODEOWNERSConfig holds CODEOWNERS resolution configuration
+type CODEOWNERSConfig struct {
+	SourceRepo          string            `mapstructure:"source_repo"`           // owner/repo whose CODEOWNERS applies to repos without their own
+	EnforceGitHubLimits bool              `mapstructure:"enforce_github_limits"` // Drop the rules GitHub ignores because the file exceeds its size limit
+	MaxBytes            int               `mapstructure:"max_bytes"`             // Largest CODEOWNERS file parsed; repos with a larger one are treated as having none (0 = no limit)
+	LocalFile           string            `mapstructure:"local_file"`            // Local CODEOWNERS file used for every repo instead of the API
+	LocalFiles          map[string]string `mapstructure:"local_files"`           // Local CODEOWNERS file by owner/repo, taking precedence over local_file
+	MinCoverage         float64           `mapstructure:"min_coverage"`          // Warn about repos where CODEOWNERS matches fewer of the changed files (0-1; 0 = off)
+	FailOnLowCoverage   bool              `mapstructure:"fail_on_low_coverage"`  // Fail the run, after exporting, when a repo is below min_coverage
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"` // OTLP/HTTP collector URL (e.g. http://localhost:4318); tracing is off when empty
+}
+
+// MetricsConfig holds how duration metrics are computed
+type MetricsConfig struct {
+	BusinessDaysOnly  bool     `mapstructure:"business_days_only"`  // Leave weekends and holidays out of durations
+	Holidays          []string `mapstructure:"holidays"`            // Non-business dates (YYYY-MM-DD) with business_days_only
+	MinContributorPRs int      `mapstructure:"min_contributor_prs"` // PRs an author needs to count in the PRs-per-contributor statistics
+
+	ExternalCommand        string `mapstructure:"external_command"`         // Command (run with sh -c) PRs are piped to as JSON lines, printing one result per PR; empty = off
+	ExternalBatchSize      int    `mapstructure:"external_batch_size"`      // PRs piped to one run of external_command
+	ExternalTimeoutSeconds int    `mapstructure:"external_timeout_seconds"` // Time a run of external_command may take before it is killed
+}
+
+// NotifyConfig holds where results are published after a run
+type NotifyConfig struct {
+	GitHubIssue string `mapstructure:"github_issue"` // owner/repo#number of an issue to post the Markdown summary to
+}
+
+// ScoringConfig holds PR scoring configuration. Scores are only computed when
+// rules are configured; PR counts remain the primary metric.
+type ScoringConfig struct {
+	Default float64             `mapstructure:"default"` // Score of a PR that matches no rule
+	Rules   []ScoringRuleConfig `mapstructure:"rules"`
+}
+
+// ScoringRuleConfig holds a scoring rule. A PR matches when it meets every
+// condition that is set; a PR's score is the sum of the rules it matches.
+type ScoringRuleConfig struct {
+	Label       string  `mapstructure:"label"`        // PR carries this label (case-insensitive)
+	TitlePrefix string  `mapstructure:"title_prefix"` // PR title starts with this prefix (case-insensitive)
+	MinLines    int     `mapstructure:"min_lines"`    // At least this many lines changed (requires fetch.pr_details)
+	MaxLines    int     `mapstructure:"max_lines"`    // At most this many lines changed (requires fetch.pr_details)
+	Score       float64 `mapstructure:"score"`
+}
+
+// BusinessUnitConfig maps repositories to a business unit (cost center)
+type BusinessUnitConfig struct {
+	Name  string   `mapstructure:"name"`
+	Repos []string `mapstructure:"repos"` // Globs of repository names (owner/name, or name alone)
+}
+
+// CategoryConfig maps PR labels to a category of work (feature, bug, chore, ...)
+type CategoryConfig struct {
+	Name   string   `mapstructure:"name"`
+	Labels []string `mapstructure:"labels"` // Globs of label names, matched case-insensitively
 }
 
 // TeamRollupConfig holds team rollup configuration
@@ -92,20 +273,25 @@ type TeamRollupConfig struct {
 	Teams []string `mapstructure:"teams"`
 }
 
-// LoadConfig loads configuration from file and environment
-func LoadConfig(configPath string, logger *zap.Logger) (*Config, error) {
+// LoadConfig loads configuration from files and environment. The files are
+// merged in order, so a later file overrides the keys it sets in earlier ones.
+func LoadConfig(configPaths []string, logger *zap.Logger) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
 	setDefaults(v)
 
-	// Set config file
-	if configPath != "" {
+	// Read the first config file and merge the rest over it
+	for i, configPath := range configPaths {
 		v.SetConfigFile(configPath)
-		if err := v.ReadInConfig(); err != nil {
-			logger.Warn("Failed to read config file, using defaults", zap.Error(err))
+		read := v.MergeInConfig
+		if i == 0 {
+			read = v.ReadInConfig
+		}
+		if err := read(); err != nil {
+			logger.Warn("Failed to read config file, skipping it", zap.String("path", configPath), zap.Error(err))
 		} else {
-			logger.Info("Using config file", zap.String("path", v.ConfigFileUsed()))
+			logger.Info("Using config file", zap.String("path", configPath))
 		}
 	}
 
@@ -121,80 +307,463 @@ func LoadConfig(configPath string, logger *zap.Logger) (*Config, error) {
 	}
 
 	// Validate and set defaults
+	untilDefaulted := cfg.TimeWindow.Until == ""
 	if err := validateAndSetDefaults(&cfg); err != nil {
 		return nil, err
 	}
+	if untilDefaulted {
+		logger.Info("time_window.until not set, using the run start time", zap.String("until", cfg.TimeWindow.Until))
+	}
 
 	return &cfg, nil
 }
 
 func setDefaults(v *viper.Viper) {
 	// GitHub defaults
+	v.SetDefault("github.repo", "")
 	v.SetDefault("github.token_env_var", "GITHUB_TOKEN")
 
+	// Time window defaults
+	v.SetDefault("time_window.inclusive_end", false)
+
 	// Attribution defaults
+	v.SetDefault("attribution.enabled", true)
 	v.SetDefault("attribution.mode", "multi")
+	v.SetDefault("attribution.no_codeowners_file_bucket", "no_codeowners_file")
+	v.SetDefault("attribution.unmatched_paths_bucket", "unmatched_paths")
+	v.SetDefault("attribution.ghost_author_bucket", "ghost")
+	v.SetDefault("attribution.count_rollup_members", false)
+	v.SetDefault("attribution.ignore_catchall", false)
+	v.SetDefault("attribution.catchall_patterns", []string{"*", "**"})
+	v.SetDefault("attribution.point_in_time", false)
+
+	// Filter defaults
+	v.SetDefault("filters.exclude_reverts", false)
+	v.SetDefault("filters.exclude_self_merged", false)
+	v.SetDefault("filters.exclude_pr_numbers", []string{})
+	v.SetDefault("filters.base_branch_regexes", []string{})
+	v.SetDefault("filters.max_changed_files", 0)
+	v.SetDefault("filters.include_repos", []string{})
+	v.SetDefault("filters.exclude_repos", []string{})
+	v.SetDefault("filters.include_archived", false)
+	v.SetDefault("filters.meaningful_only", false)
+	v.SetDefault("filters.trivial_paths", []string{"*.md", "docs/**", "*.yaml", "*.yml"})
 
 	// Cache defaults
 	v.SetDefault("cache.backend", "sqlite")
 	v.SetDefault("cache.sqlite_path", "./cache.db")
 	v.SetDefault("cache.json_dir", "./cache")
+	v.SetDefault("cache.json_layout", "files")
 	v.SetDefault("cache.ttl_minutes", 1440)
+	v.SetDefault("cache.slim_prs", false)
+	v.SetDefault("cache.snapshot_at", "")
+	v.SetDefault("cache.near.backend", "sqlite")
+	v.SetDefault("cache.near.sqlite_path", "./cache.db")
+	v.SetDefault("cache.near.json_dir", "./cache")
+	v.SetDefault("cache.far.backend", "json")
+	v.SetDefault("cache.far.sqlite_path", "./cache-far.db")
+	v.SetDefault("cache.far.json_dir", "./cache-far")
 
 	// Rate limiter defaults
 	v.SetDefault("rate_limiter.type", "token-bucket")
 	v.SetDefault("rate_limiter.qps", 2)
 	v.SetDefault("rate_limiter.burst", 20)
+	v.SetDefault("rate_limiter.search_qps", 0.5) // GitHub allows 30 searches a minute
+	v.SetDefault("rate_limiter.search_burst", 5)
 	v.SetDefault("rate_limiter.retry.max_attempts", 5)
 	v.SetDefault("rate_limiter.retry.base_delay_ms", 500)
-	v.SetDefault("rate_limiter.threshold", 0)        // 0 = disabled
-	v.SetDefault("rate_limiter.sleep_minutes", 60)   // Default 60 minutes
+	v.SetDefault("rate_limiter.threshold", 0)      // 0 = disabled
+	v.SetDefault("rate_limiter.sleep_minutes", 60) // Default 60 minutes
+	v.SetDefault("rate_limiter.inter_repo_delay_ms", 0)
 
 	// Output defaults
 	v.SetDefault("output.format", "json")
 	v.SetDefault("output.output_dir", "./out")
+	v.SetDefault("output.results_db", "")
+	v.SetDefault("output.locale", "")
+	v.SetDefault("output.timezone", "")
+	v.SetDefault("output.pr_size_buckets", []int{10, 100, 500, 1000})
+	v.SetDefault("output.cross_tab", false)
+	v.SetDefault("output.detailed", false)
+	v.SetDefault("output.files_touched", false)
+	v.SetDefault("output.html_report", false)
+	v.SetDefault("output.report_sections", []string{})
+	v.SetDefault("output.breakdowns", []string{})
+	v.SetDefault("output.combined", false)
+	v.SetDefault("output.json_compact", false)
+	v.SetDefault("output.effective_config", false)
+	v.SetDefault("output.detailed_csv", false)
+	v.SetDefault("output.include_pr_body", false)
+	v.SetDefault("output.pr_body_max_length", 4000)
+	v.SetDefault("output.allow_empty", false)
+	v.SetDefault("output.dump_repo_results", "")
+	v.SetDefault("output.load_repo_results", "")
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 
 	// Concurrency defaults
 	v.SetDefault("concurrency.repo_workers", 8)
+	v.SetDefault("concurrency.file_workers_per_repo", 4)
+	v.SetDefault("concurrency.repo_retries", 0)
+	v.SetDefault("concurrency.sort_repos", false)
+	v.SetDefault("concurrency.limit_repos", 0)
+	v.SetDefault("concurrency.priority", "none")
+
+	// Fetch defaults
+	v.SetDefault("fetch.mode", "list")
+	v.SetDefault("fetch.window_splits", 1)
+	v.SetDefault("fetch.pr_state", "closed")
+	v.SetDefault("fetch.pr_details", false)
+	v.SetDefault("fetch.reviews", false)
+	v.SetDefault("fetch.review_events", false)
+	v.SetDefault("fetch.user_details", false)
+	v.SetDefault("fetch.commits", false)
+	v.SetDefault("fetch.releases", false)
+
+	// CODEOWNERS defaults
+	v.SetDefault("codeowners.source_repo", "")
+	v.SetDefault("codeowners.enforce_github_limits", false)
+	v.SetDefault("codeowners.max_bytes", 1024*1024)
+	v.SetDefault("codeowners.local_file", "")
+	v.SetDefault("codeowners.local_files", map[string]string{})
+	v.SetDefault("codeowners.min_coverage", 0.0)
+	v.SetDefault("codeowners.fail_on_low_coverage", false)
+
+	// Tracing defaults
+	v.SetDefault("tracing.otlp_endpoint", "")
+	v.SetDefault("notify.github_issue", "")
+	v.SetDefault("metrics.business_days_only", false)
+	v.SetDefault("metrics.holidays", []string{})
+	v.SetDefault("metrics.min_contributor_prs", 1)
+	v.SetDefault("metrics.external_command", "")
+	v.SetDefault("metrics.external_batch_size", 100)
+	v.SetDefault("metrics.external_timeout_seconds", 60)
+
+	v.SetDefault("identity_map", map[string]string{})
+	v.SetDefault("repo_overrides", map[string]interface{}{})
+
+	// Scoring defaults
+	v.SetDefault("scoring.default", 1.0)
 }
 
 func validateAndSetDefaults(cfg *Config) error {
-	// Validate GitHub org
+	// Validate GitHub org; a single repository implies its owner
+	if cfg.GitHub.Repo != "" {
+		if !repoRefPattern.MatchString(cfg.GitHub.Repo) {
+			return fmt.Errorf("invalid github.repo %q (expected owner/repo)", cfg.GitHub.Repo)
+		}
+		if cfg.GitHub.Org == "" {
+			cfg.GitHub.Org = strings.SplitN(cfg.GitHub.Repo, "/", 2)[0]
+		}
+	}
 	if cfg.GitHub.Org == "" {
-		return fmt.Errorf("github.org is required")
+		return fmt.Errorf("github.org is required unless github.repo is set")
 	}
 
 	// Validate time window
 	if cfg.TimeWindow.Since == "" {
 		return fmt.Errorf("time_window.since is required")
 	}
+	if cfg.startedAt.IsZero() {
+		cfg.startedAt = time.Now().UTC().Truncate(time.Second)
+	}
 	if cfg.TimeWindow.Until == "" {
-		return fmt.Errorf("time_window.until is required")
+		// Rolling windows for scheduled runs: up to the moment the run starts
+		cfg.TimeWindow.Until = cfg.startedAt.Format(time.RFC3339)
 	}
 
 	// Validate time format
-	if _, err := time.Parse(time.RFC3339, cfg.TimeWindow.Since); err != nil {
-		return fmt.Errorf("invalid time_window.since format (must be RFC3339): %w", err)
+	if _, err := ParseTimeBound(cfg.TimeWindow.Since, cfg.startedAt); err != nil {
+		return fmt.Errorf("invalid time_window.since: %w", err)
+	}
+	if _, err := ParseTimeBound(cfg.TimeWindow.Until, cfg.startedAt); err != nil {
+		return fmt.Errorf("invalid time_window.until: %w", err)
+	}
+
+	// Validate the cache namespace; "." and ".." would not isolate a JSON cache
+	if ns := cfg.Cache.Namespace; ns != "" && (!namespacePattern.MatchString(ns) || ns == "." || ns == "..") {
+		return fmt.Errorf("invalid cache.namespace %q (letters, digits, '_', '.' and '-' only)", ns)
+	}
+
+	if cfg.Cache.SnapshotAt != "" {
+		if _, err := time.Parse(time.RFC3339, cfg.Cache.SnapshotAt); err != nil {
+			return fmt.Errorf("invalid cache.snapshot_at format (must be RFC3339): %w", err)
+		}
+	}
+
+	// Validate excluded PR references
+	for _, ref := range cfg.Filters.ExcludePRNumbers {
+		if !PRRefPattern.MatchString(ref) {
+			return fmt.Errorf("invalid filters.exclude_pr_numbers entry %q (expected owner/repo#number)", ref)
+		}
+	}
+
+	// Validate base branch regexes
+	for _, pattern := range cfg.Filters.BaseBranchRegexes {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid filters.base_branch_regexes entry %q: %w", pattern, err)
+		}
+	}
+
+	// Validate repository name globs
+	for _, pattern := range cfg.Filters.IncludeRepos {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filters.include_repos entry %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range cfg.Filters.ExcludeRepos {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filters.exclude_repos entry %q: %w", pattern, err)
+		}
+	}
+
+	// Validate the shared CODEOWNERS repository
+	if cfg.CODEOWNERS.SourceRepo != "" && !repoRefPattern.MatchString(cfg.CODEOWNERS.SourceRepo) {
+		return fmt.Errorf("invalid codeowners.source_repo %q (expected owner/repo)", cfg.CODEOWNERS.SourceRepo)
+	}
+
+	// Validate the local CODEOWNERS overrides
+	for repo, path := range cfg.CODEOWNERS.LocalFiles {
+		if !repoRefPattern.MatchString(repo) || path == "" {
+			return fmt.Errorf("invalid codeowners.local_files entry %q (expected owner/repo: path)", repo)
+		}
 	}
-	if _, err := time.Parse(time.RFC3339, cfg.TimeWindow.Until); err != nil {
-		return fmt.Errorf("invalid time_window.until format (must be RFC3339): %w", err)
+
+	// Validate the repository overrides, keyed by lower-case owner/repo for lookups
+	overrides := make(map[string]RepoOverrideConfig, len(cfg.RepoOverrides))
+	for repo, override := range cfg.RepoOverrides {
+		if !repoRefPattern.MatchString(repo) {
+			return fmt.Errorf("invalid repo_overrides entry %q (expected owner/repo)", repo)
+		}
+		if override.AttributionMode != "" && !isAllowed("attribution.mode", override.AttributionMode) {
+			return fmt.Errorf("invalid repo_overrides entry %q: invalid attribution_mode %q", repo, override.AttributionMode)
+		}
+		overrides[strings.ToLower(repo)] = override
+	}
+	cfg.RepoOverrides = overrides
+
+	if cfg.Output.DumpRepoResults != "" && cfg.Output.LoadRepoResults != "" {
+		return fmt.Errorf("output.dump_repo_results and output.load_repo_results cannot be combined")
+	}
+
+	// Normalize the identity map: aliases match logins case-insensitively, and
+	// a canonical identity cannot itself be an alias of another one
+	identities := make(map[string]string, len(cfg.IdentityMap))
+	for alias, canonical := range cfg.IdentityMap {
+		if alias == "" || canonical == "" {
+			return fmt.Errorf("invalid identity_map entry %q: %q (expected login: canonical identity)", alias, canonical)
+		}
+		identities[strings.ToLower(alias)] = canonical
+	}
+	for alias, canonical := range identities {
+		if next, ok := identities[strings.ToLower(canonical)]; ok && next != canonical {
+			return fmt.Errorf("invalid identity_map entry %q: %q is itself aliased to %q", alias, canonical, next)
+		}
 	}
+	cfg.IdentityMap = identities
 
 	// Validate attribution mode
-	validModes := map[string]bool{"multi": true, "primary": true, "first-owner-only": true}
-	if !validModes[cfg.Attribution.Mode] {
+	if !isAllowed("attribution.mode", cfg.Attribution.Mode) {
 		cfg.Attribution.Mode = "multi"
 	}
 
+	// Unowned PRs always need a bucket
+	if cfg.Attribution.NoCodeownersFileBucket == "" {
+		cfg.Attribution.NoCodeownersFileBucket = "no_codeowners_file"
+	}
+	if cfg.Attribution.UnmatchedPathsBucket == "" {
+		cfg.Attribution.UnmatchedPathsBucket = "unmatched_paths"
+	}
+
+	// So do authorless PRs
+	if cfg.Attribution.GhostAuthorBucket == "" {
+		cfg.Attribution.GhostAuthorBucket = "ghost"
+	}
+
+	// Coverage is measured against CODEOWNERS, which is not fetched without attribution
+	if !cfg.Attribution.Enabled && cfg.CODEOWNERS.MinCoverage > 0 {
+		return fmt.Errorf("codeowners.min_coverage requires attribution.enabled")
+	}
+
+	// Ignoring catch-alls needs patterns to recognize them by
+	if cfg.Attribution.IgnoreCatchall && len(cfg.Attribution.CatchallPatterns) == 0 {
+		return fmt.Errorf("attribution.ignore_catchall requires attribution.catchall_patterns")
+	}
+
 	// Validate output format
-	validFormats := map[string]bool{"json": true, "csv": true}
-	if !validFormats[cfg.Output.Format] {
+	if !isAllowed("output.format", cfg.Output.Format) {
 		cfg.Output.Format = "json"
 	}
 
+	// Validate output localization
+	if cfg.Output.Locale != "" && !isAllowed("output.locale", cfg.Output.Locale) {
+		return fmt.Errorf("invalid output.locale %q", cfg.Output.Locale)
+	}
+	if cfg.Output.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Output.Timezone); err != nil {
+			return fmt.Errorf("invalid output.timezone %q: %w", cfg.Output.Timezone, err)
+		}
+	}
+
+	// Validate repository priority
+	if !isAllowed("concurrency.priority", cfg.Concurrency.Priority) {
+		return fmt.Errorf("invalid concurrency.priority %q", cfg.Concurrency.Priority)
+	}
+
+	// Validate report sections
+	for _, section := range cfg.Output.ReportSections {
+		if !isAllowed("output.report_sections", section) {
+			return fmt.Errorf("invalid output.report_sections entry %q", section)
+		}
+	}
+
+	// Validate breakdowns
+	for _, breakdown := range cfg.Output.Breakdowns {
+		if !isAllowed("output.breakdowns", breakdown) {
+			return fmt.Errorf("invalid output.breakdowns entry %q", breakdown)
+		}
+	}
+
+	// Validate PR size buckets
+	for i, bound := range cfg.Output.PRSizeBuckets {
+		if bound < 0 || (i > 0 && bound <= cfg.Output.PRSizeBuckets[i-1]) {
+			return fmt.Errorf("output.pr_size_buckets must be non-negative and strictly increasing")
+		}
+	}
+
+	// Validate business units
+	for i, unit := range cfg.BusinessUnits {
+		if unit.Name == "" || len(unit.Repos) == 0 {
+			return fmt.Errorf("business_units[%d] needs a name and at least one repo glob", i)
+		}
+	}
+
+	// Validate categories
+	for i, category := range cfg.Categories {
+		if category.Name == "" || len(category.Labels) == 0 {
+			return fmt.Errorf("categories[%d] needs a name and at least one label glob", i)
+		}
+	}
+
+	// Validate the search rate limiter
+	if cfg.RateLimiter.SearchQPS <= 0 || cfg.RateLimiter.SearchBurst < 1 {
+		return fmt.Errorf("rate_limiter.search_qps must be positive and rate_limiter.search_burst at least 1")
+	}
+
+	// Validate the inter-repo delay
+	if cfg.RateLimiter.InterRepoDelayMs < 0 {
+		return fmt.Errorf("rate_limiter.inter_repo_delay_ms must not be negative")
+	}
+
+	// Validate CODEOWNERS coverage threshold
+	if cfg.CODEOWNERS.MinCoverage < 0 || cfg.CODEOWNERS.MinCoverage > 1 {
+		return fmt.Errorf("codeowners.min_coverage must be between 0 and 1")
+	}
+
+	// Validate CODEOWNERS size cap
+	if cfg.CODEOWNERS.MaxBytes < 0 {
+		return fmt.Errorf("codeowners.max_bytes must not be negative")
+	}
+
+	// Validate PR body length cap
+	if cfg.Output.PRBodyMaxLength < 0 {
+		return fmt.Errorf("output.pr_body_max_length must not be negative")
+	}
+
+	// Validate holidays
+	for _, holiday := range cfg.Metrics.Holidays {
+		if _, err := time.Parse("2006-01-02", holiday); err != nil {
+			return fmt.Errorf("invalid metrics.holidays entry %q (must be YYYY-MM-DD): %w", holiday, err)
+		}
+	}
+	if cfg.Metrics.MinContributorPRs < 1 {
+		cfg.Metrics.MinContributorPRs = 1
+	}
+	if cfg.Metrics.ExternalBatchSize < 1 {
+		cfg.Metrics.ExternalBatchSize = 100
+	}
+	if cfg.Metrics.ExternalTimeoutSeconds < 1 {
+		cfg.Metrics.ExternalTimeoutSeconds = 60
+	}
+
+	// Validate the notification issue
+	if cfg.Notify.GitHubIssue != "" && !PRRefPattern.MatchString(cfg.Notify.GitHubIssue) {
+		return fmt.Errorf("invalid notify.github_issue %q (must be owner/repo#number)", cfg.Notify.GitHubIssue)
+	}
+
+	// Validate scoring rules
+	for i, rule := range cfg.Scoring.Rules {
+		if rule.Label == "" && rule.TitlePrefix == "" && rule.MinLines == 0 && rule.MaxLines == 0 {
+			return fmt.Errorf("scoring.rules[%d] has no conditions", i)
+		}
+		if rule.MinLines < 0 || rule.MaxLines < 0 || (rule.MaxLines > 0 && rule.MaxLines < rule.MinLines) {
+			return fmt.Errorf("scoring.rules[%d] has an invalid line range", i)
+		}
+	}
+
+	// Validate the JSON cache layout
+	if cfg.Cache.JSONLayout == "" {
+		cfg.Cache.JSONLayout = "files"
+	}
+	if !isAllowed("cache.json_layout", cfg.Cache.JSONLayout) {
+		return fmt.Errorf("invalid cache.json_layout %q (must be files or ndjson)", cfg.Cache.JSONLayout)
+	}
+
+	// Validate the tiers of a tiered cache
+	if cfg.Cache.Backend == "tiered" {
+		if !isAllowed("cache.near.backend", cfg.Cache.Near.Backend) {
+			return fmt.Errorf("invalid cache.near.backend %q", cfg.Cache.Near.Backend)
+		}
+		if !isAllowed("cache.far.backend", cfg.Cache.Far.Backend) {
+			return fmt.Errorf("invalid cache.far.backend %q", cfg.Cache.Far.Backend)
+		}
+	}
+
+	// Validate fetch mode
+	if !isAllowed("fetch.mode", cfg.Fetch.Mode) {
+		cfg.Fetch.Mode = "list"
+	}
+
+	// Validate PR state
+	if cfg.Fetch.PRState == "" {
+		cfg.Fetch.PRState = "closed"
+	}
+	if !isAllowed("fetch.pr_state", cfg.Fetch.PRState) {
+		return fmt.Errorf("invalid fetch.pr_state %q (must be closed, merged or all)", cfg.Fetch.PRState)
+	}
+
+	// The merger of a PR is only returned when fetching it individually
+	if cfg.Filters.ExcludeSelfMerged && !cfg.Fetch.PRDetails {
+		return fmt.Errorf("filters.exclude_self_merged requires fetch.pr_details")
+	}
+
+	// So is its changed file count
+	if cfg.Filters.MaxChangedFiles < 0 {
+		return fmt.Errorf("filters.max_changed_files must not be negative")
+	}
+	if cfg.Filters.MaxChangedFiles > 0 && !cfg.Fetch.PRDetails {
+		return fmt.Errorf("filters.max_changed_files requires fetch.pr_details")
+	}
+
+	// Search results do not include the base branch
+	if len(cfg.Filters.BaseBranchRegexes) > 0 && cfg.Fetch.Mode == "search" {
+		return fmt.Errorf("filters.base_branch_regexes requires fetch.mode list")
+	}
+
+	// Nor the merge commit, unless each PR is fetched individually
+	if cfg.Attribution.PointInTime && cfg.Fetch.Mode == "search" && !cfg.Fetch.PRDetails {
+		return fmt.Errorf("attribution.point_in_time requires fetch.mode list or fetch.pr_details")
+	}
+
+	// Validate window splits; listing cannot filter by date server-side
+	if cfg.Fetch.WindowSplits < 1 {
+		cfg.Fetch.WindowSplits = 1
+	}
+	if cfg.Fetch.WindowSplits > 1 && cfg.Fetch.Mode != "search" {
+		return fmt.Errorf("fetch.window_splits requires fetch.mode search")
+	}
+
 	// Ensure output directory exists
 	if err := os.MkdirAll(cfg.Output.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -212,14 +781,20 @@ func (c *Config) GetToken() (string, error) {
 	return token, nil
 }
 
-// GetTimeWindow returns parsed time window
+// GetTimeWindow returns parsed time window. Relative bounds are resolved
+// against the time the config was loaded, so every call returns the same window.
 func (c *Config) GetTimeWindow() (time.Time, time.Time, error) {
-	since, err := time.Parse(time.RFC3339, c.TimeWindow.Since)
+	now := c.startedAt
+	if now.IsZero() {
+		now = time.Now().UTC().Truncate(time.Second)
+	}
+
+	since, err := ParseTimeBound(c.TimeWindow.Since, now)
 	if err != nil {
 		return time.Time{}, time.Time{}, fmt.Errorf("invalid since time: %w", err)
 	}
 
-	until, err := time.Parse(time.RFC3339, c.TimeWindow.Until)
+	until, err := ParseTimeBound(c.TimeWindow.Until, now)
 	if err != nil {
 		return time.Time{}, time.Time{}, fmt.Errorf("invalid until time: %w", err)
 	}
@@ -227,3 +802,41 @@ func (c *Config) GetTimeWindow() (time.Time, time.Time, error) {
 	return since, until, nil
 }
 
+// ParseTimeBound parses a time window bound: an RFC3339 timestamp, or a time
+// relative to now: "now", "now-30d", "now+1d", "-4w" or a plain "12h" (12
+// hours ago). Units are h (hours), d (days of 24 hours) and w (weeks).
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	match := relativeTimePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	// A bare number or "now" directly followed by one ("now30d") is ambiguous
+	if match == nil || (match[1] == "" && match[3] == "") || (match[1] != "" && match[3] != "" && match[2] == "") {
+		return time.Time{}, fmt.Errorf("%q is neither RFC3339 nor a relative time like now-30d", value)
+	}
+	if match[3] == "" {
+		return now.UTC(), nil
+	}
+
+	amount, err := strconv.Atoi(match[3])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid amount in %q: %w", value, err)
+	}
+	unit := time.Hour
+	switch match[4] {
+	case "d":
+		unit = 24 * time.Hour
+	case "w":
+		unit = 7 * 24 * time.Hour
+	}
+	// Durations only span about 292 years; a larger amount would wrap around
+	if amount > int(math.MaxInt64/unit) {
+		return time.Time{}, fmt.Errorf("%q is too far from now", value)
+	}
+	offset := time.Duration(amount) * unit
+	if match[2] == "+" {
+		return now.UTC().Add(offset), nil
+	}
+	return now.UTC().Add(-offset), nil
+}
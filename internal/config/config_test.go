@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	day := 24 * time.Hour
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2025-09-01T00:00:00Z", time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{"now", now.UTC()},
+		{"NOW", now.UTC()},
+		{" now ", now.UTC()},
+		{"now-30d", now.UTC().Add(-30 * day)},
+		{"now+1d", now.UTC().Add(day)},
+		{"-4w", now.UTC().Add(-28 * day)},
+		{"+2h", now.UTC().Add(2 * time.Hour)},
+		{"12h", now.UTC().Add(-12 * time.Hour)},
+		{"0d", now.UTC()},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeBound(tt.value, now)
+		if err != nil {
+			t.Errorf("ParseTimeBound(%q) = %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("ParseTimeBound(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeBoundRejects(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"",
+		"30",
+		"now30d",
+		"now-30",
+		"now-30m",
+		"yesterday",
+		"2025-09-01",
+		"-99999999999999999999d",
+		"now-999999999w",
+	} {
+		if got, err := ParseTimeBound(value, now); err == nil {
+			t.Errorf("ParseTimeBound(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestLoadConfigValidatesCacheNamespace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		valid     bool
+	}{
+		{"", true},
+		{"staging", true},
+		{"team_a.v2-eu", true},
+		{"../prod", false},
+		{"a/b", false},
+		{"..", false},
+		{".", false},
+		{"has space", false},
+		{"prod:1", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		data := fmt.Sprintf("github:\n  org: myorg\ntime_window:\n  since: now-30d\ncache:\n  namespace: %q\n", tt.namespace)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadConfig([]string{path}, zap.NewNop())
+		if got := err == nil; got != tt.valid {
+			t.Errorf("LoadConfig() with cache.namespace %q = %v, want valid %v", tt.namespace, err, tt.valid)
+		}
+	}
+}
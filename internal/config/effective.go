@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces secret values in the effective configuration
+const redacted = "<redacted>"
+
+// githubTokenPrefixes are the prefixes of GitHub token formats
+var githubTokenPrefixes = []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_"}
+
+// WriteEffective writes the fully resolved configuration (file, defaults and
+// any overrides applied to cfg) as YAML. The GitHub token is never part of the
+// config, but any value that is the token or looks like a GitHub token (e.g. a
+// token pasted into token_env_var by mistake) is redacted.
+func WriteEffective(w io.Writer, cfg *Config) error {
+	token := os.Getenv(cfg.GitHub.TokenEnvVar)
+
+	var b strings.Builder
+	b.WriteString("# ghpr-analyzer effective configuration\n")
+	writeEffectiveStruct(&b, reflect.ValueOf(*cfg), "", token)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeEffectiveStruct writes the fields of a config struct value, one line per option
+func writeEffectiveStruct(b *strings.Builder, v reflect.Value, indent, token string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value := v.Field(i)
+		switch {
+		case value.Kind() == reflect.Struct:
+			fmt.Fprintf(b, "%s%s:\n", indent, name)
+			writeEffectiveStruct(b, value, indent+"  ", token)
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+			if value.Len() == 0 {
+				fmt.Fprintf(b, "%s%s: []\n", indent, name)
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:\n", indent, name)
+
+			// Render each entry, turning its first line into a list item
+			for j := 0; j < value.Len(); j++ {
+				var item strings.Builder
+				writeEffectiveStruct(&item, value.Index(j), "", token)
+				for k, line := range strings.Split(strings.TrimSuffix(item.String(), "\n"), "\n") {
+					if k == 0 {
+						fmt.Fprintf(b, "%s  - %s\n", indent, line)
+					} else {
+						fmt.Fprintf(b, "%s    %s\n", indent, line)
+					}
+				}
+			}
+		default:
+			fmt.Fprintf(b, "%s%s: %s\n", indent, name, formatEffectiveValue(value, token))
+		}
+	}
+}
+
+// formatEffectiveValue formats a scalar or slice value as YAML
+func formatEffectiveValue(v reflect.Value, token string) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(redact(v.String(), token))
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, formatEffectiveValue(v.Index(i), token))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// redact hides a value that is the GitHub token or looks like one
+func redact(value, token string) string {
+	if token != "" && value == token {
+		return redacted
+	}
+	for _, prefix := range githubTokenPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return redacted
+		}
+	}
+	return value
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fishnix/ghpr-analyzer/internal/exporter"
+	"github.com/spf13/viper"
+)
+
+// allowedValues lists the accepted values of enumerated options, keyed by config path.
+// The locales, report sections and breakdowns come from the exporters that
+// implement them, so a new one only needs to be added there.
+var allowedValues = map[string][]string{
+	"attribution.mode":       {"multi", "primary", "first-owner-only"},
+	"cache.backend":          {"sqlite", "json", "memory", "tiered"},
+	"cache.near.backend":     {"sqlite", "json"},
+	"cache.far.backend":      {"sqlite", "json"},
+	"cache.json_layout":      {"files", "ndjson"},
+	"rate_limiter.type":      {"token-bucket"},
+	"output.format":          {"json", "csv", "sqlite"},
+	"output.locale":          exporter.LocaleNames(),
+	"fetch.mode":             {"list", "search"},
+	"fetch.pr_state":         {"closed", "merged", "all"},
+	"concurrency.priority":   {"none", "activity"},
+	"output.report_sections": exporter.ReportSections,
+	"output.breakdowns":      exporter.Breakdowns,
+	"logging.level":          {"debug", "info", "warn", "error"},
+}
+
+// requiredKeys lists the options that have no default and must be set
+var requiredKeys = map[string]bool{
+	"github.org":        true,
+	"time_window.since": true,
+	"time_window.until": true,
+}
+
+// isAllowed checks a value against the allowed values of an enumerated option
+func isAllowed(key, value string) bool {
+	for _, allowed := range allowedValues[key] {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSchema writes every configuration option with its default as commented YAML.
+// The structure is derived from the Config struct's mapstructure tags and the
+// defaults from setDefaults, so the output always matches the code.
+func WriteSchema(w io.Writer) error {
+	v := viper.New()
+	setDefaults(v)
+
+	var b strings.Builder
+	b.WriteString("# ghpr-analyzer configuration (generated from the Config struct and its defaults)\n")
+	writeSchemaStruct(&b, v, reflect.TypeOf(Config{}), "", "")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSchemaStruct writes the fields of a config struct, one line per option
+func writeSchemaStruct(b *strings.Builder, v *viper.Viper, t reflect.Type, prefix, indent string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			fmt.Fprintf(b, "%s%s:\n", indent, name)
+			writeSchemaStruct(b, v, field.Type, key, indent+"  ")
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			fmt.Fprintf(b, "%s%s:  # list of entries like:\n", indent, name)
+
+			// Render one example entry, turning its first line into a list item
+			var item strings.Builder
+			writeSchemaStruct(&item, v, field.Type.Elem(), key+"[]", "")
+			for j, line := range strings.Split(strings.TrimSuffix(item.String(), "\n"), "\n") {
+				if j == 0 {
+					fmt.Fprintf(b, "%s  - %s\n", indent, line)
+				} else {
+					fmt.Fprintf(b, "%s    %s\n", indent, line)
+				}
+			}
+		default:
+			fmt.Fprintf(b, "%s%s: %s  # %s\n", indent, name, formatSchemaDefault(v.Get(key), field.Type), describeSchemaField(key, field.Type))
+		}
+	}
+}
+
+// describeSchemaField describes the type and constraints of an option
+func describeSchemaField(key string, t reflect.Type) string {
+	desc := schemaTypeName(t)
+	if allowed, ok := allowedValues[key]; ok {
+		desc += ", one of: " + strings.Join(allowed, ", ")
+	}
+	if requiredKeys[key] {
+		desc += " (required)"
+	}
+	return desc
+}
+
+// schemaTypeName returns a human-readable name for a config field type
+func schemaTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list of " + schemaTypeName(t.Elem()) + "s"
+	case reflect.Map:
+		return "map of " + schemaTypeName(t.Key()) + " to " + schemaTypeName(t.Elem())
+	default:
+		return t.Kind().String()
+	}
+}
+
+// formatSchemaDefault formats a default value as YAML, falling back to the zero value
+func formatSchemaDefault(value interface{}, t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		s, _ := value.(string)
+		return strconv.Quote(s)
+	case reflect.Slice:
+		rv := reflect.ValueOf(value)
+		if value == nil || rv.Kind() != reflect.Slice {
+			return "[]"
+		}
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, strconv.Quote(fmt.Sprint(rv.Index(i).Interface())))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		return "{}"
+	default:
+		if value == nil {
+			return fmt.Sprint(reflect.Zero(t).Interface())
+		}
+		return fmt.Sprint(value)
+	}
+}
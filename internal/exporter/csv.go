@@ -7,25 +7,81 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// Breakdowns lists the breakdowns that output.breakdowns can select
+var Breakdowns = []string{
+	"summary",
+	"team",
+	"repo",
+	"user",
+	"commits",
+	"issues",
+	"self_merged",
+	"business_unit",
+	"category",
+	"company",
+	"contributor",
+	"scores",
+	"release_lead_time",
+	"pr_sizes",
+	"user_team",
+	"collaboration",
+	"merged_without_approval",
+	"reviews",
+	"files_touched",
+	"author_association",
+	"review_churn",
+	"coverage",
+	"custom_metric",
+	"cycle_time",
+}
+
+// breakdownFilter holds the selected breakdowns; an empty filter selects all of them
+type breakdownFilter map[string]bool
+
+// newBreakdownFilter creates a filter selecting the given breakdowns
+func newBreakdownFilter(breakdowns []string) breakdownFilter {
+	filter := make(breakdownFilter, len(breakdowns))
+	for _, breakdown := range breakdowns {
+		filter[breakdown] = true
+	}
+	return filter
+}
+
+// enabled reports whether a breakdown should be exported
+func (f breakdownFilter) enabled(breakdown string) bool {
+	return len(f) == 0 || f[breakdown]
+}
+
 // CSVExporter exports analysis results to CSV format
 type CSVExporter struct {
-	outputDir string
-	logger    *zap.Logger
+	outputDir  string
+	breakdowns breakdownFilter
+	locale     *Locale
+	logger     *zap.Logger
 }
 
-// NewCSVExporter creates a new CSV exporter
-func NewCSVExporter(outputDir string, logger *zap.Logger) *CSVExporter {
+// NewCSVExporter creates a new CSV exporter. Only the given breakdowns are
+// written; an empty list writes all of them.
+func NewCSVExporter(outputDir string, breakdowns []string, logger *zap.Logger) *CSVExporter {
 	return &CSVExporter{
-		outputDir: outputDir,
-		logger:    logger,
+		outputDir:  outputDir,
+		breakdowns: newBreakdownFilter(breakdowns),
+		logger:     logger,
 	}
 }
 
+// SetLocale sets the time zone of the timestamps in summary.csv. Numbers and
+// the RFC3339 layout are kept so the file stays machine-readable.
+func (e *CSVExporter) SetLocale(locale *Locale) {
+	e.locale = locale
+}
+
 // Export exports the analysis results to CSV
 func (e *CSVExporter) Export(result *AnalysisResult) error {
 	e.logger.Info("Exporting results to CSV", zap.String("output_dir", e.outputDir))
@@ -36,23 +92,178 @@ func (e *CSVExporter) Export(result *AnalysisResult) error {
 	}
 
 	// Export aggregated results
-	if err := e.exportAggregated(result); err != nil {
-		return fmt.Errorf("failed to export aggregated results: %w", err)
+	if e.breakdowns.enabled("summary") {
+		if err := e.exportAggregated(result); err != nil {
+			return fmt.Errorf("failed to export aggregated results: %w", err)
+		}
 	}
 
 	// Export by team
-	if err := e.exportByTeam(result); err != nil {
-		return fmt.Errorf("failed to export by team: %w", err)
+	if e.breakdowns.enabled("team") {
+		if err := e.exportByTeam(result); err != nil {
+			return fmt.Errorf("failed to export by team: %w", err)
+		}
 	}
 
 	// Export by repo
-	if err := e.exportByRepo(result); err != nil {
-		return fmt.Errorf("failed to export by repo: %w", err)
+	if e.breakdowns.enabled("repo") {
+		if err := e.exportByRepo(result); err != nil {
+			return fmt.Errorf("failed to export by repo: %w", err)
+		}
 	}
 
 	// Export by user
-	if err := e.exportByUser(result); err != nil {
-		return fmt.Errorf("failed to export by user: %w", err)
+	if e.breakdowns.enabled("user") {
+		if err := e.exportByUser(result); err != nil {
+			return fmt.Errorf("failed to export by user: %w", err)
+		}
+	}
+
+	// Export average commits by team
+	if e.breakdowns.enabled("commits") {
+		if err := e.exportAvgCommitsByTeam(result); err != nil {
+			return fmt.Errorf("failed to export average commits by team: %w", err)
+		}
+	}
+
+	// Export issue-closing PRs by team
+	if e.breakdowns.enabled("issues") {
+		if err := e.exportCounts("prs_closing_issues_by_team.csv", "Team", result.PRsClosingIssuesByTeam); err != nil {
+			return fmt.Errorf("failed to export issue-closing PRs by team: %w", err)
+		}
+	}
+
+	// Export self-merged PRs by team (only when PR details were fetched)
+	if result.SelfMergedPRsByTeam != nil && e.breakdowns.enabled("self_merged") {
+		if err := e.exportCounts("self_merged_prs_by_team.csv", "Team", result.SelfMergedPRsByTeam); err != nil {
+			return fmt.Errorf("failed to export self-merged PRs by team: %w", err)
+		}
+	}
+
+	// Export CODEOWNERS coverage (only with the coverage gate)
+	if result.CODEOWNERSCoverageByRepo != nil && e.breakdowns.enabled("coverage") {
+		if err := e.exportScores("codeowners_coverage_by_repo.csv", "Repository", "Coverage", result.CODEOWNERSCoverageByRepo); err != nil {
+			return fmt.Errorf("failed to export CODEOWNERS coverage by repository: %w", err)
+		}
+	}
+
+	// Export review churn (only when review events were fetched)
+	if result.ReviewChurnByTeam != nil && e.breakdowns.enabled("review_churn") {
+		if err := e.exportCountsAs("review_churn_by_team.csv", "Team", "Review Churn", result.ReviewChurnByTeam); err != nil {
+			return fmt.Errorf("failed to export review churn by team: %w", err)
+		}
+		if err := e.exportCountsAs("review_churn_by_repo.csv", "Repository", "Review Churn", result.ReviewChurnByRepo); err != nil {
+			return fmt.Errorf("failed to export review churn by repository: %w", err)
+		}
+	}
+
+	// Export by business unit (only when business units are configured)
+	if result.PRsByBusinessUnit != nil && e.breakdowns.enabled("business_unit") {
+		if err := e.exportCounts("prs_by_business_unit.csv", "Business Unit", result.PRsByBusinessUnit); err != nil {
+			return fmt.Errorf("failed to export by business unit: %w", err)
+		}
+	}
+
+	// Export by category (only when categories are configured)
+	if result.PRsByCategory != nil && e.breakdowns.enabled("category") {
+		if err := e.exportCounts("prs_by_category.csv", "Category", result.PRsByCategory); err != nil {
+			return fmt.Errorf("failed to export by category: %w", err)
+		}
+	}
+
+	// Export distinct files touched by team (only when requested)
+	if result.DistinctFilesByTeam != nil && e.breakdowns.enabled("files_touched") {
+		if err := e.exportCountsAs("files_touched_by_team.csv", "Team", "Distinct Files", result.DistinctFilesByTeam); err != nil {
+			return fmt.Errorf("failed to export files touched by team: %w", err)
+		}
+	}
+
+	// Export by author association
+	if e.breakdowns.enabled("author_association") {
+		if err := e.exportCounts("prs_by_author_association.csv", "Author Association", result.PRsByAuthorAssociation); err != nil {
+			return fmt.Errorf("failed to export by author association: %w", err)
+		}
+	}
+
+	// Export by company (only when user profiles were fetched)
+	if result.PRsByCompany != nil && e.breakdowns.enabled("company") {
+		if err := e.exportCounts("prs_by_company.csv", "Company", result.PRsByCompany); err != nil {
+			return fmt.Errorf("failed to export by company: %w", err)
+		}
+	}
+
+	// Export by contributor (only when commits were fetched)
+	if result.PRsByContributor != nil && e.breakdowns.enabled("contributor") {
+		if err := e.exportCounts("prs_by_contributor.csv", "Contributor", result.PRsByContributor); err != nil {
+			return fmt.Errorf("failed to export by contributor: %w", err)
+		}
+	}
+
+	// Export weighted scores (only when scoring rules are configured)
+	if result.ScoreByTeam != nil && e.breakdowns.enabled("scores") {
+		if err := e.exportScores("score_by_team.csv", "Team", "Score", result.ScoreByTeam); err != nil {
+			return fmt.Errorf("failed to export scores by team: %w", err)
+		}
+		if err := e.exportScores("score_by_user.csv", "User", "Score", result.ScoreByUser); err != nil {
+			return fmt.Errorf("failed to export scores by user: %w", err)
+		}
+	}
+
+	// Export the custom metric (only with metrics.external_command)
+	if result.PRsByCustomMetric != nil && e.breakdowns.enabled("custom_metric") {
+		if err := e.exportCounts("prs_by_custom_metric.csv", "Value", result.PRsByCustomMetric); err != nil {
+			return fmt.Errorf("failed to export PRs by custom metric: %w", err)
+		}
+		if err := e.exportScores("custom_metric_by_team.csv", "Team", "Custom Metric", result.CustomMetricByTeam); err != nil {
+			return fmt.Errorf("failed to export custom metric by team: %w", err)
+		}
+		if err := e.exportScores("custom_metric_by_user.csv", "User", "Custom Metric", result.CustomMetricByUser); err != nil {
+			return fmt.Errorf("failed to export custom metric by user: %w", err)
+		}
+	}
+
+	// Export merge-to-release lead times (only when releases were fetched)
+	if result.ReleaseLeadTimeHoursByRepo != nil && e.breakdowns.enabled("release_lead_time") {
+		if err := e.exportScores("release_lead_time_by_repo.csv", "Repository", "Avg Lead Time Hours", result.ReleaseLeadTimeHoursByRepo); err != nil {
+			return fmt.Errorf("failed to export release lead time by repo: %w", err)
+		}
+		if err := e.exportScores("release_lead_time_by_team.csv", "Team", "Avg Lead Time Hours", result.ReleaseLeadTimeHoursByTeam); err != nil {
+			return fmt.Errorf("failed to export release lead time by team: %w", err)
+		}
+	}
+
+	// Export PR size histogram (only when PR details were fetched)
+	if result.PRSizeHistogram != nil && e.breakdowns.enabled("pr_sizes") {
+		if err := e.exportPRSizeHistogram(result); err != nil {
+			return fmt.Errorf("failed to export PR size histogram: %w", err)
+		}
+	}
+
+	// Export user × team matrix (only when requested)
+	if result.PRsByUserTeam != nil && e.breakdowns.enabled("user_team") {
+		if err := e.exportByUserTeam(result); err != nil {
+			return fmt.Errorf("failed to export by user and team: %w", err)
+		}
+	}
+
+	// Export merged PRs without approval (only when reviews were fetched)
+	if result.CollaborationEdges != nil && e.breakdowns.enabled("collaboration") {
+		if err := e.exportCollaboration(result); err != nil {
+			return fmt.Errorf("failed to export collaboration edges: %w", err)
+		}
+	}
+
+	if result.MergedWithoutApproval != nil && e.breakdowns.enabled("merged_without_approval") {
+		if err := e.exportMergedWithoutApproval(result); err != nil {
+			return fmt.Errorf("failed to export merged PRs without approval: %w", err)
+		}
+	}
+
+	// Export review load by reviewer (only when reviews were fetched)
+	if result.ReviewsByUser != nil && e.breakdowns.enabled("reviews") {
+		if err := e.exportCountsAs("reviews_by_user.csv", "Reviewer", "PRs Reviewed", result.ReviewsByUser); err != nil {
+			return fmt.Errorf("failed to export reviews by user: %w", err)
+		}
 	}
 
 	e.logger.Info("CSV export complete")
@@ -80,13 +291,30 @@ func (e *CSVExporter) exportAggregated(result *AnalysisResult) error {
 	// Write data
 	records := [][]string{
 		{"Total PRs Closed", strconv.Itoa(result.TotalPRsClosed)},
+		{"Open PRs", strconv.Itoa(result.OpenPRs)},
 		{"Total Repos", strconv.Itoa(len(result.PRsByRepo))},
 		{"Total Teams", strconv.Itoa(len(result.PRsByTeam))},
 		{"Total Users", strconv.Itoa(len(result.PRsByUser))},
-		{"Time Window Start", result.TimeWindow.Since.Format(time.RFC3339)},
-		{"Time Window End", result.TimeWindow.Until.Format(time.RFC3339)},
-		{"Generated At", result.GeneratedAt.Format(time.RFC3339)},
+		{"Avg Commits Per PR", strconv.FormatFloat(result.AvgCommitsPerPR, 'f', 2, 64)},
+		{"PRs Merged Without Approval", strconv.Itoa(result.PRsMergedWithoutApproval)},
+		{"Self-Merged PRs", strconv.Itoa(result.SelfMergedPRs)},
+		{"PRs Excluded As Trivial", strconv.Itoa(result.PRsExcludedAsTrivial)},
+		{"PRs Excluded As Mass Change", strconv.Itoa(result.PRsExcludedAsMassChange)},
+		{"PRs Closing Issues", strconv.Itoa(result.PRsClosingIssues)},
 	}
+	if stats := result.PRsPerContributor; stats != nil {
+		records = append(records,
+			[]string{"Active Contributors", strconv.Itoa(stats.Contributors)},
+			[]string{"Mean PRs Per Contributor", strconv.FormatFloat(stats.Mean, 'f', 2, 64)},
+			[]string{"Median PRs Per Contributor", strconv.FormatFloat(stats.Median, 'f', 2, 64)},
+			[]string{"P90 PRs Per Contributor", strconv.FormatFloat(stats.P90, 'f', 2, 64)},
+		)
+	}
+	records = append(records, [][]string{
+		{"Time Window Start", e.locale.In(result.TimeWindow.Since).Format(time.RFC3339)},
+		{"Time Window End", e.locale.In(result.TimeWindow.Until).Format(time.RFC3339)},
+		{"Generated At", e.locale.In(result.GeneratedAt).Format(time.RFC3339)},
+	}...)
 
 	for _, record := range records {
 		if err := writer.Write(record); err != nil {
@@ -227,3 +455,388 @@ func (e *CSVExporter) exportByUser(result *AnalysisResult) error {
 	return nil
 }
 
+// exportAvgCommitsByTeam exports average commits per merged PR by team
+func (e *CSVExporter) exportAvgCommitsByTeam(result *AnalysisResult) error {
+	outputPath := filepath.Join(e.outputDir, "avg_commits_by_team.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Team", "Avg Commits"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Sort teams by average commits (descending)
+	type teamAvg struct {
+		team string
+		avg  float64
+	}
+	var teams []teamAvg
+	for team, avg := range result.AvgCommitsByTeam {
+		teams = append(teams, teamAvg{team: team, avg: avg})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].avg > teams[j].avg
+	})
+
+	// Write data
+	for _, ta := range teams {
+		record := []string{ta.team, strconv.FormatFloat(ta.avg, 'f', 2, 64)}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported average commits by team", zap.String("path", outputPath))
+	return nil
+}
+
+// exportMergedWithoutApproval exports merged PRs that had no approving review
+func (e *CSVExporter) exportMergedWithoutApproval(result *AnalysisResult) error {
+	outputPath := filepath.Join(e.outputDir, "merged_without_approval.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Repository", "PR", "Author", "Merged At"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Sort by repository, then PR number
+	merges := make([]UnapprovedMerge, len(result.MergedWithoutApproval))
+	copy(merges, result.MergedWithoutApproval)
+	sort.Slice(merges, func(i, j int) bool {
+		if merges[i].Repo != merges[j].Repo {
+			return merges[i].Repo < merges[j].Repo
+		}
+		return merges[i].PRNumber < merges[j].PRNumber
+	})
+
+	// Write data
+	for _, m := range merges {
+		record := []string{m.Repo, strconv.Itoa(m.PRNumber), m.Author, m.MergedAt.Format(time.RFC3339)}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported merged PRs without approval", zap.String("path", outputPath))
+	return nil
+}
+
+// exportCollaboration exports the reviewer → author edge list, heaviest edges first
+func (e *CSVExporter) exportCollaboration(result *AnalysisResult) error {
+	outputPath := filepath.Join(e.outputDir, "collaboration.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Reviewer", "Author", "Count"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data (already sorted by the analyzer)
+	for _, edge := range result.CollaborationEdges {
+		record := []string{edge.Reviewer, edge.Author, strconv.Itoa(edge.Count)}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported collaboration edges", zap.String("path", outputPath))
+	return nil
+}
+
+// exportCounts exports a PR count breakdown, sorted by count (descending)
+func (e *CSVExporter) exportCounts(fileName, keyHeader string, counts map[string]int) error {
+	return e.exportCountsAs(fileName, keyHeader, "PR Count", counts)
+}
+
+// exportCountsAs exports a breakdown of counts other than PRs, largest first
+func (e *CSVExporter) exportCountsAs(fileName, keyHeader, valueHeader string, counts map[string]int) error {
+	outputPath := filepath.Join(e.outputDir, fileName)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{keyHeader, valueHeader}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Sort keys by count (descending), then name for stable output
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	// Write data
+	for _, key := range keys {
+		record := []string{key, strconv.Itoa(counts[key])}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported counts", zap.String("path", outputPath))
+	return nil
+}
+
+// exportScores exports a breakdown of per-PR values (scores, average lead
+// times), sorted by value (descending)
+func (e *CSVExporter) exportScores(fileName, keyHeader, valueHeader string, scores map[string]float64) error {
+	outputPath := filepath.Join(e.outputDir, fileName)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{keyHeader, valueHeader}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data
+	for _, key := range sortedScoreKeys(scores) {
+		record := []string{key, strconv.FormatFloat(scores[key], 'f', 2, 64)}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported PR values", zap.String("path", outputPath))
+	return nil
+}
+
+// sortedScoreKeys returns the keys of a score breakdown by score (descending), then name
+func sortedScoreKeys(scores map[string]float64) []string {
+	keys := make([]string, 0, len(scores))
+	for key := range scores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] != scores[keys[j]] {
+			return scores[keys[i]] > scores[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// exportPRSizeHistogram exports PR counts by size bucket, smallest bucket first
+func (e *CSVExporter) exportPRSizeHistogram(result *AnalysisResult) error {
+	outputPath := filepath.Join(e.outputDir, "pr_size_histogram.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Lines Changed", "PR Count"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data
+	for _, bucket := range sortedSizeBuckets(result.PRSizeHistogram) {
+		record := []string{bucket, strconv.Itoa(result.PRSizeHistogram[bucket])}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported PR size histogram", zap.String("path", outputPath))
+	return nil
+}
+
+// sortedSizeBuckets orders histogram bucket labels ("0-10", "11-100", "101+")
+// by their lower bound
+func sortedSizeBuckets(histogram map[string]int) []string {
+	lowerBound := func(label string) int {
+		n, _ := strconv.Atoi(strings.FieldsFunc(label, func(r rune) bool { return r == '-' || r == '+' })[0])
+		return n
+	}
+
+	buckets := make([]string, 0, len(histogram))
+	for bucket := range histogram {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return lowerBound(buckets[i]) < lowerBound(buckets[j])
+	})
+	return buckets
+}
+
+// exportByUserTeam exports PR counts as a matrix with users as rows and teams as columns
+func (e *CSVExporter) exportByUserTeam(result *AnalysisResult) error {
+	outputPath := filepath.Join(e.outputDir, "prs_by_user_team.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Collect users and the union of teams across all users
+	var users []string
+	teamSet := make(map[string]bool)
+	for user, teams := range result.PRsByUserTeam {
+		users = append(users, user)
+		for team := range teams {
+			teamSet[team] = true
+		}
+	}
+	sort.Strings(users)
+	teams := make([]string, 0, len(teamSet))
+	for team := range teamSet {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	// Write header
+	if err := writer.Write(append([]string{"User"}, teams...)); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data (sparse cells are zero)
+	for _, user := range users {
+		record := []string{user}
+		for _, team := range teams {
+			record = append(record, strconv.Itoa(result.PRsByUserTeam[user][team]))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported by user and team", zap.String("path", outputPath))
+	return nil
+}
+
+// ExportOwnershipChanges exports paths whose owners changed between two CODEOWNERS refs
+func (e *CSVExporter) ExportOwnershipChanges(changes []OwnershipChange) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "ownership_changes.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Repository", "Path", "Previous Owners", "Current Owners", "Teams Gained", "Teams Lost"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data (owners space-separated, as in CODEOWNERS)
+	for _, c := range changes {
+		record := []string{
+			c.Repo,
+			c.Path,
+			strings.Join(c.PreviousOwners, " "),
+			strings.Join(c.CurrentOwners, " "),
+			strings.Join(c.TeamsGained, " "),
+			strings.Join(c.TeamsLost, " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Info("Exported ownership changes", zap.String("path", outputPath), zap.Int("count", len(changes)))
+	return nil
+}
+
+// ExportRateLimitTimeline exports the rate limit reported by each API response
+// of the run to rate_limit_timeline.csv, in arrival order
+func (e *CSVExporter) ExportRateLimitTimeline(samples []RateLimitSample) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "rate_limit_timeline.csv")
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"Timestamp", "Remaining", "Limit", "Resource"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	// Write data
+	for _, s := range samples {
+		record := []string{
+			s.Time.UTC().Format(time.RFC3339Nano),
+			strconv.Itoa(s.Remaining),
+			strconv.Itoa(s.Limit),
+			s.Resource,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	e.logger.Debug("Exported rate limit timeline", zap.String("path", outputPath), zap.Int("samples", len(samples)))
+	return nil
+}
@@ -0,0 +1,325 @@
+package exporter
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReportSections lists the sections of the HTML report, in report order
+var ReportSections = []string{
+	"summary",
+	"repos",
+	"teams",
+	"users",
+	"contributors",
+	"reviewers",
+	"companies",
+	"issues",
+	"commits",
+	"pr_sizes",
+	"merged_without_approval",
+	"user_team",
+	"scores",
+}
+
+// HTMLExporter exports all breakdowns as a single self-contained HTML report
+type HTMLExporter struct {
+	outputDir string
+	sections  []string
+	logger    *zap.Logger
+}
+
+// NewHTMLExporter creates a new HTML report exporter. Only the given sections
+// are rendered; an empty list renders all of them.
+func NewHTMLExporter(outputDir string, sections []string, logger *zap.Logger) *HTMLExporter {
+	return &HTMLExporter{
+		outputDir: outputDir,
+		sections:  sections,
+		logger:    logger,
+	}
+}
+
+// reportSection is one rendered section of the HTML report
+type reportSection struct {
+	ID      string
+	Title   string
+	Headers []string
+	Rows    []reportRow
+	Chart   bool // Render the first value as a bar relative to the largest
+}
+
+// reportRow is one table row of a report section
+type reportRow struct {
+	Label  string
+	Values []string
+	Bar    float64 // Bar width in percent
+}
+
+// reportTemplate renders the report; bars are plain CSS so the file has no dependencies
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GitHub PR Analysis Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #24292f; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; margin-bottom: 2em; min-width: 40em; }
+th, td { text-align: left; padding: 0.3em 0.8em; border-bottom: 1px solid #d0d7de; }
+.bar { background: #0969da; height: 0.8em; }
+</style>
+</head>
+<body>
+<h1>GitHub PR Analysis Report</h1>
+<p>Time window: {{.Since}} to {{.Until}} &middot; Generated at {{.GeneratedAt}}</p>
+<nav>{{range .Sections}}<a href="#{{.ID}}">{{.Title}}</a>{{end}}</nav>
+{{range .Sections}}
+<h2 id="{{.ID}}">{{.Title}}</h2>
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}{{if .Chart}}<th></th>{{end}}</tr>
+{{- $chart := .Chart}}
+{{range .Rows}}<tr><td>{{.Label}}</td>{{range .Values}}<td>{{.}}</td>{{end}}{{if $chart}}<td><div class="bar" style="width: {{printf "%.1f" .Bar}}%"></div></td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// Export writes the report to report.html
+func (e *HTMLExporter) Export(result *AnalysisResult) error {
+	e.logger.Info("Exporting HTML report", zap.String("output_dir", e.outputDir))
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	enabled := make(map[string]bool)
+	for _, section := range e.sections {
+		enabled[section] = true
+	}
+
+	var sections []reportSection
+	for _, id := range ReportSections {
+		if len(e.sections) > 0 && !enabled[id] {
+			continue
+		}
+		if section, ok := buildReportSection(id, result); ok {
+			sections = append(sections, section)
+		}
+	}
+
+	outputPath := filepath.Join(e.outputDir, "report.html")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML file: %w", err)
+	}
+	defer file.Close()
+
+	data := struct {
+		Since       string
+		Until       string
+		GeneratedAt string
+		Sections    []reportSection
+	}{
+		Since:       result.TimeWindow.Since.Format("2006-01-02"),
+		Until:       result.TimeWindow.Until.Format("2006-01-02"),
+		GeneratedAt: result.GeneratedAt.Format("2006-01-02 15:04:05"),
+		Sections:    sections,
+	}
+	if err := reportTemplate.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	e.logger.Info("HTML export complete", zap.String("path", outputPath))
+	return nil
+}
+
+// buildReportSection builds a report section, reporting false when its data was not collected
+func buildReportSection(id string, result *AnalysisResult) (reportSection, bool) {
+	switch id {
+	case "summary":
+		rows := []reportRow{
+			{Label: "Total PRs Closed", Values: []string{strconv.Itoa(result.TotalPRsClosed)}},
+			{Label: "Total Repos", Values: []string{strconv.Itoa(len(result.PRsByRepo))}},
+			{Label: "Total Teams", Values: []string{strconv.Itoa(len(result.PRsByTeam))}},
+			{Label: "Total Users", Values: []string{strconv.Itoa(len(result.PRsByUser))}},
+		}
+		if result.OpenPRs > 0 {
+			rows = append(rows, reportRow{Label: "Open PRs", Values: []string{strconv.Itoa(result.OpenPRs)}})
+		}
+		if len(result.AvgCommitsByTeam) > 0 {
+			rows = append(rows, reportRow{Label: "Avg Commits per Merged PR", Values: []string{strconv.FormatFloat(result.AvgCommitsPerPR, 'f', 2, 64)}})
+		}
+		if result.MergedWithoutApproval != nil {
+			rows = append(rows, reportRow{Label: "PRs Merged Without Approval", Values: []string{strconv.Itoa(result.PRsMergedWithoutApproval)}})
+		}
+		if result.PRsExcludedAsTrivial > 0 {
+			rows = append(rows, reportRow{Label: "PRs Excluded As Trivial", Values: []string{strconv.Itoa(result.PRsExcludedAsTrivial)}})
+		}
+		if result.PRsExcludedAsMassChange > 0 {
+			rows = append(rows, reportRow{Label: "PRs Excluded As Mass Change", Values: []string{strconv.Itoa(result.PRsExcludedAsMassChange)}})
+		}
+		rows = append(rows, reportRow{Label: "PRs Closing Issues", Values: []string{strconv.Itoa(result.PRsClosingIssues)}})
+		if stats := result.PRsPerContributor; stats != nil {
+			rows = append(rows,
+				reportRow{Label: "Median PRs per Contributor", Values: []string{strconv.FormatFloat(stats.Median, 'f', 1, 64)}},
+				reportRow{Label: "P90 PRs per Contributor", Values: []string{strconv.FormatFloat(stats.P90, 'f', 1, 64)}},
+			)
+		}
+		return reportSection{ID: id, Title: "Summary", Headers: []string{"Metric", "Value"}, Rows: rows}, true
+	case "repos":
+		return countsSection(id, "PRs by Repository", "Repository", result.PRsByRepo), true
+	case "teams":
+		return countsSection(id, "PRs by Team", "Team", result.PRsByTeam), true
+	case "users":
+		return countsSection(id, "PRs by User", "User", result.PRsByUser), true
+	case "contributors":
+		if result.PRsByContributor == nil {
+			return reportSection{}, false
+		}
+		return countsSection(id, "PRs by Contributor (Authors and Co-Authors)", "Contributor", result.PRsByContributor), true
+	case "reviewers":
+		if result.ReviewsByUser == nil {
+			return reportSection{}, false
+		}
+		return countsSection(id, "PRs Reviewed by User", "Reviewer", result.ReviewsByUser), true
+	case "companies":
+		if result.PRsByCompany == nil {
+			return reportSection{}, false
+		}
+		return countsSection(id, "PRs by Company", "Company", result.PRsByCompany), true
+	case "issues":
+		return countsSection(id, "Issue-Closing PRs by Team", "Team", result.PRsClosingIssuesByTeam), true
+	case "commits":
+		if len(result.AvgCommitsByTeam) == 0 {
+			return reportSection{}, false
+		}
+		teams := make([]string, 0, len(result.AvgCommitsByTeam))
+		max := 0.0
+		for team, avg := range result.AvgCommitsByTeam {
+			teams = append(teams, team)
+			if avg > max {
+				max = avg
+			}
+		}
+		sort.Slice(teams, func(i, j int) bool {
+			return result.AvgCommitsByTeam[teams[i]] > result.AvgCommitsByTeam[teams[j]]
+		})
+		section := reportSection{ID: id, Title: "Avg Commits per Merged PR by Team", Headers: []string{"Team", "Avg Commits"}, Chart: true}
+		for _, team := range teams {
+			avg := result.AvgCommitsByTeam[team]
+			section.Rows = append(section.Rows, reportRow{Label: team, Values: []string{strconv.FormatFloat(avg, 'f', 2, 64)}, Bar: 100 * avg / max})
+		}
+		return section, true
+	case "pr_sizes":
+		if result.PRSizeHistogram == nil {
+			return reportSection{}, false
+		}
+		section := reportSection{ID: id, Title: "PR Size Histogram", Headers: []string{"Lines Changed", "PR Count"}, Chart: true}
+		buckets := sortedSizeBuckets(result.PRSizeHistogram)
+		max := maxCount(result.PRSizeHistogram)
+		for _, bucket := range buckets {
+			section.Rows = append(section.Rows, reportRow{Label: bucket, Values: []string{strconv.Itoa(result.PRSizeHistogram[bucket])}, Bar: percent(result.PRSizeHistogram[bucket], max)})
+		}
+		return section, true
+	case "merged_without_approval":
+		if result.MergedWithoutApproval == nil {
+			return reportSection{}, false
+		}
+		section := reportSection{ID: id, Title: "PRs Merged Without Approval", Headers: []string{"Repository", "PR", "Author", "Merged At"}}
+		for _, m := range result.MergedWithoutApproval {
+			section.Rows = append(section.Rows, reportRow{Label: m.Repo, Values: []string{strconv.Itoa(m.PRNumber), m.Author, m.MergedAt.Format(time.RFC3339)}})
+		}
+		return section, true
+	case "user_team":
+		if result.PRsByUserTeam == nil {
+			return reportSection{}, false
+		}
+		section := reportSection{ID: id, Title: "PRs by User and Team", Headers: []string{"User", "Team", "PR Count"}}
+		users := make([]string, 0, len(result.PRsByUserTeam))
+		for user := range result.PRsByUserTeam {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+		for _, user := range users {
+			teams := make([]string, 0, len(result.PRsByUserTeam[user]))
+			for team := range result.PRsByUserTeam[user] {
+				teams = append(teams, team)
+			}
+			sort.Strings(teams)
+			for _, team := range teams {
+				section.Rows = append(section.Rows, reportRow{Label: user, Values: []string{team, strconv.Itoa(result.PRsByUserTeam[user][team])}})
+			}
+		}
+		return section, true
+	case "scores":
+		if result.ScoreByTeam == nil {
+			return reportSection{}, false
+		}
+		keys := sortedScoreKeys(result.ScoreByTeam)
+		max := 0.0
+		if len(keys) > 0 {
+			max = result.ScoreByTeam[keys[0]]
+		}
+		section := reportSection{ID: id, Title: "PR Score by Team", Headers: []string{"Team", "Score", "PR Count"}, Chart: true}
+		for _, team := range keys {
+			score := result.ScoreByTeam[team]
+			bar := 0.0
+			if max > 0 {
+				bar = 100 * score / max
+			}
+			section.Rows = append(section.Rows, reportRow{Label: team, Values: []string{strconv.FormatFloat(score, 'f', 2, 64), strconv.Itoa(result.PRsByTeam[team])}, Bar: bar})
+		}
+		return section, true
+	default:
+		return reportSection{}, false
+	}
+}
+
+// countsSection builds a charted section of a PR count breakdown, sorted by count (descending)
+func countsSection(id, title, keyHeader string, counts map[string]int) reportSection {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	max := maxCount(counts)
+	section := reportSection{ID: id, Title: title, Headers: []string{keyHeader, "PR Count"}, Chart: true}
+	for _, key := range keys {
+		section.Rows = append(section.Rows, reportRow{Label: key, Values: []string{strconv.Itoa(counts[key])}, Bar: percent(counts[key], max)})
+	}
+	return section
+}
+
+// maxCount returns the largest count of a breakdown
+func maxCount(counts map[string]int) int {
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+	return max
+}
+
+// percent returns count as a percentage of max
+func percent(count, max int) float64 {
+	if max == 0 {
+		return 0
+	}
+	return 100 * float64(count) / float64(max)
+}
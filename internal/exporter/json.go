@@ -5,20 +5,166 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/fishnix/ghpr-analyzer/internal/calendar"
 	"github.com/google/go-github/v62/github"
 	"go.uber.org/zap"
 )
 
 // AnalysisResult represents the aggregated analysis results
 type AnalysisResult struct {
-	TotalPRsClosed int                    `json:"total_prs_closed"`
-	PRsByRepo      map[string]int         `json:"prs_by_repo"`
-	PRsByTeam      map[string]int         `json:"prs_by_team"`
-	PRsByUser      map[string]int         `json:"prs_by_user"`
-	TimeWindow     TimeWindow             `json:"time_window"`
-	GeneratedAt    time.Time              `json:"generated_at"`
+	TotalPRsClosed             int                         `json:"total_prs_closed"`
+	OpenPRs                    int                         `json:"open_prs,omitempty"` // PRs still open, not in total_prs_closed; with fetch.pr_state all
+	PRsByRepo                  map[string]int              `json:"prs_by_repo"`
+	PRsByTeam                  map[string]int              `json:"prs_by_team"`
+	PRsByUser                  map[string]int              `json:"prs_by_user"`
+	PRsByAuthorAssociation     map[string]int              `json:"prs_by_author_association"`
+	AvgCommitsPerPR            float64                     `json:"avg_commits_per_pr"`
+	AvgCommitsByTeam           map[string]float64          `json:"avg_commits_by_team"`
+	PRsExcludedAsTrivial       int                         `json:"prs_excluded_as_trivial"`
+	PRsExcludedAsMassChange    int                         `json:"prs_excluded_as_mass_change"`
+	PRsClosingIssues           int                         `json:"prs_closing_issues"`
+	PRsClosingIssuesByTeam     map[string]int              `json:"prs_closing_issues_by_team"`
+	PRsMergedWithoutApproval   int                         `json:"prs_merged_without_approval"`
+	SelfMergedPRs              int                         `json:"self_merged_prs,omitempty"` // PRs merged by their author; requires fetch.pr_details
+	SelfMergedPRsByTeam        map[string]int              `json:"self_merged_prs_by_team,omitempty"`
+	MergedWithoutApproval      []UnapprovedMerge           `json:"merged_without_approval,omitempty"`
+	ReviewsByUser              map[string]int              `json:"reviews_by_user,omitempty"`             // PRs reviewed by each user other than their author; requires fetch.reviews
+	CODEOWNERSCoverageByRepo   map[string]float64          `json:"codeowners_coverage_by_repo,omitempty"` // Share of distinct changed files owned; with codeowners.min_coverage
+	ReviewChurn                int                         `json:"review_churn,omitempty"`                // Review dismissals and re-requests; requires fetch.review_events
+	ReviewChurnByRepo          map[string]int              `json:"review_churn_by_repo,omitempty"`
+	ReviewChurnByTeam          map[string]int              `json:"review_churn_by_team,omitempty"`
+	PRsByCompany               map[string]int              `json:"prs_by_company,omitempty"`
+	PRsByBusinessUnit          map[string]int              `json:"prs_by_business_unit,omitempty"`
+	PRsByCategory              map[string]int              `json:"prs_by_category,omitempty"`      // Only with categories configured
+	PRsByTeamCategory          map[string]map[string]int   `json:"prs_by_team_category,omitempty"` // PR count by team, then category
+	PRsByContributor           map[string]int              `json:"prs_by_contributor,omitempty"`
+	PRSizeHistogram            map[string]int              `json:"pr_size_histogram,omitempty"`
+	PRsByUserTeam              map[string]map[string]int   `json:"prs_by_user_team,omitempty"`
+	ScoreByTeam                map[string]float64          `json:"score_by_team,omitempty"`
+	ScoreByUser                map[string]float64          `json:"score_by_user,omitempty"`
+	PRsByCustomMetric          map[string]int              `json:"prs_by_custom_metric,omitempty"`  // PR count by categorical result of metrics.external_command
+	CustomMetricByTeam         map[string]float64          `json:"custom_metric_by_team,omitempty"` // Sum of numeric results of metrics.external_command
+	CustomMetricByUser         map[string]float64          `json:"custom_metric_by_user,omitempty"`
+	CustomMetricFailedPRs      int                         `json:"custom_metric_failed_prs,omitempty"` // PRs the command failed for
+	AvgReleaseLeadTimeHours    float64                     `json:"avg_release_lead_time_hours,omitempty"`
+	ReleaseLeadTimeHoursByRepo map[string]float64          `json:"release_lead_time_hours_by_repo,omitempty"`
+	ReleaseLeadTimeHoursByTeam map[string]float64          `json:"release_lead_time_hours_by_team,omitempty"`
+	UnreleasedPRs              int                         `json:"unreleased_prs,omitempty"`         // Merged PRs no release has been published after
+	DistinctFilesByTeam        map[string]int              `json:"distinct_files_by_team,omitempty"` // Distinct paths owned by each team among the changed files; only with output.files_touched
+	PRsPerContributor          *ContributorStats           `json:"prs_per_contributor,omitempty"`    // nil when no author reaches metrics.min_contributor_prs
+	CycleTime                  *CycleTimeStats             `json:"cycle_time,omitempty"`             // Open to merge (or close) over all PRs; nil when no PR has both timestamps
+	CycleTimeByTeam            map[string]*CycleTimeStats  `json:"cycle_time_by_team"`
+	CycleTimeByRepo            map[string]*CycleTimeStats  `json:"cycle_time_by_repo"`
+	CollaborationEdges         []CollaborationEdge         `json:"collaboration_edges,omitempty"`
+	TeamsDetail                []TeamDetail                `json:"-"` // Exported separately to teams_detail.json
+	OwningTeams                map[string]map[int][]string `json:"-"` // Teams of each PR by repository and PR number, exported with the per-repo PRs
+	TimeWindow                 TimeWindow                  `json:"time_window"`
+	GeneratedAt                time.Time                   `json:"generated_at"`
+}
+
+// ContributorStats describes the distribution of PRs per contributor over the time window
+type ContributorStats struct {
+	Contributors int     `json:"contributors"` // Authors with at least MinPRs PRs
+	MinPRs       int     `json:"min_prs"`
+	Mean         float64 `json:"mean"`
+	Median       float64 `json:"median"`
+	P90          float64 `json:"p90"`
+}
+
+// CycleTimeStats describes the distribution of PR cycle times (creation to
+// merge, or to close for PRs closed without merging) in hours
+type CycleTimeStats struct {
+	PRs    int     `json:"prs"`
+	Mean   float64 `json:"mean_hours"`
+	Median float64 `json:"median_hours"` // Middle value, averaging the two middle ones for an even count
+	P50    float64 `json:"p50_hours"`    // Nearest rank, like P90 and P95
+	P90    float64 `json:"p90_hours"`
+	P95    float64 `json:"p95_hours"`
+}
+
+// CollaborationEdge counts the merged PRs of an author reviewed by a reviewer
+type CollaborationEdge struct {
+	Reviewer string `json:"reviewer"`
+	Author   string `json:"author"`
+	Count    int    `json:"count"`
+}
+
+// TeamDetail is a team's PR count with the repositories and users it came from
+type TeamDetail struct {
+	Team  string       `json:"team"`
+	PRs   int          `json:"prs"`
+	Repos []NamedCount `json:"repos"` // Most PRs first
+	Users []NamedCount `json:"users"` // Most PRs first
+}
+
+// NamedCount is the PR count of a repository or user
+type NamedCount struct {
+	Name string `json:"name"`
+	PRs  int    `json:"prs"`
+}
+
+// UnapprovedMerge represents a merged PR that had no approving review
+type UnapprovedMerge struct {
+	Repo     string    `json:"repo"`
+	PRNumber int       `json:"pr_number"`
+	Author   string    `json:"author"`
+	MergedAt time.Time `json:"merged_at"`
+}
+
+// OwnershipChange represents a path whose CODEOWNERS owners differ between two refs
+type OwnershipChange struct {
+	Repo           string   `json:"repo"`
+	Path           string   `json:"path"`
+	PreviousOwners []string `json:"previous_owners"`
+	CurrentOwners  []string `json:"current_owners"`
+	TeamsGained    []string `json:"teams_gained"`
+	TeamsLost      []string `json:"teams_lost"`
+}
+
+// APIUsage represents the GitHub API cost of a run
+type APIUsage struct {
+	RateLimitSleeps       int     `json:"rate_limit_sleeps"`
+	RateLimitSleepSeconds float64 `json:"rate_limit_sleep_seconds"`
+}
+
+// RateLimitSample represents the rate limit reported by one API response
+type RateLimitSample struct {
+	Time      time.Time
+	Resource  string
+	Remaining int
+	Limit     int
+}
+
+// RunReport represents the errors and warnings encountered during a run
+type RunReport struct {
+	Errors   int        `json:"errors"`
+	Warnings int        `json:"warnings"`
+	Events   []RunEvent `json:"events"`
+}
+
+// RunStatus is the machine-readable outcome of a run, written whether it
+// succeeded or not
+type RunStatus struct {
+	Success         bool    `json:"success"`
+	Error           string  `json:"error,omitempty"`
+	ReposAnalyzed   int     `json:"repos_analyzed"`
+	ReposErrored    int     `json:"repos_errored"`
+	TotalPRs        int     `json:"total_prs"`
+	APICalls        int64   `json:"api_calls"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	RateLimited     bool    `json:"rate_limited"` // The run slept waiting on a rate limit
+}
+
+// RunEvent represents a single error or warning of a run
+type RunEvent struct {
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"` // "error" | "warning"
+	Repo     string    `json:"repo,omitempty"`
+	PRNumber int       `json:"pr_number,omitempty"`
+	Message  string    `json:"message"`
 }
 
 // TimeWindow represents the analysis time window
@@ -27,20 +173,74 @@ type TimeWindow struct {
 	Until time.Time `json:"until"`
 }
 
+// combinedReportVersion is bumped whenever the layout of report.json changes incompatibly
+const combinedReportVersion = 1
+
+// CombinedReport is everything one run produced, in a single versioned document
+type CombinedReport struct {
+	Version  int                     `json:"version"`
+	Result   *AnalysisResult         `json:"result"`
+	Repos    map[string][]CombinedPR `json:"repos"`
+	APIUsage *APIUsage               `json:"api_usage,omitempty"`
+}
+
+// CombinedPR is a per-repo PR with its computed metrics
+type CombinedPR struct {
+	RepoPR
+	Additions    int     `json:"additions,omitempty"`     // Requires fetch.pr_details
+	Deletions    int     `json:"deletions,omitempty"`     // Requires fetch.pr_details
+	ChangedFiles int     `json:"changed_files,omitempty"` // Requires fetch.pr_details
+	Commits      int     `json:"commits,omitempty"`       // Requires fetch.pr_details
+	HoursToClose float64 `json:"hours_to_close"`
+}
+
 // JSONExporter exports analysis results to JSON format
 type JSONExporter struct {
-	outputDir string
-	logger    *zap.Logger
+	outputDir     string
+	compact       bool
+	includeBody   bool
+	bodyMaxLength int
+	calendar      *calendar.Calendar // Measures durations; nil means wall clock
+	identities    map[string]string  // Canonical identity by lowercased aliased login
+	logger        *zap.Logger
 }
 
-// NewJSONExporter creates a new JSON exporter
-func NewJSONExporter(outputDir string, logger *zap.Logger) *JSONExporter {
+// NewJSONExporter creates a new JSON exporter. Output is indented unless compact is set.
+func NewJSONExporter(outputDir string, compact bool, logger *zap.Logger) *JSONExporter {
 	return &JSONExporter{
 		outputDir: outputDir,
+		compact:   compact,
 		logger:    logger,
 	}
 }
 
+// IncludePRBody adds PR bodies to the per-repo export, truncated to maxLength
+// characters (0 means no limit)
+func (e *JSONExporter) IncludePRBody(maxLength int) {
+	e.includeBody = true
+	e.bodyMaxLength = maxLength
+}
+
+// SetCalendar sets how durations such as hours_to_close are measured; nil
+// measures wall-clock time
+func (e *JSONExporter) SetCalendar(cal *calendar.Calendar) {
+	e.calendar = cal
+}
+
+// SetIdentityMap sets the canonical identity that per-PR authors are reported
+// as, by lowercased aliased login; unmapped logins are reported unchanged
+func (e *JSONExporter) SetIdentityMap(identities map[string]string) {
+	e.identities = identities
+}
+
+// marshal encodes v, indented unless the exporter is compact
+func (e *JSONExporter) marshal(v interface{}) ([]byte, error) {
+	if e.compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
 // Export exports the analysis results to JSON
 func (e *JSONExporter) Export(result *AnalysisResult) error {
 	e.logger.Info("Exporting results to JSON", zap.String("output_dir", e.outputDir))
@@ -53,8 +253,8 @@ func (e *JSONExporter) Export(result *AnalysisResult) error {
 	// Create output file path
 	outputPath := filepath.Join(e.outputDir, "analysis_results.json")
 
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+	// Marshal to JSON
+	jsonData, err := e.marshal(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -70,17 +270,20 @@ func (e *JSONExporter) Export(result *AnalysisResult) error {
 
 // RepoPR represents a PR for per-repo export
 type RepoPR struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	Author    string    `json:"author"`
-	State     string    `json:"state"`
-	CreatedAt time.Time `json:"created_at"`
-	ClosedAt  time.Time `json:"closed_at"`
-	URL       string    `json:"url"`
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	State       string    `json:"state"`
+	CreatedAt   time.Time `json:"created_at"`
+	ClosedAt    time.Time `json:"closed_at"`
+	URL         string    `json:"url"`
+	OwningTeams []string  `json:"owning_teams"`   // Teams the PR is attributed to, after rollups
+	Body        string    `json:"body,omitempty"` // Only with output.include_pr_body
 }
 
-// ExportPerRepo exports PRs grouped by repository
-func (e *JSONExporter) ExportPerRepo(repoPRs map[string][]*github.PullRequest) error {
+// ExportPerRepo exports PRs grouped by repository, each with its owning teams
+// looked up by repository and PR number
+func (e *JSONExporter) ExportPerRepo(repoPRs map[string][]*github.PullRequest, owningTeams map[string]map[int][]string) error {
 	e.logger.Info("Exporting per-repo PRs to JSON")
 
 	// Create output directory if it doesn't exist
@@ -93,27 +296,15 @@ func (e *JSONExporter) ExportPerRepo(repoPRs map[string][]*github.PullRequest) e
 	for repo, prs := range repoPRs {
 		exportData[repo] = make([]RepoPR, 0, len(prs))
 		for _, pr := range prs {
-			author := ""
-			if pr.User != nil {
-				author = pr.User.GetLogin()
-			}
-			exportData[repo] = append(exportData[repo], RepoPR{
-				Number:    pr.GetNumber(),
-				Title:     pr.GetTitle(),
-				Author:    author,
-				State:     pr.GetState(),
-				CreatedAt: pr.GetCreatedAt().Time,
-				ClosedAt:  pr.GetClosedAt().Time,
-				URL:       pr.GetHTMLURL(),
-			})
+			exportData[repo] = append(exportData[repo], e.toRepoPR(pr, owningTeams[repo][pr.GetNumber()]))
 		}
 	}
 
 	// Create output file path
 	outputPath := filepath.Join(e.outputDir, "prs_by_repo.json")
 
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(exportData, "", "  ")
+	// Marshal to JSON
+	jsonData, err := e.marshal(exportData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -127,3 +318,185 @@ func (e *JSONExporter) ExportPerRepo(repoPRs map[string][]*github.PullRequest) e
 	return nil
 }
 
+// ExportAPIUsage exports the run's API usage to api_usage.json
+func (e *JSONExporter) ExportAPIUsage(usage *APIUsage) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "api_usage.json")
+
+	// Marshal to JSON
+	jsonData, err := e.marshal(usage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	e.logger.Debug("Exported API usage", zap.String("path", outputPath))
+	return nil
+}
+
+// ExportTeamsDetail exports each team with its repositories and users to teams_detail.json
+func (e *JSONExporter) ExportTeamsDetail(teams []TeamDetail) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "teams_detail.json")
+
+	// Marshal to JSON
+	jsonData, err := e.marshal(teams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	e.logger.Info("Exported team details", zap.String("path", outputPath), zap.Int("teams", len(teams)))
+	return nil
+}
+
+// ExportStatus exports the run's outcome to status.json
+func (e *JSONExporter) ExportStatus(status *RunStatus) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "status.json")
+
+	// Marshal to JSON
+	jsonData, err := e.marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	e.logger.Debug("Exported run status", zap.String("path", outputPath))
+	return nil
+}
+
+// ExportRunReport exports the run's errors and warnings to run_report.json
+func (e *JSONExporter) ExportRunReport(report *RunReport) error {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(e.outputDir, "run_report.json")
+
+	// Marshal to JSON
+	jsonData, err := e.marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	e.logger.Debug("Exported run report", zap.String("path", outputPath))
+	return nil
+}
+
+// ExportCombined exports the aggregate result, the per-repo PRs with their
+// computed metrics and the API usage to a single report.json
+func (e *JSONExporter) ExportCombined(result *AnalysisResult, repoPRs map[string][]*github.PullRequest, usage *APIUsage) error {
+	e.logger.Info("Exporting combined report to JSON")
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	report := CombinedReport{
+		Version:  combinedReportVersion,
+		Result:   result,
+		Repos:    make(map[string][]CombinedPR, len(repoPRs)),
+		APIUsage: usage,
+	}
+	for repo, prs := range repoPRs {
+		report.Repos[repo] = make([]CombinedPR, 0, len(prs))
+		for _, pr := range prs {
+			combined := CombinedPR{
+				RepoPR:       e.toRepoPR(pr, result.OwningTeams[repo][pr.GetNumber()]),
+				Additions:    pr.GetAdditions(),
+				Deletions:    pr.GetDeletions(),
+				ChangedFiles: pr.GetChangedFiles(),
+				Commits:      pr.GetCommits(),
+			}
+			if pr.CreatedAt != nil && pr.ClosedAt != nil {
+				combined.HoursToClose = e.calendar.Duration(pr.GetCreatedAt().Time, pr.GetClosedAt().Time).Hours()
+			}
+			report.Repos[repo] = append(report.Repos[repo], combined)
+		}
+	}
+
+	outputPath := filepath.Join(e.outputDir, "report.json")
+
+	// Marshal to JSON
+	jsonData, err := e.marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	e.logger.Info("Combined JSON export complete", zap.String("path", outputPath))
+	return nil
+}
+
+// toRepoPR converts a pull request to its per-repo export form
+func (e *JSONExporter) toRepoPR(pr *github.PullRequest, owningTeams []string) RepoPR {
+	author := ""
+	if pr.User != nil {
+		author = pr.User.GetLogin()
+	}
+	if canonical, ok := e.identities[strings.ToLower(author)]; ok {
+		author = canonical
+	}
+
+	// Bodies can be huge (e.g. pasted logs), so they are capped
+	body := ""
+	if e.includeBody {
+		body = pr.GetBody()
+		if runes := []rune(body); e.bodyMaxLength > 0 && len(runes) > e.bodyMaxLength {
+			body = string(runes[:e.bodyMaxLength])
+		}
+	}
+
+	// Always an array, so consumers need not tell a missing field from no teams
+	if owningTeams == nil {
+		owningTeams = []string{}
+	}
+
+	return RepoPR{
+		Number:      pr.GetNumber(),
+		Title:       pr.GetTitle(),
+		Author:      author,
+		State:       pr.GetState(),
+		CreatedAt:   pr.GetCreatedAt().Time,
+		ClosedAt:    pr.GetClosedAt().Time,
+		URL:         pr.GetHTMLURL(),
+		OwningTeams: owningTeams,
+		Body:        body,
+	}
+}
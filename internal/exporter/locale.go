@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeFormat holds how a locale writes dates and numbers
+type localeFormat struct {
+	date      string // Layout of dates
+	dateTime  string // Layout of timestamps
+	thousands string // Digit group separator; empty leaves numbers ungrouped
+	decimal   string
+}
+
+// defaultFormat is the formatting without output.locale: ISO dates and
+// ungrouped numbers
+var defaultFormat = localeFormat{"2006-01-02", "2006-01-02 15:04:05", "", "."}
+
+// Locales lists the locales that output.locale can select
+var Locales = map[string]localeFormat{
+	"en-US": {"01/02/2006", "01/02/2006 3:04:05 PM", ",", "."},
+	"en-GB": {"02/01/2006", "02/01/2006 15:04:05", ",", "."},
+	"de-DE": {"02.01.2006", "02.01.2006 15:04:05", ".", ","},
+	"fr-FR": {"02/01/2006", "02/01/2006 15:04:05", " ", ","},
+	"es-ES": {"02/01/2006", "02/01/2006 15:04:05", ".", ","},
+	"it-IT": {"02/01/2006", "02/01/2006 15:04:05", ".", ","},
+	"nl-NL": {"02-01-2006", "02-01-2006 15:04:05", ".", ","},
+	"pl-PL": {"02.01.2006", "02.01.2006 15:04:05", " ", ","},
+	"sv-SE": {"2006-01-02", "2006-01-02 15:04:05", " ", ","},
+}
+
+// LocaleNames returns the names of Locales, sorted
+func LocaleNames() []string {
+	names := make([]string, 0, len(Locales))
+	for name := range Locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Locale formats the dates and numbers of human-readable output. A nil
+// *Locale formats like an unset output.locale and output.timezone.
+type Locale struct {
+	format   localeFormat
+	location *time.Location // nil keeps timestamps in their own time zone
+}
+
+// NewLocale creates the formatting of a locale (one of Locales, or empty for
+// the default) and an IANA time zone (empty keeps timestamps' own zone)
+func NewLocale(locale, timezone string) (*Locale, error) {
+	l := &Locale{format: defaultFormat}
+	if locale != "" {
+		format, ok := Locales[locale]
+		if !ok {
+			return nil, fmt.Errorf("unknown locale %q", locale)
+		}
+		l.format = format
+	}
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q: %w", timezone, err)
+		}
+		l.location = location
+	}
+	return l, nil
+}
+
+// In returns t in the locale's time zone
+func (l *Locale) In(t time.Time) time.Time {
+	if l == nil || l.location == nil {
+		return t
+	}
+	return t.In(l.location)
+}
+
+// Date formats the date of t
+func (l *Locale) Date(t time.Time) string {
+	return l.In(t).Format(l.formatOrDefault().date)
+}
+
+// DateTime formats t with its time of day
+func (l *Locale) DateTime(t time.Time) string {
+	return l.In(t).Format(l.formatOrDefault().dateTime)
+}
+
+// Int formats a count with the locale's digit grouping
+func (l *Locale) Int(n int) string {
+	return groupDigits(strconv.Itoa(n), l.formatOrDefault().thousands)
+}
+
+// Float formats a number with prec decimals, the locale's digit grouping and
+// decimal separator
+func (l *Locale) Float(f float64, prec int) string {
+	format := l.formatOrDefault()
+	whole, fraction, _ := strings.Cut(strconv.FormatFloat(f, 'f', prec, 64), ".")
+	whole = groupDigits(whole, format.thousands)
+	if fraction == "" {
+		return whole
+	}
+	return whole + format.decimal + fraction
+}
+
+// formatOrDefault returns the locale's format, or the default one for a nil locale
+func (l *Locale) formatOrDefault() localeFormat {
+	if l == nil {
+		return defaultFormat
+	}
+	return l.format
+}
+
+// groupDigits inserts sep between groups of three digits of an integer
+func groupDigits(digits, sep string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if sep == "" || len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
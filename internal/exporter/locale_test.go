@@ -0,0 +1,116 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		digits, sep, want string
+	}{
+		{"0", ",", "0"},
+		{"7", ",", "7"},
+		{"999", ",", "999"},
+		{"1000", ",", "1,000"},
+		{"12345", ",", "12,345"},
+		{"123456", ",", "123,456"},
+		{"1234567", ".", "1.234.567"},
+		{"-1", ",", "-1"},
+		{"-999", ",", "-999"},
+		{"-1000", ",", "-1,000"},
+		{"-123456", " ", "-123 456"},
+		{"1234567", "", "1234567"},
+		{"-1234567", "", "-1234567"},
+	}
+	for _, tt := range tests {
+		if got := groupDigits(tt.digits, tt.sep); got != tt.want {
+			t.Errorf("groupDigits(%q, %q) = %q, want %q", tt.digits, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleInt(t *testing.T) {
+	for name, format := range Locales {
+		l, err := NewLocale(name, "")
+		if err != nil {
+			t.Fatalf("NewLocale(%q) = %v", name, err)
+		}
+		sep := format.thousands
+		tests := []struct {
+			n    int
+			want string
+		}{
+			{0, "0"},
+			{42, "42"},
+			{999, "999"},
+			{1000, "1" + sep + "000"},
+			{1234567, "1" + sep + "234" + sep + "567"},
+			{-5, "-5"},
+			{-1234567, "-1" + sep + "234" + sep + "567"},
+		}
+		for _, tt := range tests {
+			if got := l.Int(tt.n); got != tt.want {
+				t.Errorf("%s: Int(%d) = %q, want %q", name, tt.n, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLocaleFloat(t *testing.T) {
+	locale := func(name string) *Locale {
+		l, err := NewLocale(name, "")
+		if err != nil {
+			t.Fatalf("NewLocale(%q) = %v", name, err)
+		}
+		return l
+	}
+	tests := []struct {
+		locale *Locale
+		f      float64
+		prec   int
+		want   string
+	}{
+		{nil, 0, 2, "0.00"},
+		{nil, 1234.5, 1, "1234.5"},
+		{nil, -1234.5, 0, "-1234"},
+		{locale("en-US"), 0.25, 2, "0.25"},
+		{locale("en-US"), 999.99, 2, "999.99"},
+		{locale("en-US"), 1234567.891, 2, "1,234,567.89"},
+		{locale("en-US"), -1234.5, 1, "-1,234.5"},
+		{locale("en-US"), 1234.5, 0, "1,234"},
+		{locale("de-DE"), 0, 1, "0,0"},
+		{locale("de-DE"), 1234567.891, 2, "1.234.567,89"},
+		{locale("de-DE"), -0.5, 1, "-0,5"},
+		{locale("fr-FR"), 1234.5, 1, "1\u202f234,5"},
+		{locale("sv-SE"), -98765.4, 1, "-98\u00a0765,4"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Float(tt.f, tt.prec); got != tt.want {
+			t.Errorf("Float(%v, %d) = %q, want %q", tt.f, tt.prec, got, tt.want)
+		}
+	}
+}
+
+func TestNewLocale(t *testing.T) {
+	if _, err := NewLocale("xx-XX", ""); err == nil {
+		t.Error("expected an error for an unknown locale")
+	}
+	if _, err := NewLocale("", "Nowhere/Special"); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+
+	l, err := NewLocale("de-DE", "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 10, 1, 23, 30, 0, 0, time.UTC)
+	if got, want := l.DateTime(at), "02.10.2025 01:30:00"; got != want {
+		t.Errorf("DateTime() = %q, want %q", got, want)
+	}
+
+	var unset *Locale
+	if got, want := unset.Date(at), "2025-10-01"; got != want {
+		t.Errorf("Date() of a nil locale = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MarkdownSummary renders the headline metrics and top breakdowns of the
+// results as GitHub-flavored Markdown, e.g. for an issue comment, with dates
+// and numbers formatted for locale (nil for the default formatting)
+func MarkdownSummary(result *AnalysisResult, locale *Locale) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## GitHub PR Analysis Summary\n\n")
+	fmt.Fprintf(&b, "**Time Window:** %s to %s  \n", locale.Date(result.TimeWindow.Since), locale.Date(result.TimeWindow.Until))
+	fmt.Fprintf(&b, "**Generated At:** %s\n\n", locale.DateTime(result.GeneratedAt))
+
+	fmt.Fprintf(&b, "| Metric | Value |\n|---|---:|\n")
+	fmt.Fprintf(&b, "| Total PRs Closed | %s |\n", locale.Int(result.TotalPRsClosed))
+	if result.OpenPRs > 0 {
+		fmt.Fprintf(&b, "| Open PRs | %s |\n", locale.Int(result.OpenPRs))
+	}
+	if len(result.AvgCommitsByTeam) > 0 {
+		fmt.Fprintf(&b, "| Avg Commits per Merged PR | %s |\n", locale.Float(result.AvgCommitsPerPR, 2))
+	}
+	if result.MergedWithoutApproval != nil {
+		fmt.Fprintf(&b, "| PRs Merged Without Approval | %s |\n", locale.Int(result.PRsMergedWithoutApproval))
+	}
+	if result.SelfMergedPRsByTeam != nil {
+		fmt.Fprintf(&b, "| Self-Merged PRs | %s |\n", locale.Int(result.SelfMergedPRs))
+	}
+	if result.PRsExcludedAsTrivial > 0 {
+		fmt.Fprintf(&b, "| PRs Excluded As Trivial | %s |\n", locale.Int(result.PRsExcludedAsTrivial))
+	}
+	if result.PRsExcludedAsMassChange > 0 {
+		fmt.Fprintf(&b, "| PRs Excluded As Mass Change | %s |\n", locale.Int(result.PRsExcludedAsMassChange))
+	}
+	fmt.Fprintf(&b, "| PRs Closing Issues | %s |\n", locale.Int(result.PRsClosingIssues))
+	if stats := result.PRsPerContributor; stats != nil {
+		fmt.Fprintf(&b, "| Median PRs per Contributor (p90) | %s (%s) |\n", locale.Float(stats.Median, 1), locale.Float(stats.P90, 1))
+	}
+	if result.ReleaseLeadTimeHoursByRepo != nil {
+		fmt.Fprintf(&b, "| Avg Merge-to-Release Lead Time (hours) | %s |\n", locale.Float(result.AvgReleaseLeadTimeHours, 1))
+		fmt.Fprintf(&b, "| Unreleased Merged PRs | %s |\n", locale.Int(result.UnreleasedPRs))
+	}
+	b.WriteString("\n")
+
+	writeMarkdownCounts(&b, locale, "Top Repositories by PR Count", "Repository", result.PRsByRepo)
+	writeMarkdownCounts(&b, locale, "Top Teams by PR Count", "Team", result.PRsByTeam)
+	writeMarkdownCounts(&b, locale, "Top Users by PR Count", "User", result.PRsByUser)
+
+	return b.String()
+}
+
+// writeMarkdownCounts writes the ten largest entries of a PR count breakdown as a table
+func writeMarkdownCounts(b *strings.Builder, locale *Locale, title, keyHeader string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > 10 {
+		keys = keys[:10]
+	}
+
+	fmt.Fprintf(b, "### %s\n\n| %s | PRs |\n|---|---:|\n", title, keyHeader)
+	for _, key := range keys {
+		fmt.Fprintf(b, "| %s | %s |\n", strings.ReplaceAll(key, "|", `\|`), locale.Int(counts[key]))
+	}
+	b.WriteString("\n")
+}
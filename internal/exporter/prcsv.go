@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// prCSVFlushRows is how many rows are buffered before the writer flushes to disk
+const prCSVFlushRows = 1000
+
+// PRRow is one PR of the detailed per-PR exports (prs.csv and the results database)
+type PRRow struct {
+	Repo      string
+	Number    int
+	Title     string
+	Author    string
+	State     string
+	CreatedAt time.Time
+	ClosedAt  time.Time
+	MergedAt  time.Time // Zero when the PR was closed without merging
+	Teams     []string
+	URL       string
+}
+
+// PRCSVWriter streams PRs to prs.csv as they are produced, flushing every
+// prCSVFlushRows rows, so memory stays flat regardless of the number of PRs.
+// Write errors are sticky and reported by Close. All methods are no-ops on a
+// nil writer.
+type PRCSVWriter struct {
+	file   *os.File
+	writer *csv.Writer
+	path   string
+	rows   int
+	err    error
+	logger *zap.Logger
+}
+
+// NewPRCSVWriter creates prs.csv in the output directory and writes its header
+func NewPRCSVWriter(outputDir string, logger *zap.Logger) (*PRCSVWriter, error) {
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(outputDir, "prs.csv")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CSV file: %w", err)
+	}
+
+	w := &PRCSVWriter{
+		file:   file,
+		writer: csv.NewWriter(file),
+		path:   outputPath,
+		logger: logger,
+	}
+	header := []string{"Repository", "PR Number", "Title", "Author", "State", "Created At", "Closed At", "Merged At", "Teams", "URL"}
+	if err := w.writer.Write(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return w, nil
+}
+
+// Write writes one PR row
+func (w *PRCSVWriter) Write(row PRRow) {
+	if w == nil || w.err != nil {
+		return
+	}
+
+	mergedAt := ""
+	if !row.MergedAt.IsZero() {
+		mergedAt = row.MergedAt.Format(time.RFC3339)
+	}
+	record := []string{
+		row.Repo,
+		strconv.Itoa(row.Number),
+		row.Title,
+		row.Author,
+		row.State,
+		row.CreatedAt.Format(time.RFC3339),
+		row.ClosedAt.Format(time.RFC3339),
+		mergedAt,
+		strings.Join(row.Teams, ";"),
+		row.URL,
+	}
+	if err := w.writer.Write(record); err != nil {
+		w.err = fmt.Errorf("failed to write record: %w", err)
+		return
+	}
+
+	w.rows++
+	if w.rows%prCSVFlushRows == 0 {
+		w.writer.Flush()
+		if err := w.writer.Error(); err != nil {
+			w.err = fmt.Errorf("failed to flush CSV: %w", err)
+		}
+	}
+}
+
+// Close flushes the remaining rows and closes the file, returning the first error
+func (w *PRCSVWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil && w.err == nil {
+		w.err = fmt.Errorf("failed to flush CSV: %w", err)
+	}
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = fmt.Errorf("failed to close CSV file: %w", err)
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	w.logger.Debug("Exported detailed PRs", zap.String("path", w.path), zap.Int("rows", w.rows))
+	return nil
+}
@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+// resultsSchema creates the tables of the results database. Every run adds a
+// row to runs, and the counts and PRs of that run reference it by run_id, so
+// runs of the same window, even within the same second, never collide.
+const resultsSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	since TEXT NOT NULL,
+	until TEXT NOT NULL,
+	generated_at TEXT NOT NULL,
+	total_prs INTEGER NOT NULL,
+	result BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS team_counts (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	team TEXT NOT NULL,
+	pr_count INTEGER NOT NULL,
+	PRIMARY KEY (run_id, team)
+);
+
+CREATE TABLE IF NOT EXISTS repo_counts (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	repo TEXT NOT NULL,
+	pr_count INTEGER NOT NULL,
+	PRIMARY KEY (run_id, repo)
+);
+
+CREATE TABLE IF NOT EXISTS user_counts (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	user TEXT NOT NULL,
+	pr_count INTEGER NOT NULL,
+	PRIMARY KEY (run_id, user)
+);
+
+CREATE TABLE IF NOT EXISTS prs (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	repo TEXT NOT NULL,
+	pr_number INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	state TEXT NOT NULL,
+	created_at TEXT,
+	closed_at TEXT,
+	merged_at TEXT,
+	teams TEXT NOT NULL,
+	url TEXT NOT NULL,
+	PRIMARY KEY (run_id, repo, pr_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_counts_team ON team_counts (team);
+CREATE INDEX IF NOT EXISTS idx_repo_counts_repo ON repo_counts (repo);
+CREATE INDEX IF NOT EXISTS idx_user_counts_user ON user_counts (user);
+`
+
+// SQLiteExporter appends the results of each run to a SQLite database, so
+// runs accumulate into one queryable history. It is separate from the cache.
+type SQLiteExporter struct {
+	path   string
+	logger *zap.Logger
+}
+
+// NewSQLiteExporter creates a new SQLite exporter writing to the database at path
+func NewSQLiteExporter(path string, logger *zap.Logger) *SQLiteExporter {
+	return &SQLiteExporter{
+		path:   path,
+		logger: logger,
+	}
+}
+
+// Export appends a run, with its team, repository and user counts and its
+// PRs, to the results database, creating the database if needed
+func (e *SQLiteExporter) Export(ctx context.Context, result *AnalysisResult, prs []PRRow) error {
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", e.path+"?_busy_timeout=5000")
+	if err != nil {
+		return fmt.Errorf("failed to open results database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, resultsSchema); err != nil {
+		return fmt.Errorf("failed to initialize results database: %w", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO runs (since, until, generated_at, total_prs, result) VALUES (?, ?, ?, ?, ?)`,
+		formatTime(result.TimeWindow.Since), formatTime(result.TimeWindow.Until), result.GeneratedAt.UTC().Format(generatedAtLayout), result.TotalPRsClosed, data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read run id: %w", err)
+	}
+
+	counts := []struct {
+		table, column string
+		counts        map[string]int
+	}{
+		{"team_counts", "team", result.PRsByTeam},
+		{"repo_counts", "repo", result.PRsByRepo},
+		{"user_counts", "user", result.PRsByUser},
+	}
+	for _, c := range counts {
+		query := fmt.Sprintf(`INSERT INTO %s (run_id, %s, pr_count) VALUES (?, ?, ?)`, c.table, c.column)
+		for key, count := range c.counts {
+			if _, err := tx.ExecContext(ctx, query, runID, key, count); err != nil {
+				return fmt.Errorf("failed to insert into %s: %w", c.table, err)
+			}
+		}
+	}
+
+	for _, pr := range prs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO prs (run_id, repo, pr_number, title, author, state, created_at, closed_at, merged_at, teams, url)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			runID, pr.Repo, pr.Number, pr.Title, pr.Author, pr.State,
+			nullableTime(pr.CreatedAt), nullableTime(pr.ClosedAt), nullableTime(pr.MergedAt),
+			strings.Join(pr.Teams, ";"), pr.URL,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert PR: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	e.logger.Info("Exported results to SQLite",
+		zap.String("path", e.path),
+		zap.Int64("run_id", runID),
+		zap.Int("prs", len(prs)),
+	)
+	return nil
+}
+
+// generatedAtLayout is RFC3339 with fixed-width nanoseconds, so generation
+// times still sort as text and databases created with a unique
+// (since, until, generated_at) key accept runs started in the same second
+const generatedAtLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime formats a timestamp as RFC3339 in UTC, which sorts and compares
+// correctly as text in SQL
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// nullableTime formats a timestamp like formatTime, or returns NULL for a zero one
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return formatTime(t)
+}
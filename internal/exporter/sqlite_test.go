@@ -0,0 +1,104 @@
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSQLiteExporterRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+	e := NewSQLiteExporter(path, zap.NewNop())
+
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	closed := time.Date(2025, 9, 15, 12, 0, 0, 0, time.UTC)
+	result := &AnalysisResult{
+		TotalPRsClosed: 2,
+		PRsByRepo:      map[string]int{"myorg/api": 2},
+		PRsByTeam:      map[string]int{"platform": 2, "web": 1},
+		PRsByUser:      map[string]int{"alice": 1, "bob": 1},
+		GeneratedAt:    time.Date(2025, 10, 1, 8, 0, 0, 100, time.UTC),
+	}
+	result.TimeWindow.Since = since
+	result.TimeWindow.Until = until
+	prs := []PRRow{
+		{Repo: "myorg/api", Number: 1, Title: "one", Author: "alice", State: "closed", ClosedAt: closed, MergedAt: closed, Teams: []string{"platform", "web"}, URL: "https://github.com/myorg/api/pull/1"},
+		{Repo: "myorg/api", Number: 2, Title: "two", Author: "bob", State: "closed", ClosedAt: closed, Teams: []string{"platform"}, URL: "https://github.com/myorg/api/pull/2"},
+	}
+
+	// Two runs of the same window within the same second are both kept
+	if err := e.Export(ctx, result, prs); err != nil {
+		t.Fatalf("first Export: %v", err)
+	}
+	result.GeneratedAt = result.GeneratedAt.Add(time.Millisecond)
+	if err := e.Export(ctx, result, prs); err != nil {
+		t.Fatalf("second Export: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var runs int
+	var gotSince, gotUntil string
+	if err := db.QueryRow(`SELECT COUNT(*), MIN(since), MIN(until) FROM runs`).Scan(&runs, &gotSince, &gotUntil); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Fatalf("runs = %d, want 2", runs)
+	}
+	if gotSince != "2025-09-01T00:00:00Z" || gotUntil != "2025-10-01T00:00:00Z" {
+		t.Errorf("window = %s..%s, want 2025-09-01T00:00:00Z..2025-10-01T00:00:00Z", gotSince, gotUntil)
+	}
+
+	var runID int64
+	if err := db.QueryRow(`SELECT MAX(id) FROM runs`).Scan(&runID); err != nil {
+		t.Fatal(err)
+	}
+	var teamCount int
+	if err := db.QueryRow(`SELECT pr_count FROM team_counts WHERE run_id = ? AND team = 'platform'`, runID).Scan(&teamCount); err != nil {
+		t.Fatal(err)
+	}
+	if teamCount != 2 {
+		t.Errorf("platform pr_count = %d, want 2", teamCount)
+	}
+
+	rows, err := db.Query(`SELECT pr_number, author, teams, merged_at FROM prs WHERE run_id = ? ORDER BY pr_number`, runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type prRow struct {
+		number        int
+		author, teams string
+		mergedAt      sql.NullString
+	}
+	var got []prRow
+	for rows.Next() {
+		var r prRow
+		if err := rows.Scan(&r.number, &r.author, &r.teams, &r.mergedAt); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("prs of run %d = %+v, want 2", runID, got)
+	}
+	if got[0].author != "alice" || got[0].teams != "platform;web" || got[0].mergedAt.String != "2025-09-15T12:00:00Z" {
+		t.Errorf("PR 1 = %+v, want alice, platform;web, merged 2025-09-15T12:00:00Z", got[0])
+	}
+	if got[1].author != "bob" || got[1].mergedAt.Valid {
+		t.Errorf("PR 2 = %+v, want bob, not merged", got[1])
+	}
+}
@@ -4,22 +4,32 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 )
 
 // SummaryExporter exports human-readable summary
 type SummaryExporter struct {
-	logger *zap.Logger
+	breakdowns breakdownFilter
+	locale     *Locale // nil formats dates and numbers the default way
+	logger     *zap.Logger
 }
 
-// NewSummaryExporter creates a new summary exporter
-func NewSummaryExporter(logger *zap.Logger) *SummaryExporter {
+// NewSummaryExporter creates a new summary exporter. Only the given breakdowns
+// are printed; an empty list prints all of them.
+func NewSummaryExporter(breakdowns []string, logger *zap.Logger) *SummaryExporter {
 	return &SummaryExporter{
-		logger: logger,
+		breakdowns: newBreakdownFilter(breakdowns),
+		logger:     logger,
 	}
 }
 
+// SetLocale formats the summary's dates and numbers for a locale
+func (e *SummaryExporter) SetLocale(locale *Locale) {
+	e.locale = locale
+}
+
 // Export exports a human-readable summary to stdout
 func (e *SummaryExporter) Export(result *AnalysisResult) error {
 	e.logger.Info("Exporting human-readable summary")
@@ -27,83 +37,327 @@ func (e *SummaryExporter) Export(result *AnalysisResult) error {
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("GitHub PR Analysis Summary")
 	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("\nTime Window: %s to %s\n", result.TimeWindow.Since.Format("2006-01-02"), result.TimeWindow.Until.Format("2006-01-02"))
-	fmt.Printf("Generated At: %s\n", result.GeneratedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("\nTime Window: %s to %s\n", e.locale.Date(result.TimeWindow.Since), e.locale.Date(result.TimeWindow.Until))
+	fmt.Printf("Generated At: %s\n", e.locale.DateTime(result.GeneratedAt))
 	fmt.Println()
 
 	// Total PRs
-	fmt.Printf("Total PRs Closed: %d\n", result.TotalPRsClosed)
-	fmt.Println()
+	if e.breakdowns.enabled("summary") {
+		fmt.Printf("Total PRs Closed: %s\n", e.locale.Int(result.TotalPRsClosed))
+		if result.OpenPRs > 0 {
+			fmt.Printf("Open PRs: %s\n", e.locale.Int(result.OpenPRs))
+		}
+		if len(result.AvgCommitsByTeam) > 0 {
+			fmt.Printf("Avg Commits per Merged PR: %s\n", e.locale.Float(result.AvgCommitsPerPR, 2))
+		}
+		if result.MergedWithoutApproval != nil {
+			fmt.Printf("PRs Merged Without Approval: %s\n", e.locale.Int(result.PRsMergedWithoutApproval))
+		}
+		if result.SelfMergedPRsByTeam != nil {
+			fmt.Printf("Self-Merged PRs: %s\n", e.locale.Int(result.SelfMergedPRs))
+		}
+		if result.PRsExcludedAsTrivial > 0 {
+			fmt.Printf("PRs Excluded As Trivial: %s\n", e.locale.Int(result.PRsExcludedAsTrivial))
+		}
+		if result.PRsExcludedAsMassChange > 0 {
+			fmt.Printf("PRs Excluded As Mass Change: %s\n", e.locale.Int(result.PRsExcludedAsMassChange))
+		}
+		fmt.Printf("PRs Closing Issues: %s\n", e.locale.Int(result.PRsClosingIssues))
+		if stats := result.PRsPerContributor; stats != nil {
+			fmt.Printf("PRs per Contributor (%s with %s+ PRs): mean %s, median %s, p90 %s\n", e.locale.Int(stats.Contributors), e.locale.Int(stats.MinPRs), e.locale.Float(stats.Mean, 1), e.locale.Float(stats.Median, 1), e.locale.Float(stats.P90, 1))
+		}
+		if result.ReleaseLeadTimeHoursByRepo != nil {
+			fmt.Printf("Avg Merge-to-Release Lead Time: %s hours\n", e.locale.Float(result.AvgReleaseLeadTimeHours, 1))
+			fmt.Printf("Unreleased Merged PRs: %s\n", e.locale.Int(result.UnreleasedPRs))
+		}
+		fmt.Println()
+	}
 
 	// Top repositories
-	fmt.Println("Top Repositories by PR Count:")
-	fmt.Println(strings.Repeat("-", 80))
-	type repoCount struct {
-		repo  string
-		count int
+	if e.breakdowns.enabled("repo") {
+		fmt.Println("Top Repositories by PR Count:")
+		fmt.Println(strings.Repeat("-", 80))
+		type repoCount struct {
+			repo  string
+			count int
+		}
+		var repos []repoCount
+		for repo, count := range result.PRsByRepo {
+			repos = append(repos, repoCount{repo: repo, count: count})
+		}
+		sort.Slice(repos, func(i, j int) bool {
+			return repos[i].count > repos[j].count
+		})
+		if len(repos) > 10 {
+			repos = repos[:10]
+		}
+		repoNames := make([]string, 0, len(repos))
+		for _, rc := range repos {
+			repoNames = append(repoNames, rc.repo)
+		}
+		width := nameColumnWidth(repoNames)
+		for _, rc := range repos {
+			fmt.Printf("  %-*s %5s\n", width, fitName(rc.repo, width), e.locale.Int(rc.count))
+		}
+		fmt.Println()
 	}
-	var repos []repoCount
-	for repo, count := range result.PRsByRepo {
-		repos = append(repos, repoCount{repo: repo, count: count})
+
+	// Top teams (none without attribution)
+	if e.breakdowns.enabled("team") && len(result.PRsByTeam) > 0 {
+		fmt.Println("Top Teams by PR Count:")
+		fmt.Println(strings.Repeat("-", 80))
+		type teamCount struct {
+			team  string
+			count int
+		}
+		var teams []teamCount
+		for team, count := range result.PRsByTeam {
+			teams = append(teams, teamCount{team: team, count: count})
+		}
+		sort.Slice(teams, func(i, j int) bool {
+			return teams[i].count > teams[j].count
+		})
+		if len(teams) > 10 {
+			teams = teams[:10]
+		}
+		teamNames := make([]string, 0, len(teams))
+		for _, tc := range teams {
+			teamNames = append(teamNames, tc.team)
+		}
+		width := nameColumnWidth(teamNames)
+		for _, tc := range teams {
+			fmt.Printf("  %-*s %5s\n", width, fitName(tc.team, width), e.locale.Int(tc.count))
+		}
+		fmt.Println()
 	}
-	sort.Slice(repos, func(i, j int) bool {
-		return repos[i].count > repos[j].count
-	})
-	for i, rc := range repos {
-		if i >= 10 {
-			break
+
+	// Top users
+	if e.breakdowns.enabled("user") {
+		fmt.Println("Top Users by PR Count:")
+		fmt.Println(strings.Repeat("-", 80))
+		type userCount struct {
+			user  string
+			count int
+		}
+		var users []userCount
+		for user, count := range result.PRsByUser {
+			users = append(users, userCount{user: user, count: count})
+		}
+		sort.Slice(users, func(i, j int) bool {
+			return users[i].count > users[j].count
+		})
+		if len(users) > 10 {
+			users = users[:10]
+		}
+		userNames := make([]string, 0, len(users))
+		for _, uc := range users {
+			userNames = append(userNames, uc.user)
 		}
-		fmt.Printf("  %-50s %5d\n", rc.repo, rc.count)
+		width := nameColumnWidth(userNames)
+		for _, uc := range users {
+			fmt.Printf("  %-*s %5s\n", width, fitName(uc.user, width), e.locale.Int(uc.count))
+		}
+		fmt.Println()
 	}
-	fmt.Println()
 
-	// Top teams
-	fmt.Println("Top Teams by PR Count:")
-	fmt.Println(strings.Repeat("-", 80))
-	type teamCount struct {
-		team  string
-		count int
+	// Cycle time distributions
+	if result.CycleTime != nil && e.breakdowns.enabled("cycle_time") {
+		e.printCycleTimes(result.CycleTime, result.CycleTimeByTeam)
 	}
-	var teams []teamCount
-	for team, count := range result.PRsByTeam {
-		teams = append(teams, teamCount{team: team, count: count})
+
+	// PR size histogram (only when PR details were fetched)
+	if result.PRSizeHistogram != nil && e.breakdowns.enabled("pr_sizes") {
+		fmt.Println("PR Size Histogram (lines changed):")
+		fmt.Println(strings.Repeat("-", 80))
+		buckets := sortedSizeBuckets(result.PRSizeHistogram)
+		width := nameColumnWidth(buckets)
+		for _, bucket := range buckets {
+			fmt.Printf("  %-*s %5s\n", width, fitName(bucket, width), e.locale.Int(result.PRSizeHistogram[bucket]))
+		}
+		fmt.Println()
 	}
-	sort.Slice(teams, func(i, j int) bool {
-		return teams[i].count > teams[j].count
-	})
-	for i, tc := range teams {
-		if i >= 10 {
-			break
+
+	// Weighted scores (only when scoring rules are configured)
+	if result.ScoreByTeam != nil && e.breakdowns.enabled("scores") {
+		e.printTopScores("Top Teams by PR Score:", result.ScoreByTeam)
+		e.printTopScores("Top Users by PR Score:", result.ScoreByUser)
+	}
+
+	// Custom metric (only with metrics.external_command)
+	if result.PRsByCustomMetric != nil && e.breakdowns.enabled("custom_metric") {
+		if len(result.PRsByCustomMetric) > 0 {
+			e.printTopCounts("PRs by Custom Metric:", result.PRsByCustomMetric)
+		}
+		if len(result.CustomMetricByTeam) > 0 {
+			e.printTopScores("Top Teams by Custom Metric:", result.CustomMetricByTeam)
+		}
+		if result.CustomMetricFailedPRs > 0 {
+			fmt.Printf("Custom metric failed for %s PRs; see run_report.json\n\n", e.locale.Int(result.CustomMetricFailedPRs))
 		}
-		fmt.Printf("  %-50s %5d\n", tc.team, tc.count)
 	}
+
+	// Teams waiting longest for their merges to ship (only when releases were fetched)
+	if len(result.ReleaseLeadTimeHoursByTeam) > 0 && e.breakdowns.enabled("release_lead_time") {
+		e.printTopScores("Top Teams by Avg Merge-to-Release Hours:", result.ReleaseLeadTimeHoursByTeam)
+	}
+
+	// Teams merging their own PRs most (only when PR details were fetched)
+	if len(result.SelfMergedPRsByTeam) > 0 && e.breakdowns.enabled("self_merged") {
+		e.printTopCounts("Top Teams by Self-Merged PRs:", result.SelfMergedPRsByTeam)
+	}
+
+	// Teams closing the most issues
+	if len(result.PRsClosingIssuesByTeam) > 0 && e.breakdowns.enabled("issues") {
+		e.printTopCounts("Top Teams by Issue-Closing PRs:", result.PRsClosingIssuesByTeam)
+	}
+
+	// Top contributors including co-authors (only when commits were fetched)
+	if result.PRsByContributor != nil && e.breakdowns.enabled("contributor") {
+		e.printTopCounts("Top Contributors by PR Count (authors and co-authors):", result.PRsByContributor)
+	}
+
+	// Top reviewers (only when reviews were fetched)
+	if result.ReviewsByUser != nil && e.breakdowns.enabled("reviews") {
+		e.printTopCounts("Top Reviewers by PRs Reviewed:", result.ReviewsByUser)
+	}
+
+	// Top business units (only when business units are configured)
+	if result.PRsByBusinessUnit != nil && e.breakdowns.enabled("business_unit") {
+		e.printTopCounts("Top Business Units by PR Count:", result.PRsByBusinessUnit)
+	}
+
+	// PRs by category (only when categories are configured)
+	if result.PRsByCategory != nil && e.breakdowns.enabled("category") {
+		e.printTopCounts("PRs by Category:", result.PRsByCategory)
+	}
+
+	// Teams owning the most distinct files (only with output.files_touched)
+	if result.DistinctFilesByTeam != nil && e.breakdowns.enabled("files_touched") {
+		e.printTopCounts("Top Teams by Distinct Files Touched:", result.DistinctFilesByTeam)
+	}
+
+	// Teams with the most review churn (only when review events were fetched)
+	if result.ReviewChurnByTeam != nil && e.breakdowns.enabled("review_churn") {
+		e.printTopCounts(fmt.Sprintf("Top Teams by Review Churn (%s dismissals and re-requests):", e.locale.Int(result.ReviewChurn)), result.ReviewChurnByTeam)
+	}
+
+	// PRs by author association
+	if e.breakdowns.enabled("author_association") {
+		e.printTopCounts("PRs by Author Association:", result.PRsByAuthorAssociation)
+	}
+
+	// Top companies (only when user profiles were fetched)
+	if result.PRsByCompany != nil && e.breakdowns.enabled("company") {
+		e.printTopCounts("Top Companies by PR Count:", result.PRsByCompany)
+	}
+
+	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println()
 
-	// Top users
-	fmt.Println("Top Users by PR Count:")
+	return nil
+}
+
+// printTopCounts prints the ten largest entries of a PR count breakdown
+func (e *SummaryExporter) printTopCounts(title string, counts map[string]int) {
+	fmt.Println(title)
 	fmt.Println(strings.Repeat("-", 80))
-	type userCount struct {
-		user  string
-		count int
-	}
-	var users []userCount
-	for user, count := range result.PRsByUser {
-		users = append(users, userCount{user: user, count: count})
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
 	}
-	sort.Slice(users, func(i, j int) bool {
-		return users[i].count > users[j].count
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
 	})
-	for i, uc := range users {
-		if i >= 10 {
-			break
+	if len(keys) > 10 {
+		keys = keys[:10]
+	}
+	width := nameColumnWidth(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-*s %5s\n", width, fitName(key, width), e.locale.Int(counts[key]))
+	}
+	fmt.Println()
+}
+
+// printCycleTimes prints the overall cycle time distribution and that of the
+// ten teams with the most PRs
+func (e *SummaryExporter) printCycleTimes(overall *CycleTimeStats, byTeam map[string]*CycleTimeStats) {
+	fmt.Println("Cycle Time (hours from open to merge or close):")
+	fmt.Println(strings.Repeat("-", 80))
+
+	teams := make([]string, 0, len(byTeam))
+	for team := range byTeam {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if byTeam[teams[i]].PRs != byTeam[teams[j]].PRs {
+			return byTeam[teams[i]].PRs > byTeam[teams[j]].PRs
 		}
-		fmt.Printf("  %-50s %5d\n", uc.user, uc.count)
+		return teams[i] < teams[j]
+	})
+	if len(teams) > 10 {
+		teams = teams[:10]
+	}
+
+	width := nameColumnWidth(teams)
+	fmt.Printf("  %-*s %5s %8s %8s %8s %8s\n", width, "", "PRs", "Mean", "Median", "P90", "P95")
+	row := func(name string, stats *CycleTimeStats) {
+		fmt.Printf("  %-*s %5s %8s %8s %8s %8s\n", width, fitName(name, width), e.locale.Int(stats.PRs),
+			e.locale.Float(stats.Mean, 1), e.locale.Float(stats.Median, 1), e.locale.Float(stats.P90, 1), e.locale.Float(stats.P95, 1))
+	}
+	row("All PRs", overall)
+	for _, team := range teams {
+		row(team, byTeam[team])
 	}
 	fmt.Println()
+}
 
-	fmt.Println(strings.Repeat("=", 80))
+// printTopScores prints the ten largest entries of a PR score breakdown
+func (e *SummaryExporter) printTopScores(title string, scores map[string]float64) {
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("-", 80))
+
+	keys := sortedScoreKeys(scores)
+	if len(keys) > 10 {
+		keys = keys[:10]
+	}
+	width := nameColumnWidth(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-*s %8s\n", width, fitName(key, width), e.locale.Float(scores[key], 2))
+	}
 	fmt.Println()
+}
 
-	return nil
+// Bounds of the name column of the summary's tables. The column grows to fit
+// the longest name shown (e.g. long owner/repo names of multi-org runs) but
+// never past summaryMaxNameWidth, beyond which names are cut with an ellipsis.
+const (
+	summaryMinNameWidth = 50
+	summaryMaxNameWidth = 100
+)
+
+// nameColumnWidth returns the width of a table's name column fitting all names
+func nameColumnWidth(names []string) int {
+	width := summaryMinNameWidth
+	for _, name := range names {
+		if n := utf8.RuneCountInString(name); n > width {
+			width = n
+		}
+	}
+	if width > summaryMaxNameWidth {
+		width = summaryMaxNameWidth
+	}
+	return width
 }
 
+// fitName cuts a name longer than width, marking the cut with an ellipsis
+func fitName(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width {
+		return name
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -2,7 +2,9 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -12,11 +14,22 @@ import (
 	"go.uber.org/zap"
 )
 
+// githubCODEOWNERSMaxBytes is GitHub's documented CODEOWNERS size limit; GitHub
+// does not load a larger file at all, so none of its rules request reviews
+const githubCODEOWNERSMaxBytes = 3 * 1024 * 1024
+
+// ErrCODEOWNERSTooLarge is returned for a CODEOWNERS file over the size set
+// with SetMaxBytes. Callers treat the repository as having none, but must not
+// remember it as such: under a raised limit the file is used.
+var ErrCODEOWNERSTooLarge = errors.New("CODEOWNERS file exceeds codeowners.max_bytes")
+
 // CODEOWNERSFetcher fetches CODEOWNERS files from repositories
 type CODEOWNERSFetcher struct {
-	client   *github.Client
-	ghClient *ghclient.Client
-	logger   *zap.Logger
+	client              *github.Client
+	ghClient            *ghclient.Client
+	enforceGitHubLimits bool
+	maxBytes            int // Largest file parsed; 0 means no limit
+	logger              *zap.Logger
 }
 
 // NewCODEOWNERSFetcher creates a new CODEOWNERS fetcher
@@ -28,10 +41,24 @@ func NewCODEOWNERSFetcher(client *github.Client, ghClient *ghclient.Client, logg
 	}
 }
 
+// SetEnforceGitHubLimits makes parsing drop the rules GitHub ignores because
+// the file exceeds its limits, so attribution matches what GitHub shows reviewers
+func (c *CODEOWNERSFetcher) SetEnforceGitHubLimits(enabled bool) {
+	c.enforceGitHubLimits = enabled
+}
+
+// SetMaxBytes sets the size of the largest CODEOWNERS file that is parsed;
+// fetching a larger file fails with ErrCODEOWNERSTooLarge. 0 means no limit.
+func (c *CODEOWNERSFetcher) SetMaxBytes(maxBytes int) {
+	c.maxBytes = maxBytes
+}
+
 // CODEOWNERSFile represents a parsed CODEOWNERS file
 type CODEOWNERSFile struct {
-	Rules []CODEOWNERSRule
-	Path  string
+	Rules        []CODEOWNERSRule
+	Path         string
+	InvalidLines []int // Line numbers of rules that were skipped for having no owners
+	DroppedRules int   // Rules dropped because the file exceeds GitHub's limits (codeowners.enforce_github_limits)
 }
 
 // CODEOWNERSRule represents a single CODEOWNERS rule
@@ -45,6 +72,13 @@ type CODEOWNERSRule struct {
 // It checks both repo root and .github/ directory
 // Returns both the parsed file and raw content for caching
 func (c *CODEOWNERSFetcher) FetchCODEOWNERS(ctx context.Context, owner, repo string) (*CODEOWNERSFile, []byte, error) {
+	return c.FetchCODEOWNERSAtRef(ctx, owner, repo, "")
+}
+
+// FetchCODEOWNERSAtRef fetches and parses the CODEOWNERS file as of a git ref
+// (branch, tag or commit SHA); an empty ref means the default branch. A file
+// over the size limit fails with ErrCODEOWNERSTooLarge.
+func (c *CODEOWNERSFetcher) FetchCODEOWNERSAtRef(ctx context.Context, owner, repo, ref string) (*CODEOWNERSFile, []byte, error) {
 	// Try common CODEOWNERS locations
 	paths := []string{
 		"CODEOWNERS",
@@ -53,7 +87,15 @@ func (c *CODEOWNERSFetcher) FetchCODEOWNERS(ctx context.Context, owner, repo str
 	}
 
 	for _, path := range paths {
-		content, err := c.fetchFileContent(ctx, owner, repo, path)
+		content, err := c.fetchFileContent(ctx, owner, repo, path, ref)
+		if errors.Is(err, ErrCODEOWNERSTooLarge) {
+			c.logger.Warn("Ignoring oversized CODEOWNERS file, treating repository as having none",
+				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+				zap.String("path", path),
+				zap.Int("max_bytes", c.maxBytes),
+			)
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
 		if err != nil {
 			// File not found, try next location
 			if strings.Contains(err.Error(), "404") {
@@ -70,6 +112,7 @@ func (c *CODEOWNERSFetcher) FetchCODEOWNERS(ctx context.Context, owner, repo str
 			c.logger.Debug("Found CODEOWNERS file",
 				zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
 				zap.String("path", path),
+				zap.String("ref", ref),
 				zap.Int("rules", len(parsed.Rules)),
 			)
 			return parsed, content, nil
@@ -84,8 +127,8 @@ func (c *CODEOWNERSFetcher) FetchCODEOWNERS(ctx context.Context, owner, repo str
 }
 
 // fetchFileContent fetches file content from GitHub
-func (c *CODEOWNERSFetcher) fetchFileContent(ctx context.Context, owner, repo, path string) ([]byte, error) {
-	fileContent, _, resp, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{})
+func (c *CODEOWNERSFetcher) fetchFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	fileContent, _, resp, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
 	if err != nil {
 		return nil, err
 	}
@@ -99,16 +142,36 @@ func (c *CODEOWNERSFetcher) fetchFileContent(ctx context.Context, owner, repo, p
 
 	// Check if it's a file (not a directory)
 	if resp.StatusCode == 200 && fileContent != nil {
+		// Refuse oversized files before decoding them
+		if c.maxBytes > 0 && fileContent.GetSize() > c.maxBytes {
+			return nil, ErrCODEOWNERSTooLarge
+		}
 		content, err := fileContent.GetContent()
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode file content: %w", err)
 		}
+		if c.maxBytes > 0 && len(content) > c.maxBytes {
+			return nil, ErrCODEOWNERSTooLarge
+		}
 		return []byte(content), nil
 	}
 
 	return nil, fmt.Errorf("file not found or is a directory")
 }
 
+// LoadCODEOWNERSFile reads and parses a CODEOWNERS file from the local
+// filesystem, e.g. from a cloned repository, instead of the API
+func (c *CODEOWNERSFetcher) LoadCODEOWNERSFile(path string) (*CODEOWNERSFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS file: %w", err)
+	}
+	if c.maxBytes > 0 && len(content) > c.maxBytes {
+		return nil, fmt.Errorf("%s: %w", path, ErrCODEOWNERSTooLarge)
+	}
+	return c.parseCODEOWNERS(content, path)
+}
+
 // ParseCODEOWNERS parses CODEOWNERS file content (public method for cache)
 func (c *CODEOWNERSFetcher) ParseCODEOWNERS(content []byte, path string) (*CODEOWNERSFile, error) {
 	return c.parseCODEOWNERS(content, path)
@@ -135,6 +198,7 @@ func (c *CODEOWNERSFetcher) parseCODEOWNERS(content []byte, path string) (*CODEO
 		parts := strings.Fields(line)
 		if len(parts) < 2 {
 			// Invalid line, skip
+			file.InvalidLines = append(file.InvalidLines, lineNum)
 			continue
 		}
 
@@ -153,12 +217,34 @@ func (c *CODEOWNERSFetcher) parseCODEOWNERS(content []byte, path string) (*CODEO
 		})
 	}
 
+	// GitHub ignores a CODEOWNERS file over its size limit entirely
+	if c.enforceGitHubLimits && len(content) > githubCODEOWNERSMaxBytes {
+		c.logger.Warn("CODEOWNERS exceeds GitHub's size limit, dropping all of its rules",
+			zap.String("path", path),
+			zap.Int("bytes", len(content)),
+			zap.Int("max_bytes", githubCODEOWNERSMaxBytes),
+			zap.Int("dropped_rules", len(file.Rules)),
+		)
+		file.DroppedRules = len(file.Rules)
+		file.Rules = []CODEOWNERSRule{}
+	}
+
 	return file, nil
 }
 
 // FindOwners finds owners for a given file path using CODEOWNERS rules
 // Returns owners in order of specificity (most specific first)
 func (file *CODEOWNERSFile) FindOwners(filePath string) []string {
+	rule := file.FindRule(filePath)
+	if rule == nil {
+		return nil
+	}
+	return rule.Owners
+}
+
+// FindRule finds the most specific CODEOWNERS rule matching a file path,
+// or nil if no rule matches
+func (file *CODEOWNERSFile) FindRule(filePath string) *CODEOWNERSRule {
 	if file == nil || len(file.Rules) == 0 {
 		return nil
 	}
@@ -170,20 +256,21 @@ func (file *CODEOWNERSFile) FindOwners(filePath string) []string {
 	filePath = filepath.Clean(filePath)
 
 	var matches []struct {
-		owners      []string
+		rule        *CODEOWNERSRule
 		specificity int
 	}
 
 	// Find all matching rules
-	for _, rule := range file.Rules {
+	for i := range file.Rules {
+		rule := &file.Rules[i]
 		if matchesPattern(rule.Pattern, filePath) {
 			// Calculate specificity (longer pattern = more specific)
 			specificity := len(rule.Pattern)
 			matches = append(matches, struct {
-				owners      []string
+				rule        *CODEOWNERSRule
 				specificity int
 			}{
-				owners:      rule.Owners,
+				rule:        rule,
 				specificity: specificity,
 			})
 		}
@@ -203,8 +290,8 @@ func (file *CODEOWNERSFile) FindOwners(filePath string) []string {
 		}
 	}
 
-	// Return owners from most specific match
-	return matches[0].owners
+	// Return the most specific match
+	return matches[0].rule
 }
 
 // matchesPattern checks if a file path matches a CODEOWNERS pattern
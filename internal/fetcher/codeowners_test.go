@@ -1,7 +1,20 @@
 package fetcher
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
 )
 
 func TestParseCODEOWNERS(t *testing.T) {
@@ -72,3 +85,120 @@ func TestMatchesPattern(t *testing.T) {
 	}
 }
 
+func TestParseCODEOWNERSEnforcesGitHubSizeLimit(t *testing.T) {
+	// Pad with comments so the file exceeds the limit without changing its rules
+	content := []byte("* @team1\n/docs/ @team2\n" + strings.Repeat("# padding\n", githubCODEOWNERSMaxBytes/10+1))
+
+	fetcher := NewCODEOWNERSFetcher(nil, nil, zap.NewNop())
+	file, err := fetcher.ParseCODEOWNERS(content, "CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Failed to parse CODEOWNERS: %v", err)
+	}
+	if len(file.Rules) != 2 || file.DroppedRules != 0 {
+		t.Fatalf("Without enforcement expected 2 rules and none dropped, got %d and %d", len(file.Rules), file.DroppedRules)
+	}
+
+	fetcher.SetEnforceGitHubLimits(true)
+	file, err = fetcher.ParseCODEOWNERS(content, "CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Failed to parse CODEOWNERS: %v", err)
+	}
+	if len(file.Rules) != 0 || file.DroppedRules != 2 {
+		t.Errorf("With enforcement expected no rules and 2 dropped, got %d and %d", len(file.Rules), file.DroppedRules)
+	}
+	if owners := file.FindOwners("docs/README.md"); owners != nil {
+		t.Errorf("Expected no owners for an oversized file, got %v", owners)
+	}
+
+	// A file within the limit is unaffected
+	file, err = fetcher.ParseCODEOWNERS([]byte("* @team1\n"), "CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Failed to parse CODEOWNERS: %v", err)
+	}
+	if len(file.Rules) != 1 || file.DroppedRules != 0 {
+		t.Errorf("Expected 1 rule and none dropped, got %d and %d", len(file.Rules), file.DroppedRules)
+	}
+}
+
+func TestFetchCODEOWNERSRejectsOversizedFile(t *testing.T) {
+	content := "* @team1\n" + strings.Repeat("# padding\n", 100)
+	tests := []struct {
+		name      string
+		size      int // Size reported by the API
+		maxBytes  int
+		wantRules int
+	}{
+		{"within cap", len(content), 4096, 1},
+		{"reported size over cap", len(content), 64, 0},
+		{"decoded content over cap", 0, 64, 0},
+		{"no cap", len(content), 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "size": %d, "content": %q}`,
+					tt.size, base64.StdEncoding.EncodeToString([]byte(content)))
+			}))
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			baseURL, err := url.Parse(server.URL + "/")
+			if err != nil {
+				t.Fatalf("failed to parse server URL: %v", err)
+			}
+			client.BaseURL = baseURL
+
+			fetcher := NewCODEOWNERSFetcher(client, nil, zap.NewNop())
+			fetcher.SetMaxBytes(tt.maxBytes)
+			file, raw, err := fetcher.FetchCODEOWNERS(context.Background(), "org", "repo")
+
+			if tt.wantRules == 0 {
+				// Reported apart from absence, so it is not cached as absent
+				if !errors.Is(err, ErrCODEOWNERSTooLarge) || file != nil || raw != nil {
+					t.Errorf("FetchCODEOWNERS() = %+v, %v, want ErrCODEOWNERSTooLarge", file, err)
+				}
+				// The other CODEOWNERS locations are not tried
+				if len(paths) != 1 {
+					t.Errorf("expected 1 request, got %v", paths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchCODEOWNERS() error = %v", err)
+			}
+			if file == nil || len(file.Rules) != tt.wantRules {
+				t.Fatalf("expected %d rules, got %+v", tt.wantRules, file)
+			}
+		})
+	}
+}
+
+func TestLoadCODEOWNERSFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CODEOWNERS")
+	if err := os.WriteFile(path, []byte("* @team1\n/docs/ @team2\n"), 0644); err != nil {
+		t.Fatalf("failed to write CODEOWNERS: %v", err)
+	}
+
+	fetcher := NewCODEOWNERSFetcher(nil, nil, zap.NewNop())
+	file, err := fetcher.LoadCODEOWNERSFile(path)
+	if err != nil {
+		t.Fatalf("LoadCODEOWNERSFile() error = %v", err)
+	}
+	if file.Path != path {
+		t.Errorf("expected path %q, got %q", path, file.Path)
+	}
+	if owners := file.FindOwners("docs/README.md"); len(owners) != 1 || owners[0] != "@team2" {
+		t.Errorf("expected [@team2], got %v", owners)
+	}
+
+	fetcher.SetMaxBytes(8)
+	if _, err := fetcher.LoadCODEOWNERSFile(path); err == nil {
+		t.Error("expected an error for a file over codeowners.max_bytes")
+	}
+	if _, err := fetcher.LoadCODEOWNERSFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -15,6 +15,7 @@ type PRFetcher struct {
 	client   *github.Client
 	ghClient *ghclient.Client
 	logger   *zap.Logger
+	state    string
 }
 
 // NewPRFetcher creates a new PR fetcher
@@ -23,37 +24,77 @@ func NewPRFetcher(client *github.Client, ghClient *ghclient.Client, logger *zap.
 		client:   client,
 		ghClient: ghClient,
 		logger:   logger,
+		state:    "closed",
 	}
 }
 
-// FetchClosedPRs fetches closed pull requests for a repository within a time window
+// SetPRState sets which PRs count as closed: "closed" (merged or not) or
+// "merged"; "all" counts the closed ones, to which callers add FetchOpenPRs
+func (p *PRFetcher) SetPRState(state string) {
+	p.state = state
+}
+
+// FetchClosedPRs fetches closed pull requests for a repository within a time
+// window. The window is half-open: PRs closed at since count, PRs closed at until do not.
+// Which PRs count as closed follows the fetcher's PR state.
 func (p *PRFetcher) FetchClosedPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	return p.FetchClosedPRsFromPage(ctx, owner, repo, since, until, 1, nil)
+}
+
+// FetchClosedPRsFromPage fetches closed pull requests within a time window, starting
+// at the given list page. onPage, if set, is called with the in-window PRs of each
+// fully fetched page. When a page fails, the PRs fetched before it are returned
+// along with the error so callers can keep them.
+func (p *PRFetcher) FetchClosedPRsFromPage(ctx context.Context, owner, repo string, since, until time.Time, startPage int, onPage func(page int, prs []*github.PullRequest)) ([]*github.PullRequest, error) {
+	return p.fetchClosedPRs(ctx, owner, repo, since, until, time.Time{}, startPage, onPage)
+}
+
+// FetchClosedPRsUpdatedSince fetches the closed pull requests within a time
+// window that were updated at or after updatedSince. The list is ordered by
+// update time, so it stops at the first PR updated before then.
+func (p *PRFetcher) FetchClosedPRsUpdatedSince(ctx context.Context, owner, repo string, since, until, updatedSince time.Time, onPage func(page int, prs []*github.PullRequest)) ([]*github.PullRequest, error) {
+	return p.fetchClosedPRs(ctx, owner, repo, since, until, updatedSince, 1, onPage)
+}
+
+// fetchClosedPRs lists closed pull requests from startPage, stopping at PRs
+// closed before since or, if updatedSince is set, updated before it
+func (p *PRFetcher) fetchClosedPRs(ctx context.Context, owner, repo string, since, until, updatedSince time.Time, startPage int, onPage func(page int, prs []*github.PullRequest)) ([]*github.PullRequest, error) {
 	p.logger.Debug("Fetching closed PRs",
 		zap.String("owner", owner),
 		zap.String("repo", repo),
+		zap.String("state", p.state),
 		zap.Time("since", since),
 		zap.Time("until", until),
+		zap.Time("updated_since", updatedSince),
+		zap.Int("start_page", startPage),
 	)
 
 	var allPRs []*github.PullRequest
 	var lastResp *github.Response
+	// Merged PRs are a subset of the closed ones; the list endpoint has no merged state
 	opts := &github.PullRequestListOptions{
 		State:       "closed",
 		Sort:        "updated",
 		Direction:   "desc",
-		ListOptions: github.ListOptions{PerPage: 100},
+		ListOptions: github.ListOptions{Page: startPage, PerPage: 100},
 	}
 
 	for {
 		prs, resp, err := p.client.PullRequests.List(ctx, owner, repo, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, repo, err)
+			return allPRs, fmt.Errorf("failed to list pull requests for %s/%s (page %d): %w", owner, repo, opts.Page, err)
 		}
 
 		lastResp = resp
 
 		// Filter PRs by closed date within the time window
+		var pagePRs []*github.PullRequest
+		done := false
 		for _, pr := range prs {
+			if !updatedSince.IsZero() && pr.UpdatedAt != nil && pr.UpdatedAt.Time.Before(updatedSince) {
+				done = true
+				break
+			}
 			if pr.ClosedAt == nil {
 				continue
 			}
@@ -64,12 +105,21 @@ func (p *PRFetcher) FetchClosedPRs(ctx context.Context, owner, repo string, sinc
 				break
 			}
 
-			if closedAt.After(until) {
+			if !closedAt.Before(until) {
+				continue
+			}
+
+			if p.state == "merged" && pr.MergedAt == nil {
 				continue
 			}
 
 			// PR is within the time window
-			allPRs = append(allPRs, pr)
+			pagePRs = append(pagePRs, pr)
+		}
+		allPRs = append(allPRs, pagePRs...)
+
+		if onPage != nil {
+			onPage(opts.Page, pagePRs)
 		}
 
 		p.logger.Debug("Fetched PRs page",
@@ -82,18 +132,19 @@ func (p *PRFetcher) FetchClosedPRs(ctx context.Context, owner, repo string, sinc
 		// Check rate limit and sleep if threshold is reached
 		if p.ghClient != nil && resp != nil {
 			if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
-				return nil, fmt.Errorf("rate limit check failed: %w", err)
+				return allPRs, fmt.Errorf("rate limit check failed: %w", err)
 			}
 		}
 
-		if resp.NextPage == 0 {
+		if done || resp.NextPage == 0 {
 			break
 		}
 		opts.Page = resp.NextPage
 
 		// If we've gone past the since date, we can stop
-		if len(prs) > 0 && prs[len(prs)-1].ClosedAt != nil {
-			if prs[len(prs)-1].ClosedAt.Time.Before(since) {
+		if len(prs) > 0 {
+			last := prs[len(prs)-1]
+			if last.ClosedAt != nil && last.ClosedAt.Time.Before(since) {
 				break
 			}
 		}
@@ -118,6 +169,56 @@ func (p *PRFetcher) FetchClosedPRs(ctx context.Context, owner, repo string, sinc
 	return allPRs, nil
 }
 
+// FetchOpenPRs fetches the pull requests still open that were updated within
+// the half-open time window [since, until), for fetch.pr_state all
+func (p *PRFetcher) FetchOpenPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	var openPRs []*github.PullRequest
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		prs, resp, err := p.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open pull requests for %s/%s (page %d): %w", owner, repo, opts.Page, err)
+		}
+
+		done := false
+		for _, pr := range prs {
+			if pr.UpdatedAt == nil || !pr.UpdatedAt.Time.Before(until) {
+				continue
+			}
+			// Sorted by update time, newest first, so the rest are older still
+			if pr.UpdatedAt.Time.Before(since) {
+				done = true
+				break
+			}
+			openPRs = append(openPRs, pr)
+		}
+
+		// Check rate limit and sleep if threshold is reached
+		if p.ghClient != nil && resp != nil {
+			if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+				return nil, fmt.Errorf("rate limit check failed: %w", err)
+			}
+		}
+
+		if done || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	p.logger.Debug("Fetched open PRs",
+		zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		zap.Int("count", len(openPRs)),
+	)
+	return openPRs, nil
+}
+
 // FetchPRFiles fetches the list of files changed in a pull request
 func (p *PRFetcher) FetchPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]*github.CommitFile, error) {
 	var allFiles []*github.CommitFile
@@ -146,3 +247,121 @@ func (p *PRFetcher) FetchPRFiles(ctx context.Context, owner, repo string, prNumb
 
 	return allFiles, nil
 }
+
+// FetchPRReviews fetches the reviews submitted on a pull request
+func (p *PRFetcher) FetchPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	var allReviews []*github.PullRequestReview
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		reviews, resp, err := p.client.PullRequests.ListReviews(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviews for PR #%d: %w", prNumber, err)
+		}
+
+		allReviews = append(allReviews, reviews...)
+
+		// Check rate limit and sleep if threshold is reached
+		if p.ghClient != nil && resp != nil {
+			if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+				return nil, fmt.Errorf("rate limit check failed: %w", err)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allReviews, nil
+}
+
+// reviewEventTypes are the timeline events that make up a PR's review flow
+var reviewEventTypes = map[string]bool{
+	"reviewed":               true,
+	"review_requested":       true,
+	"review_request_removed": true,
+	"review_dismissed":       true,
+}
+
+// FetchPRReviewEvents fetches the review events of a pull request's timeline
+// (reviews, review requests and dismissals), in chronological order
+func (p *PRFetcher) FetchPRReviewEvents(ctx context.Context, owner, repo string, prNumber int) ([]*github.Timeline, error) {
+	allEvents := []*github.Timeline{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		events, resp, err := p.client.Issues.ListIssueTimeline(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list timeline for PR #%d: %w", prNumber, err)
+		}
+
+		for _, event := range events {
+			if reviewEventTypes[event.GetEvent()] {
+				allEvents = append(allEvents, event)
+			}
+		}
+
+		// Check rate limit and sleep if threshold is reached
+		if p.ghClient != nil && resp != nil {
+			if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+				return nil, fmt.Errorf("rate limit check failed: %w", err)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allEvents, nil
+}
+
+// FetchPRDetails fetches a single pull request, which includes fields the list
+// endpoint leaves empty (commits, additions, deletions, changed files, merged by)
+func (p *PRFetcher) FetchPRDetails(ctx context.Context, owner, repo string, prNumber int) (*github.PullRequest, error) {
+	pr, resp, err := p.client.PullRequests.Get(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+	}
+
+	// Check rate limit and sleep if threshold is reached
+	if p.ghClient != nil && resp != nil {
+		if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+			return nil, fmt.Errorf("rate limit check failed: %w", err)
+		}
+	}
+
+	return pr, nil
+}
+
+// FetchPRCommits fetches the commits of a pull request
+func (p *PRFetcher) FetchPRCommits(ctx context.Context, owner, repo string, prNumber int) ([]*github.RepositoryCommit, error) {
+	var allCommits []*github.RepositoryCommit
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		commits, resp, err := p.client.PullRequests.ListCommits(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits for PR #%d: %w", prNumber, err)
+		}
+
+		allCommits = append(allCommits, commits...)
+
+		// Check rate limit and sleep if threshold is reached
+		if p.ghClient != nil && resp != nil {
+			if err := p.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+				return nil, fmt.Errorf("rate limit check failed: %w", err)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allCommits, nil
+}
@@ -0,0 +1,122 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+func TestFetchClosedPRsFollowsPRState(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Sorted by update time, newest first, like the list endpoint returns them
+	page := `[
+		{"number": 1, "state": "open", "updated_at": "2024-01-20T00:00:00Z"},
+		{"number": 2, "state": "closed", "updated_at": "2024-01-15T00:00:00Z", "closed_at": "2024-01-15T00:00:00Z", "merged_at": "2024-01-15T00:00:00Z"},
+		{"number": 3, "state": "closed", "updated_at": "2024-01-10T00:00:00Z", "closed_at": "2024-01-10T00:00:00Z"},
+		{"number": 4, "state": "open", "updated_at": "2023-12-20T00:00:00Z"}
+	]`
+
+	tests := []struct {
+		state     string
+		wantState string // State requested from the list endpoint
+		want      []int
+	}{
+		{"closed", "closed", []int{2, 3}},
+		{"merged", "closed", []int{2}},
+		{"all", "closed", []int{2, 3}}, // Open PRs are fetched by FetchOpenPRs
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			var gotState string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotState = r.URL.Query().Get("state")
+				fmt.Fprint(w, page)
+			}))
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			baseURL, err := url.Parse(server.URL + "/")
+			if err != nil {
+				t.Fatalf("failed to parse server URL: %v", err)
+			}
+			client.BaseURL = baseURL
+
+			fetcher := NewPRFetcher(client, nil, zap.NewNop())
+			fetcher.SetPRState(tt.state)
+			prs, err := fetcher.FetchClosedPRs(context.Background(), "org", "repo", since, until)
+			if err != nil {
+				t.Fatalf("FetchClosedPRs() error = %v", err)
+			}
+
+			if gotState != tt.wantState {
+				t.Errorf("expected list state %q, got %q", tt.wantState, gotState)
+			}
+			var got []int
+			for _, pr := range prs {
+				got = append(got, pr.GetNumber())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected PRs %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchOpenPRs(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	var gotState string
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		gotState = r.URL.Query().Get("state")
+		// Sorted by update time, newest first; #4 ends the listing, so the
+		// next page is never requested
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path))
+		fmt.Fprint(w, `[
+			{"number": 1, "state": "open", "updated_at": "2024-02-10T00:00:00Z"},
+			{"number": 2, "state": "open", "updated_at": "2024-01-20T00:00:00Z"},
+			{"number": 3, "state": "open", "updated_at": "2024-01-01T00:00:00Z"},
+			{"number": 4, "state": "open", "updated_at": "2023-12-20T00:00:00Z"}
+		]`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	prs, err := NewPRFetcher(client, nil, zap.NewNop()).FetchOpenPRs(context.Background(), "org", "repo", since, until)
+	if err != nil {
+		t.Fatalf("FetchOpenPRs() error = %v", err)
+	}
+
+	if gotState != "open" {
+		t.Errorf("expected list state open, got %q", gotState)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+	var got []int
+	for _, pr := range prs {
+		got = append(got, pr.GetNumber())
+	}
+	if want := []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected PRs %v, got %v", want, got)
+	}
+}
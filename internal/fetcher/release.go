@@ -0,0 +1,59 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fishnix/ghpr-analyzer/internal/ghclient"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// ReleaseFetcher fetches the releases of a repository
+type ReleaseFetcher struct {
+	client   *github.Client
+	ghClient *ghclient.Client
+	logger   *zap.Logger
+}
+
+// NewReleaseFetcher creates a new release fetcher
+func NewReleaseFetcher(client *github.Client, ghClient *ghclient.Client, logger *zap.Logger) *ReleaseFetcher {
+	return &ReleaseFetcher{
+		client:   client,
+		ghClient: ghClient,
+		logger:   logger,
+	}
+}
+
+// FetchReleases fetches all releases of a repository, including drafts and prereleases
+func (r *ReleaseFetcher) FetchReleases(ctx context.Context, owner, repo string) ([]*github.RepositoryRelease, error) {
+	var allReleases []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		releases, resp, err := r.client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases for %s/%s: %w", owner, repo, err)
+		}
+
+		allReleases = append(allReleases, releases...)
+
+		// Check rate limit and sleep if threshold is reached
+		if r.ghClient != nil && resp != nil {
+			if err := r.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+				return nil, fmt.Errorf("rate limit check failed: %w", err)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	r.logger.Debug("Fetched releases",
+		zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		zap.Int("releases", len(allReleases)),
+	)
+	return allReleases, nil
+}
@@ -9,6 +9,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// repoListType is the repository type listed for the organization
+const repoListType = "all"
+
 // RepoEnumerator enumerates repositories in a GitHub organization
 type RepoEnumerator struct {
 	client   *github.Client
@@ -27,6 +30,13 @@ func NewRepoEnumerator(client *github.Client, ghClient *ghclient.Client, org str
 	}
 }
 
+// FilterSignature identifies the filters applied when enumerating, so lists
+// enumerated with different filters are cached separately. It is safe for use
+// in file names.
+func (r *RepoEnumerator) FilterSignature() string {
+	return "type-" + repoListType
+}
+
 // EnumerateRepos lists all repositories in the organization
 func (r *RepoEnumerator) EnumerateRepos(ctx context.Context) ([]*github.Repository, error) {
 	r.logger.Info("Enumerating repositories", zap.String("org", r.org))
@@ -34,7 +44,7 @@ func (r *RepoEnumerator) EnumerateRepos(ctx context.Context) ([]*github.Reposito
 	var allRepos []*github.Repository
 	var lastResp *github.Response
 	opts := &github.RepositoryListByOrgOptions{
-		Type:        "all",
+		Type:        repoListType,
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
@@ -83,3 +93,23 @@ func (r *RepoEnumerator) EnumerateRepos(ctx context.Context) ([]*github.Reposito
 
 	return allRepos, nil
 }
+
+// FetchRepo fetches a single repository, for analyzing it without enumerating
+// the organization
+func (r *RepoEnumerator) FetchRepo(ctx context.Context, owner, name string) (*github.Repository, error) {
+	r.logger.Info("Fetching repository", zap.String("repo", fmt.Sprintf("%s/%s", owner, name)))
+
+	repo, resp, err := r.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	// Check rate limit and sleep if threshold is reached
+	if r.ghClient != nil && resp != nil {
+		if err := r.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+			return nil, fmt.Errorf("rate limit check failed: %w", err)
+		}
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,192 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// searchResultCap is the maximum number of results the Search API returns for a query
+const searchResultCap = 1000
+
+// ErrSearchCapExceeded is returned when a search matches more PRs than the
+// Search API can return; callers should fall back to listing PRs
+var ErrSearchCapExceeded = errors.New("search matched more results than the search API returns")
+
+// SearchClosedPRs fetches closed pull requests for a repository within a time window
+// using the Search API, which filters by state and closed date server-side.
+// Search results are issues, so only the fields issues share with PRs (plus the
+// merge time) are populated; fetch PR details for the rest.
+func (p *PRFetcher) SearchClosedPRs(ctx context.Context, owner, repo string, since, until time.Time) ([]*github.PullRequest, error) {
+	// Open PRs cannot be matched by close date, so config limits search to the
+	// closed and merged states
+	state := "closed"
+	if p.state == "merged" {
+		state = "merged"
+	}
+	query := fmt.Sprintf("repo:%s/%s is:pr is:%s closed:%s..%s",
+		owner, repo, state,
+		since.UTC().Format(time.RFC3339),
+		until.UTC().Format(time.RFC3339),
+	)
+
+	p.logger.Debug("Searching closed PRs",
+		zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		zap.String("query", query),
+	)
+
+	var allPRs []*github.PullRequest
+	opts := &github.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		result, resp, err := p.client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search pull requests for %s/%s: %w", owner, repo, err)
+		}
+
+		if result.GetTotal() > searchResultCap || result.GetIncompleteResults() {
+			return nil, ErrSearchCapExceeded
+		}
+
+		// The closed: range is inclusive at both ends; the window excludes until
+		for _, issue := range result.Issues {
+			if issue.ClosedAt != nil && !issue.ClosedAt.Time.Before(until) {
+				continue
+			}
+			allPRs = append(allPRs, issueToPR(issue))
+		}
+
+		// The search rate limit is separate from (and much lower than) the core
+		// limit, so wait out its reset instead of applying the core threshold
+		if resp.Rate.Remaining == 0 {
+			if err := waitForReset(ctx, resp.Rate.Reset.Time); err != nil {
+				return nil, err
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	p.logger.Info("PR search complete",
+		zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		zap.Int("total_prs", len(allPRs)),
+	)
+
+	return allPRs, nil
+}
+
+// SearchClosedPRsSplit searches closed PRs like SearchClosedPRs, but splits the
+// window into splits equal sub-windows searched concurrently, at most workers
+// at a time, so a long history is fetched in parallel. The results are merged
+// and deduplicated by PR number.
+func (p *PRFetcher) SearchClosedPRsSplit(ctx context.Context, owner, repo string, since, until time.Time, splits, workers int) ([]*github.PullRequest, error) {
+	windows := splitWindow(since, until, splits)
+	if len(windows) == 1 {
+		return p.SearchClosedPRs(ctx, owner, repo, since, until)
+	}
+
+	results := make([][]*github.PullRequest, len(windows))
+	errs := make([]error, len(windows))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(workers, 1))
+	for i, window := range windows {
+		wg.Add(1)
+		sem <- struct{}{} // Acquire semaphore
+		go func(i int, since, until time.Time) {
+			defer wg.Done()
+			defer func() { <-sem }() // Release semaphore
+			results[i], errs[i] = p.SearchClosedPRs(ctx, owner, repo, since, until)
+		}(i, window[0], window[1])
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var allPRs []*github.PullRequest
+	for _, prs := range results {
+		for _, pr := range prs {
+			if seen[pr.GetNumber()] {
+				continue
+			}
+			seen[pr.GetNumber()] = true
+			allPRs = append(allPRs, pr)
+		}
+	}
+
+	p.logger.Info("Split PR search complete",
+		zap.String("repo", fmt.Sprintf("%s/%s", owner, repo)),
+		zap.Int("windows", len(windows)),
+		zap.Int("total_prs", len(allPRs)),
+	)
+
+	return allPRs, nil
+}
+
+// splitWindow splits [since, until) into n contiguous sub-windows of equal
+// length (to the second, the precision of search dates); the last one ends at until
+func splitWindow(since, until time.Time, n int) [][2]time.Time {
+	step := until.Sub(since) / time.Duration(max(n, 1)) / time.Second * time.Second
+	if n <= 1 || step <= 0 {
+		return [][2]time.Time{{since, until}}
+	}
+
+	windows := make([][2]time.Time, 0, n)
+	start := since
+	for i := 0; i < n-1; i++ {
+		end := start.Add(step)
+		windows = append(windows, [2]time.Time{start, end})
+		start = end
+	}
+	return append(windows, [2]time.Time{start, until})
+}
+
+// issueToPR converts a PR search result to a pull request
+func issueToPR(issue *github.Issue) *github.PullRequest {
+	pr := &github.PullRequest{
+		Number:            issue.Number,
+		State:             issue.State,
+		Title:             issue.Title,
+		Body:              issue.Body,
+		User:              issue.User,
+		Labels:            issue.Labels,
+		CreatedAt:         issue.CreatedAt,
+		UpdatedAt:         issue.UpdatedAt,
+		ClosedAt:          issue.ClosedAt,
+		AuthorAssociation: issue.AuthorAssociation,
+		HTMLURL:           issue.HTMLURL,
+	}
+	if issue.PullRequestLinks != nil {
+		pr.MergedAt = issue.PullRequestLinks.MergedAt
+	}
+	return pr
+}
+
+// waitForReset sleeps until a rate limit resets
+func waitForReset(ctx context.Context, reset time.Time) error {
+	wait := time.Until(reset)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
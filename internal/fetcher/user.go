@@ -0,0 +1,43 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fishnix/ghpr-analyzer/internal/ghclient"
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+// UserFetcher fetches GitHub user profiles
+type UserFetcher struct {
+	client   *github.Client
+	ghClient *ghclient.Client
+	logger   *zap.Logger
+}
+
+// NewUserFetcher creates a new user fetcher
+func NewUserFetcher(client *github.Client, ghClient *ghclient.Client, logger *zap.Logger) *UserFetcher {
+	return &UserFetcher{
+		client:   client,
+		ghClient: ghClient,
+		logger:   logger,
+	}
+}
+
+// FetchUser fetches the full profile of a user (including company)
+func (u *UserFetcher) FetchUser(ctx context.Context, login string) (*github.User, error) {
+	user, resp, err := u.client.Users.Get(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", login, err)
+	}
+
+	// Check rate limit and sleep if threshold is reached
+	if u.ghClient != nil && resp != nil {
+		if err := u.ghClient.CheckAndSleepIfNeeded(ctx, resp); err != nil {
+			return nil, fmt.Errorf("rate limit check failed: %w", err)
+		}
+	}
+
+	return user, nil
+}
@@ -1,31 +1,212 @@
 package ghclient
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
-	"go.uber.org/zap"
 )
 
+// APICategory is a group of API endpoints sharing a GitHub rate limit
+type APICategory string
+
+const (
+	CategoryCore   APICategory = "core"   // Everything but search
+	CategorySearch APICategory = "search" // The search API, with its own, much lower limit
+)
+
+// secondaryRateLimitWait is the wait after a secondary rate limit (abuse
+// detection) response that does not say how long to wait
+const secondaryRateLimitWait = time.Minute
+
+// maxRetryAfterWait caps the wait a rate limited response asks for, so a
+// Retry-After far in the future cannot stall a run; a request still limited
+// after it is simply limited again
+const maxRetryAfterWait = 15 * time.Minute
+
+// secondaryRateLimitBodyMax is how much of a 403 or 429 body is read to tell a
+// secondary rate limit from other refusals
+const secondaryRateLimitBodyMax = 64 << 10
+
+// apiCategory returns the rate limit category of an API path
+func apiCategory(path string) APICategory {
+	if strings.HasPrefix(strings.TrimPrefix(path, "/api/v3"), "/search/") {
+		return CategorySearch
+	}
+	return CategoryCore
+}
+
 // Client wraps the GitHub API client with rate limiting and retries
 type Client struct {
 	client        *github.Client
-	limiter       *rate.Limiter
+	limiter       *rate.Limiter // Paces core API work, see WaitForRateLimit
+	searchLimiter *rate.Limiter // Paces every search API request
 	logger        *zap.Logger
 	maxRetries    int
 	baseDelay     time.Duration
 	threshold     int           // Rate limit threshold to trigger sleep
 	sleepDuration time.Duration // Duration to sleep when threshold is reached
+
+	// Rate limit sleep accounting for the run
+	sleepMu     sync.Mutex
+	sleepEvents int
+	sleepTotal  time.Duration
+
+	apiCalls atomic.Int64 // HTTP requests sent to the API
+
+	// Rate limit headers of every response, in arrival order
+	rateMu       sync.Mutex
+	rateTimeline []RateSample
+}
+
+// RateSample is the rate limit reported by one API response
+type RateSample struct {
+	Time      time.Time
+	Resource  string // Rate limit category, e.g. "core" or "search"
+	Remaining int
+	Limit     int
+}
+
+// rateRecordingTransport records the rate limit headers of the responses
+// received through it
+type rateRecordingTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+// RoundTrip sends the request with the base transport and records the rate
+// limit of the response, if it reports one
+func (t *rateRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.client.recordRate(resp.Header)
+	}
+	return resp, err
+}
+
+// retryingTransport retries requests refused by a secondary rate limit (or
+// with a Retry-After on a 403 or 429) once the wait they ask for is over, so
+// every API call survives abuse detection rather than failing the run
+type retryingTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+// RoundTrip sends the request with the base transport, waiting out and
+// retrying rate limited responses up to the client's max retries. The last
+// attempt's response is returned as is, without waiting.
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests whose body cannot be replayed are sent once
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil || !replayable || attempt >= t.client.maxRetries {
+			return resp, err
+		}
+		wait, ok := rateLimitedWait(resp)
+		if !ok {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		t.client.logger.Warn("Secondary rate limit hit, waiting before retrying",
+			zap.String("path", req.URL.Path),
+			zap.Int("attempt", attempt),
+			zap.Int("status_code", resp.StatusCode),
+			zap.Duration("wait_time", wait),
+		)
+		if err := t.client.waitRateLimited(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimitedWait returns how long to wait before retrying a response refused
+// by a rate limit: its Retry-After, or secondaryRateLimitWait for a secondary
+// rate limit without one. It reports false for any other response. The body of
+// a 403 or 429 without Retry-After is read and replaced, so it can still be
+// decoded.
+func rateLimitedWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if wait, ok := retryAfterHeader(resp); ok {
+		return wait, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, secondaryRateLimitBodyMax))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return 0, false
+	}
+	if strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
 }
 
-// NewClient creates a new GitHub client with rate limiting
-func NewClient(token string, qps int, burst int, maxRetries int, baseDelayMs int, threshold int, sleepMinutes int, logger *zap.Logger) (*Client, error) {
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	base  http.RoundTripper
+	calls *atomic.Int64
+}
+
+// RoundTrip counts the request and sends it with the base transport
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return t.base.RoundTrip(req)
+}
+
+// limitingTransport paces the requests of the API categories it has a limiter for
+type limitingTransport struct {
+	base     http.RoundTripper
+	limiters map[APICategory]*rate.Limiter
+}
+
+// RoundTrip waits for the limiter of the request's category, if any, and sends
+// the request with the base transport
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if limiter, ok := t.limiters[apiCategory(req.URL.Path)]; ok {
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewClient creates a new GitHub client with rate limiting. Core API work is
+// paced at qps by callers through WaitForRateLimit; every search request is
+// paced at searchQPS, as search has a separate, lower limit.
+func NewClient(token string, qps int, burst int, searchQPS float64, searchBurst int, maxRetries int, baseDelayMs int, threshold int, sleepMinutes int, logger *zap.Logger) (*Client, error) {
 	if token == "" {
 		return nil, fmt.Errorf("GitHub token is required")
 	}
@@ -40,17 +221,74 @@ func NewClient(token string, qps int, burst int, maxRetries int, baseDelayMs int
 	// qps is requests per second, so we need to convert to rate.Limit
 	limiter := rate.NewLimiter(rate.Limit(qps), burst)
 
-	client := github.NewClient(tc)
-
-	return &Client{
-		client:        client,
+	c := &Client{
 		limiter:       limiter,
+		searchLimiter: rate.NewLimiter(rate.Limit(searchQPS), searchBurst),
 		logger:        logger,
 		maxRetries:    maxRetries,
 		baseDelay:     time.Duration(baseDelayMs) * time.Millisecond,
 		threshold:     threshold,
 		sleepDuration: time.Duration(sleepMinutes) * time.Minute,
-	}, nil
+	}
+	tc.Transport = &retryingTransport{
+		base: &limitingTransport{
+			base: &countingTransport{
+				base:  &rateRecordingTransport{base: tc.Transport, client: c},
+				calls: &c.apiCalls,
+			},
+			limiters: map[APICategory]*rate.Limiter{CategorySearch: c.searchLimiter},
+		},
+		client: c,
+	}
+	c.client = github.NewClient(tc)
+
+	return c, nil
+}
+
+// APICalls returns the number of HTTP requests sent to the API so far
+func (c *Client) APICalls() int64 {
+	return c.apiCalls.Load()
+}
+
+// ResetStats clears the API calls, rate limit sleeps and rate limits recorded
+// so far, so a client shared by successive runs reports each run's own
+func (c *Client) ResetStats() {
+	c.apiCalls.Store(0)
+
+	c.sleepMu.Lock()
+	c.sleepEvents, c.sleepTotal = 0, 0
+	c.sleepMu.Unlock()
+
+	c.rateMu.Lock()
+	c.rateTimeline = nil
+	c.rateMu.Unlock()
+}
+
+// RateTimeline returns the rate limits reported by the API responses so far,
+// in arrival order
+func (c *Client) RateTimeline() []RateSample {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return append([]RateSample(nil), c.rateTimeline...)
+}
+
+// recordRate adds the rate limit of a response's headers to the timeline;
+// responses without rate limit headers are skipped
+func (c *Client) recordRate(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	c.rateTimeline = append(c.rateTimeline, RateSample{
+		Time:      time.Now(),
+		Resource:  header.Get("X-RateLimit-Resource"),
+		Remaining: remaining,
+		Limit:     limit,
+	})
 }
 
 // GetClient returns the underlying GitHub client
@@ -58,9 +296,17 @@ func (c *Client) GetClient() *github.Client {
 	return c.client
 }
 
-// WaitForRateLimit waits for the rate limiter
+// WaitForRateLimit waits for the core API rate limiter
 func (c *Client) WaitForRateLimit(ctx context.Context) error {
-	return c.limiter.Wait(ctx)
+	return c.Limiter(CategoryCore).Wait(ctx)
+}
+
+// Limiter returns the rate limiter of an API category
+func (c *Client) Limiter(category APICategory) *rate.Limiter {
+	if category == CategorySearch {
+		return c.searchLimiter
+	}
+	return c.limiter
 }
 
 // CheckRateLimit checks the current rate limit status
@@ -86,10 +332,13 @@ func (c *Client) CheckAndSleepIfNeeded(ctx context.Context, resp *github.Respons
 			zap.Duration("sleep_duration", c.sleepDuration),
 		)
 
+		start := time.Now()
 		select {
 		case <-ctx.Done():
+			c.recordSleep(time.Since(start))
 			return ctx.Err()
 		case <-time.After(c.sleepDuration):
+			c.recordSleep(time.Since(start))
 			c.logger.Info("Sleep complete, resuming operations")
 		}
 	}
@@ -97,6 +346,21 @@ func (c *Client) CheckAndSleepIfNeeded(ctx context.Context, resp *github.Respons
 	return nil
 }
 
+// SleepStats returns the number of rate limit sleeps and the total time spent in them
+func (c *Client) SleepStats() (int, time.Duration) {
+	c.sleepMu.Lock()
+	defer c.sleepMu.Unlock()
+	return c.sleepEvents, c.sleepTotal
+}
+
+// recordSleep adds a rate limit sleep to the run's accounting
+func (c *Client) recordSleep(d time.Duration) {
+	c.sleepMu.Lock()
+	defer c.sleepMu.Unlock()
+	c.sleepEvents++
+	c.sleepTotal += d
+}
+
 // RetryWithBackoff executes a function with exponential backoff retry
 func (c *Client) RetryWithBackoff(ctx context.Context, fn func() (*github.Response, error)) (*github.Response, error) {
 	var lastErr error
@@ -120,11 +384,14 @@ func (c *Client) RetryWithBackoff(ctx context.Context, fn func() (*github.Respon
 							zap.Time("reset_time", resetTime),
 							zap.Duration("wait_time", waitTime),
 						)
+						start := time.Now()
 						select {
 						case <-ctx.Done():
+							c.recordSleep(time.Since(start))
 							return nil, ctx.Err()
 						case <-time.After(waitTime):
 						}
+						c.recordSleep(time.Since(start))
 					}
 				}
 			}
@@ -134,6 +401,24 @@ func (c *Client) RetryWithBackoff(ctx context.Context, fn func() (*github.Respon
 		lastErr = err
 		lastResp = resp
 
+		// Secondary rate limits say how long to back off; waiting that long
+		// lets the run continue instead of failing on the 403. There is no
+		// point in waiting before giving up.
+		if wait, ok := retryAfter(resp, err); ok {
+			if attempt == c.maxRetries-1 {
+				break
+			}
+			c.logger.Warn("Secondary rate limit hit, waiting before retrying",
+				zap.Int("attempt", attempt+1),
+				zap.Duration("wait_time", wait),
+				zap.Error(err),
+			)
+			if err := c.waitRateLimited(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		// Check if it's a retryable error
 		if resp != nil {
 			statusCode := resp.StatusCode
@@ -173,10 +458,69 @@ func (c *Client) RetryWithBackoff(ctx context.Context, fn func() (*github.Respon
 	return lastResp, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// waitRateLimited sleeps for d, accounted as a rate limit sleep, unless ctx
+// is done first
+func (c *Client) waitRateLimited(ctx context.Context, d time.Duration) error {
+	start := time.Now()
+	defer func() { c.recordSleep(time.Since(start)) }()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// retryAfter returns how long to wait before retrying a failed request: the
+// wait of a secondary rate limit error, or the Retry-After header of a 403 or
+// 429 response, at most maxRetryAfterWait. It reports false when the failure
+// says nothing about waiting.
+func retryAfter(resp *github.Response, err error) (time.Duration, bool) {
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return min(max(*abuseErr.RetryAfter, 0), maxRetryAfterWait), true
+		}
+		if wait, ok := retryAfterHeader(abuseErr.Response); ok {
+			return wait, true
+		}
+		return secondaryRateLimitWait, true
+	}
+
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	return retryAfterHeader(resp.Response)
+}
+
+// retryAfterHeader parses the Retry-After header of a response, given either
+// in seconds or as an HTTP date, capped at maxRetryAfterWait
+func retryAfterHeader(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds > int(maxRetryAfterWait/time.Second) {
+			return maxRetryAfterWait, true
+		}
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return min(max(time.Until(at), 0), maxRetryAfterWait), true
+	}
+	return 0, false
+}
+
 func (c *Client) calculateBackoff(attempt int) time.Duration {
 	// Exponential backoff with jitter
 	delay := float64(c.baseDelay) * math.Pow(2, float64(attempt))
 	jitter := time.Duration(float64(delay) * 0.1) // 10% jitter
 	return time.Duration(delay) + jitter
 }
-
@@ -0,0 +1,284 @@
+package ghclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+	"go.uber.org/zap"
+)
+
+func TestAPICategory(t *testing.T) {
+	tests := []struct {
+		path string
+		want APICategory
+	}{
+		{"/search/issues", CategorySearch},
+		{"/api/v3/search/issues", CategorySearch}, // GitHub Enterprise Server
+		{"/repos/org/repo/pulls", CategoryCore},
+		{"/repos/org/search/pulls", CategoryCore},
+	}
+	for _, tt := range tests {
+		if got := apiCategory(tt.path); got != tt.want {
+			t.Errorf("apiCategory(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSearchAndCoreUseDistinctLimiters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// One search request, then none for a long time; core allows one request
+	c, err := NewClient("token", 1, 1, 0.001, 1, 1, 0, 0, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.Limiter(CategorySearch) == c.Limiter(CategoryCore) {
+		t.Fatal("search and core share a limiter")
+	}
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	c.client.BaseURL = baseURL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := c.client.Search.Issues(ctx, "is:pr", nil); err != nil {
+		t.Fatalf("first search error = %v", err)
+	}
+	// The search limiter is drained, so the next search has to wait past the deadline
+	if _, _, err := c.client.Search.Issues(ctx, "is:pr", nil); err == nil {
+		t.Error("second search was not rate limited")
+	}
+
+	// Core work draws from its own limiter
+	if err := c.WaitForRateLimit(ctx); err != nil {
+		t.Errorf("core limiter wait error = %v, want it unaffected by search", err)
+	}
+	if _, _, err := c.client.Repositories.Get(ctx, "org", "repo"); err != nil {
+		t.Errorf("core request error = %v, want it not paced by the search limiter", err)
+	}
+}
+
+func TestRateTimelineRecordsResponses(t *testing.T) {
+	remaining := 5000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/untracked" {
+			remaining--
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Resource", "core")
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient("token", 100, 100, 100, 100, 1, 0, 0, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for _, path := range []string{"/rate_limit", "/untracked", "/rate_limit"} {
+		resp, err := c.client.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	timeline := c.RateTimeline()
+	if len(timeline) != 2 {
+		t.Fatalf("expected 2 samples (responses without rate limit headers skipped), got %d", len(timeline))
+	}
+	for i, want := range []int{4999, 4998} {
+		if got := timeline[i]; got.Remaining != want || got.Limit != 5000 || got.Resource != "core" {
+			t.Errorf("sample %d = %+v, want remaining %d of 5000 core", i, got, want)
+		}
+	}
+	if timeline[1].Time.Before(timeline[0].Time) {
+		t.Error("expected samples in arrival order")
+	}
+
+	c.recordSleep(time.Second)
+	c.ResetStats()
+	if events, total := c.SleepStats(); events != 0 || total != 0 {
+		t.Errorf("SleepStats() after ResetStats = %d, %v, want none", events, total)
+	}
+	if calls := c.APICalls(); calls != 0 {
+		t.Errorf("APICalls() after ResetStats = %d, want 0", calls)
+	}
+	if timeline := c.RateTimeline(); len(timeline) != 0 {
+		t.Errorf("RateTimeline() after ResetStats = %+v, want empty", timeline)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	response := func(status int, retryAfter string) *github.Response {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &github.Response{Response: &http.Response{StatusCode: status, Header: header}}
+	}
+	wait := 45 * time.Second
+
+	tests := []struct {
+		name     string
+		resp     *github.Response
+		err      error
+		want     time.Duration
+		wantWait bool
+	}{
+		{"403 with Retry-After", response(http.StatusForbidden, "30"), errors.New("forbidden"), 30 * time.Second, true},
+		{"429 with Retry-After", response(http.StatusTooManyRequests, "5"), errors.New("too many requests"), 5 * time.Second, true},
+		{"403 without Retry-After", response(http.StatusForbidden, ""), errors.New("forbidden"), 0, false},
+		{"404 with Retry-After", response(http.StatusNotFound, "30"), errors.New("not found"), 0, false},
+		{"unparseable Retry-After", response(http.StatusForbidden, "soon"), errors.New("forbidden"), 0, false},
+		{"no response", nil, errors.New("connection reset"), 0, false},
+		{"secondary rate limit", response(http.StatusForbidden, "30"), &github.AbuseRateLimitError{RetryAfter: &wait}, wait, true},
+		{"secondary rate limit without wait", response(http.StatusForbidden, ""), &github.AbuseRateLimitError{}, secondaryRateLimitWait, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.resp, tt.err)
+			if got != tt.want || ok != tt.wantWait {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantWait)
+			}
+		})
+	}
+
+	// An HTTP date is a point in time to wait until
+	at := time.Now().Add(10 * time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(response(http.StatusForbidden, at), errors.New("forbidden")); !ok || got <= 8*time.Minute || got > 10*time.Minute {
+		t.Errorf("retryAfter(HTTP date 10 minutes ahead) = %v, %v, want about 10 minutes", got, ok)
+	}
+
+	// Waits far in the future are capped
+	far := time.Now().Add(48 * time.Hour).UTC().Format(http.TimeFormat)
+	for _, value := range []string{far, "86400", "999999999999999"} {
+		if got, ok := retryAfter(response(http.StatusForbidden, value), errors.New("forbidden")); !ok || got != maxRetryAfterWait {
+			t.Errorf("retryAfter(%q) = %v, %v, want the cap %v", value, got, ok, maxRetryAfterWait)
+		}
+	}
+	long := 24 * time.Hour
+	if got, _ := retryAfter(nil, &github.AbuseRateLimitError{RetryAfter: &long}); got != maxRetryAfterWait {
+		t.Errorf("retryAfter(secondary rate limit of a day) = %v, want the cap %v", got, maxRetryAfterWait)
+	}
+}
+
+func TestRetryWithBackoffRetriesSecondaryRateLimit(t *testing.T) {
+	c, err := NewClient("token", 100, 100, 100, 100, 3, 0, 0, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	calls := 0
+	resp, err := c.RetryWithBackoff(context.Background(), func() (*github.Response, error) {
+		calls++
+		if calls == 1 {
+			header := http.Header{"Retry-After": []string{"0"}}
+			return &github.Response{Response: &http.Response{StatusCode: http.StatusForbidden, Header: header}}, errors.New("secondary rate limit")
+		}
+		return &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("RetryWithBackoff() = %v, %v, want a retried success", resp, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+	if events, _ := c.SleepStats(); events != 1 {
+		t.Errorf("expected the wait to be recorded as 1 rate limit sleep, got %d", events)
+	}
+
+	// The last attempt gives up without waiting
+	calls = 0
+	start := time.Now()
+	_, err = c.RetryWithBackoff(context.Background(), func() (*github.Response, error) {
+		calls++
+		header := http.Header{"Retry-After": []string{"0"}}
+		if calls == 3 {
+			header.Set("Retry-After", "600")
+		}
+		return &github.Response{Response: &http.Response{StatusCode: http.StatusForbidden, Header: header}}, errors.New("secondary rate limit")
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("RetryWithBackoff() = %v after %d attempts, want an error after 3", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected no wait after the last attempt, took %v", elapsed)
+	}
+}
+
+func TestTransportRetriesSecondaryRateLimit(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case r.URL.Path == "/repos/org/limited" && calls == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+		case r.URL.Path == "/repos/org/always-limited":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+		case r.URL.Path == "/repos/org/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		default:
+			w.Write([]byte(`{"name": "limited"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient("token", 100, 100, 100, 100, 3, 0, 0, 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	c.client.BaseURL = baseURL
+	ctx := context.Background()
+
+	repo, _, err := c.client.Repositories.Get(ctx, "org", "limited")
+	if err != nil || repo.GetName() != "limited" {
+		t.Fatalf("Repositories.Get() = %v, %v, want the repository after a retry", repo, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+
+	// Gives up after the max retries, with the last response's error intact
+	calls = 0
+	if _, _, err := c.client.Repositories.Get(ctx, "org", "always-limited"); err == nil {
+		t.Error("expected an error once retries are exhausted")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+
+	// Other refusals are not retried, and their body is still decoded
+	calls = 0
+	_, _, err = c.client.Repositories.Get(ctx, "org", "forbidden")
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Message != "Resource not accessible by integration" {
+		t.Errorf("Repositories.Get() error = %v, want the decoded 403", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
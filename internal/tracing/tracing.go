@@ -0,0 +1,256 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// serviceName is reported as the service.name resource attribute
+const serviceName = "ghpr-analyzer"
+
+// OTLP span kind and status codes
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// Tracer records spans for the run and exports them to an OTLP/HTTP collector
+// (JSON encoding) on Shutdown. A nil Tracer is valid and records nothing, so
+// callers never need to check whether tracing is configured.
+type Tracer struct {
+	endpoint string
+	client   *http.Client
+	logger   *zap.Logger
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewTracer creates a tracer exporting to the OTLP/HTTP endpoint (e.g.
+// http://localhost:4318), or returns nil when endpoint is empty
+func NewTracer(endpoint string, logger *zap.Logger) *Tracer {
+	if endpoint == "" {
+		return nil
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+
+	return &Tracer{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		logger:   logger,
+	}
+}
+
+// Span is one timed operation of the run. Its methods are no-ops on a nil Span.
+type Span struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  map[string]interface{}
+	errMsg string
+}
+
+type spanKey struct{}
+
+// Start starts a span as a child of the span in ctx (if any) and returns a
+// context carrying the new span. attrs are alternating keys and values.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		spanID: newID(8),
+		name:   name,
+		start:  time.Now(),
+		attrs:  make(map[string]interface{}),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		span.traceID = newID(16)
+	}
+	span.SetAttributes(attrs...)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes sets attributes given as alternating keys and values
+func (s *Span) SetAttributes(attrs ...interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.attrs[fmt.Sprint(attrs[i])] = attrs[i+1]
+	}
+}
+
+// RecordError marks the span as failed; a nil err is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End ends the span
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+}
+
+// Shutdown exports the recorded spans. Spans that were never ended are
+// exported as ending now.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(exportRequest(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to export spans: collector returned %s", resp.Status)
+	}
+
+	t.logger.Info("Exported trace spans", zap.Int("spans", len(spans)), zap.String("endpoint", t.endpoint))
+	return nil
+}
+
+// exportRequest builds an OTLP ExportTraceServiceRequest in its JSON encoding
+func exportRequest(spans []*Span) map[string]interface{} {
+	now := time.Now()
+	otlpSpans := make([]map[string]interface{}, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		end := s.end
+		if end.IsZero() {
+			end = now
+		}
+
+		span := map[string]interface{}{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              spanKindInternal,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+			"attributes":        otlpAttributes(s.attrs),
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.errMsg != "" {
+			span["status"] = map[string]interface{}{"code": statusCodeError, "message": s.errMsg}
+		}
+		s.mu.Unlock()
+
+		otlpSpans = append(otlpSpans, span)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": serviceName},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlpAttributes converts attributes to OTLP key/value pairs
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	kvs := make([]map[string]interface{}, 0, len(attrs))
+	for key, value := range attrs {
+		var v map[string]interface{}
+		switch value := value.(type) {
+		case int:
+			v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+		case int64:
+			v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+		case bool:
+			v = map[string]interface{}{"boolValue": value}
+		case float64:
+			v = map[string]interface{}{"doubleValue": value}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+		}
+		kvs = append(kvs, map[string]interface{}{"key": key, "value": v})
+	}
+	return kvs
+}
+
+// newID returns a random hex-encoded ID of n bytes (16 for traces, 8 for spans)
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNilTracerIsNoOp(t *testing.T) {
+	tracer := NewTracer("", zap.NewNop())
+	if tracer != nil {
+		t.Fatalf("NewTracer(\"\") = %v, want nil", tracer)
+	}
+
+	ctx, span := tracer.Start(context.Background(), "repo")
+	span.SetAttributes("repo", "org/a")
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	if ctx != context.Background() {
+		t.Errorf("Start on nil tracer changed the context")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown on nil tracer = %v, want nil", err)
+	}
+}
+
+func TestShutdownExportsSpanTree(t *testing.T) {
+	var got struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+					Status       *struct {
+						Code    int    `json:"code"`
+						Message string `json:"message"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode export request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(server.URL, zap.NewNop())
+	ctx, root := tracer.Start(context.Background(), "analyze")
+	_, child := tracer.Start(ctx, "process_repo", "repo", "org/a", "prs", 3)
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+
+	if path != "/v1/traces" {
+		t.Errorf("export path = %q, want /v1/traces", path)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected export request shape: %+v", got)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	parent, span := spans[0], spans[1]
+	if span.TraceID != parent.TraceID || span.ParentSpanID != parent.SpanID {
+		t.Errorf("child span not linked to its parent: %+v, %+v", parent, span)
+	}
+	if parent.ParentSpanID != "" {
+		t.Errorf("root span has parent %q", parent.ParentSpanID)
+	}
+	if span.Status == nil || span.Status.Code != statusCodeError || span.Status.Message != "boom" {
+		t.Errorf("child status = %+v, want error boom", span.Status)
+	}
+}